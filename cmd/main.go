@@ -2,18 +2,26 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
+	"belimang/internal/app/courier"
 	"belimang/internal/app/image"
 	"belimang/internal/app/items"
 	"belimang/internal/app/merchant"
+	"belimang/internal/app/order"
 	"belimang/internal/app/purchase"
 	"belimang/internal/app/user"
 	"belimang/internal/config"
 	"belimang/internal/infrastructure/cache"
 	"belimang/internal/infrastructure/database"
+	"belimang/internal/middleware"
 	"belimang/internal/pkg/jwt"
 	logger "belimang/internal/pkg/logging"
 	"belimang/internal/pkg/utils"
@@ -23,7 +31,10 @@ import (
 )
 
 func main() {
-	ctx := context.Background()
+	// dibatalkan saat SIGINT/SIGTERM; worker background berhenti mengikuti ctx ini
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var workers sync.WaitGroup
 
 	// Load configuration
 	cfg, err := config.LoadConfig(".env")
@@ -52,6 +63,7 @@ func main() {
 
 	// Initialize Gin router
 	router := gin.Default()
+	router.Use(middleware.Idempotency(redisCache, jwtService))
 
 	// Setup routes with shared dependencies
 	router.GET("/healthz", func(c *gin.Context) {
@@ -64,19 +76,38 @@ func main() {
 	user.RegisterRoutes(router, userHandler)
 
 	// Item
-	itemService := items.NewItemService(db.Queries, redisCache)
+	itemService := items.NewItemService(db.Queries, db, redisCache)
 	itemHandler := items.NewItemHandler(itemService)
 	items.ItemRoutes(router, itemHandler, jwtService)
 
+	// Order events are published by purchase, order and courier services
+	orderEvents := order.NewEventPublisher(redisCache)
+
 	// Purchase
-	purhcaseService := purchase.NewPurchaseService(db.Queries, db)
+	purhcaseService := purchase.NewPurchaseService(db.Queries, db, redisCache, cfg.Delivery, orderEvents)
 	purchaseHandler := purchase.NewPurchaseHandler(purhcaseService, validator)
 	purchase.PurchaseRoutes(router, purchaseHandler, jwtService)
 
+	// Order lifecycle
+	orderService := order.NewOrderService(db, redisCache, orderEvents)
+	orderHandler := order.NewOrderHandler(orderService, validator)
+	order.OrderRoutes(router, orderHandler, jwtService)
+	releaseScheduler := order.NewReleaseScheduler(db.Queries, orderEvents, cfg.Delivery.Schedule)
+	workers.Go(func() { releaseScheduler.Run(ctx) })
+
+	// Courier location and order dispatch
+	courierService := courier.NewCourierService(db.Queries, redisCache, orderEvents, cfg.Dispatch)
+	courierHandler := courier.NewCourierHandler(courierService, validator)
+	courier.CourierRoutes(router, courierHandler, jwtService)
+	dispatcher := courier.NewDispatcher(db.Queries, redisCache, courierService, cfg.Dispatch)
+	workers.Go(func() { dispatcher.Run(ctx) })
+
 	// Initialize merchant components with shared dependencies
-	merchantService := merchant.NewMerchantService(redisCache, db.Queries)
+	merchantService := merchant.NewMerchantService(redisCache, db.Queries, db)
 	merchantHandler := merchant.NewMerchantHandler(merchantService, validator)
 	merchant.MerchantRoutes(router, merchantHandler, jwtService)
+	merchantImportWorker := merchant.NewImportWorker(db, cfg.MerchantImport)
+	workers.Go(func() { merchantImportWorker.Run(ctx) })
 
 	// Image
 	imageHandler := image.NewImageHandler()
@@ -88,8 +119,38 @@ func main() {
 		Handler: router,
 	}
 
-	log.Printf("Server starting on port %d", cfg.Server.Port)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("Failed to start server: %v", err)
+	go func() {
+		log.Printf("Server starting on port %d", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Printf("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// stream SSE tidak pernah idle, jadi diakhiri dulu sebelum Shutdown
+	// menunggu request yang masih berjalan
+	if err := orderEvents.Close(shutdownCtx); err != nil {
+		log.Printf("Order event streams did not close in time: %v", err)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown failed: %v", err)
+	}
+
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+		log.Printf("Server stopped")
+	case <-shutdownCtx.Done():
+		log.Printf("Background workers did not stop in time")
 	}
 }
@@ -0,0 +1,184 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"belimang/internal/config"
+	"belimang/internal/infrastructure/cache"
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// order yang diproses per putaran dispatcher
+	dispatchBatchSize = 50
+	// kandidat kurir yang dikumpulkan sebelum memilih yang terdekat
+	dispatchCandidates = 20
+)
+
+// Dispatcher offers orders without a courier to the nearest available
+// courier around the route's starting merchant, one courier at a time.
+//
+// An offer lives in Redis for OfferTimeout. When it expires or the courier
+// rejects it, the next round offers the order to the nearest courier that
+// has not been asked yet; once every courier in range has been asked the
+// list is reset so they are asked again. Offers are claimed with SETNX, so
+// several app instances can run the dispatcher side by side.
+type Dispatcher struct {
+	queries  *database.Queries
+	cache    *cache.RedisCache
+	couriers *CourierService
+	cfg      config.DispatchConfig
+}
+
+func NewDispatcher(q *database.Queries, c *cache.RedisCache, couriers *CourierService, cfg config.DispatchConfig) *Dispatcher {
+	return &Dispatcher{
+		queries:  q,
+		cache:    c,
+		couriers: couriers,
+		cfg:      cfg,
+	}
+}
+
+// Run dispatches pending orders every Interval until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	logger.InfoCtx(ctx, "Courier dispatcher started", "interval", d.cfg.Interval, "offer_timeout", d.cfg.OfferTimeout)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.DispatchPending(ctx); err != nil {
+				logger.ErrorCtx(ctx, "Courier dispatch failed", "error", err)
+			}
+		}
+	}
+}
+
+// DispatchPending makes one pass over the orders that still need a courier
+func (d *Dispatcher) DispatchPending(ctx context.Context) error {
+	orders, err := d.queries.ListUndispatchedOrders(ctx, dispatchBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list undispatched orders: %w", err)
+	}
+
+	for _, order := range orders {
+		if err := d.offerOrder(ctx, order); err != nil {
+			logger.ErrorCtx(ctx, "Failed to offer order", "order_id", order.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) offerOrder(ctx context.Context, order database.ListUndispatchedOrdersRow) error {
+	offerKey := fmt.Sprintf(cache.DispatchOfferKey, order.ID)
+	pending, err := d.cache.Exists(ctx, offerKey)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return nil // masih menunggu jawaban kurir
+	}
+
+	offeredKey := fmt.Sprintf(cache.DispatchOfferedKey, order.ID)
+	asked, err := d.cache.SetMembers(ctx, offeredKey)
+	if err != nil {
+		return err
+	}
+	alreadyAsked := make(map[string]bool, len(asked))
+	for _, id := range asked {
+		alreadyAsked[id] = true
+	}
+
+	candidates, err := d.couriers.nearestCouriers(ctx, order.Lat, order.Lng, d.cfg.MaxRadiusMeters, dispatchCandidates, d.availableCouriers(alreadyAsked))
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		if len(asked) > 0 {
+			// semua kurir dalam jangkauan sudah ditanya; mulai lagi dari yang terdekat
+			logger.InfoCtx(ctx, "All nearby couriers asked, restarting offers", "order_id", order.ID)
+			return d.cache.Delete(ctx, offeredKey)
+		}
+		logger.DebugCtx(ctx, "No courier near order", "order_id", order.ID)
+		return nil
+	}
+
+	for _, candidate := range candidates {
+		offered, err := d.offer(ctx, order, candidate)
+		if err != nil {
+			return err
+		}
+		if offered {
+			return nil
+		}
+	}
+	return nil
+}
+
+// availableCouriers keeps the couriers without an active order that have not
+// been asked about this order yet
+func (d *Dispatcher) availableCouriers(alreadyAsked map[string]bool) courierFilter {
+	return func(ctx context.Context, couriers []nearbyCourier) ([]nearbyCourier, error) {
+		ids := make([]uuid.UUID, len(couriers))
+		for i, c := range couriers {
+			ids[i] = c.ID
+		}
+		busyIDs, err := d.queries.ListBusyCouriers(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list busy couriers: %w", err)
+		}
+		busy := make(map[uuid.UUID]bool, len(busyIDs))
+		for _, id := range busyIDs {
+			busy[id] = true
+		}
+
+		var available []nearbyCourier
+		for _, c := range couriers {
+			if !busy[c.ID] && !alreadyAsked[c.ID.String()] {
+				available = append(available, c)
+			}
+		}
+		return available, nil
+	}
+}
+
+// offer claims the order and the courier for OfferTimeout. It reports false
+// when another dispatcher instance offered the order first or the courier is
+// already considering another order.
+func (d *Dispatcher) offer(ctx context.Context, order database.ListUndispatchedOrdersRow, candidate nearbyCourier) (bool, error) {
+	offer := Offer{
+		OrderID:          order.ID.String(),
+		CourierID:        candidate.ID.String(),
+		MerchantID:       order.MerchantID.String(),
+		Pickup:           Location{Lat: order.Lat, Long: order.Lng},
+		DistanceInMeters: candidate.DistanceMeters,
+		ExpiresAt:        time.Now().Add(d.cfg.OfferTimeout).Format(time.RFC3339),
+	}
+
+	orderKey := fmt.Sprintf(cache.DispatchOfferKey, order.ID)
+	claimed, err := d.cache.SetNX(ctx, orderKey, offer, d.cfg.OfferTimeout)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	claimed, err = d.cache.SetNX(ctx, fmt.Sprintf(cache.CourierOfferKey, candidate.ID), offer, d.cfg.OfferTimeout)
+	if err != nil || !claimed {
+		_ = d.cache.Delete(ctx, orderKey)
+		return false, err
+	}
+
+	if err := d.cache.SetAdd(ctx, fmt.Sprintf(cache.DispatchOfferedKey, order.ID), cache.DispatchOfferedTTL, candidate.ID.String()); err != nil {
+		return true, err
+	}
+
+	logger.InfoCtx(ctx, "Order offered to courier", "order_id", order.ID, "courier_id", candidate.ID, "distance_m", candidate.DistanceMeters)
+	return true, nil
+}
@@ -0,0 +1,127 @@
+package courier
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type CourierHandler struct {
+	courierService *CourierService
+	validate       *validator.Validate
+}
+
+func NewCourierHandler(courierService *CourierService, v *validator.Validate) *CourierHandler {
+	return &CourierHandler{courierService: courierService, validate: v}
+}
+
+func (h *CourierHandler) UpdateLocation(c *gin.Context) {
+	courierID, err := getCourierID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req LocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "latitude must be [-90,90], longitude [-180,180]"})
+		return
+	}
+
+	resp, err := h.courierService.UpdateLocation(c, courierID, req.Lat, req.Long)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *CourierHandler) GetOffer(c *gin.Context) {
+	courierID, err := getCourierID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	offer, err := h.courierService.CurrentOffer(c, courierID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+func (h *CourierHandler) AcceptOffer(c *gin.Context) {
+	courierID, err := getCourierID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrOfferNotFound.Error()})
+		return
+	}
+
+	resp, err := h.courierService.AcceptOffer(c, courierID, orderID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *CourierHandler) RejectOffer(c *gin.Context) {
+	courierID, err := getCourierID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrOfferNotFound.Error()})
+		return
+	}
+
+	if err := h.courierService.RejectOffer(c, courierID, orderID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CourierHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNoActiveOffer), errors.Is(err, ErrOfferNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrOfferTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func getCourierID(c *gin.Context) (uuid.UUID, error) {
+	rawUserID, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, errors.New("user not authenticated")
+	}
+	courierID, ok := rawUserID.(string)
+	if !ok {
+		return uuid.Nil, errors.New("invalid user context")
+	}
+	return uuid.Parse(courierID)
+}
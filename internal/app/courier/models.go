@@ -0,0 +1,51 @@
+package courier
+
+import (
+	"errors"
+	"time"
+)
+
+type LocationRequest struct {
+	Lat  float64 `json:"lat" validate:"required,gte=-90,lte=90"`
+	Long float64 `json:"long" validate:"required,gte=-180,lte=180"`
+}
+
+type Location struct {
+	Lat  float64 `json:"lat"`
+	Long float64 `json:"long"`
+}
+
+type LocationResponse struct {
+	Location  Location `json:"location"`
+	H3Cell    string   `json:"h3Cell"`
+	ExpiresAt string   `json:"expiresAt"` // courier dianggap offline setelah ini tanpa update baru
+}
+
+// Offer is an order offered to a single courier until ExpiresAt
+type Offer struct {
+	OrderID          string   `json:"orderId"`
+	CourierID        string   `json:"courierId"`
+	MerchantID       string   `json:"merchantId"` // starting merchant of the route
+	Pickup           Location `json:"pickup"`
+	DistanceInMeters float64  `json:"distanceInMeters"` // courier to the starting merchant
+	ExpiresAt        string   `json:"expiresAt"`
+}
+
+type AcceptOfferResponse struct {
+	OrderID   string `json:"orderId"`
+	CourierID string `json:"courierId"`
+}
+
+// courierLocation is the last position a courier pushed, as stored in Redis
+type courierLocation struct {
+	Lat       float64   `json:"lat"`
+	Lng       float64   `json:"lng"`
+	Cell      string    `json:"cell"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var (
+	ErrNoActiveOffer = errors.New("no active offer")
+	ErrOfferNotFound = errors.New("offer not found or expired")
+	ErrOfferTaken    = errors.New("order is no longer available")
+)
@@ -0,0 +1,19 @@
+package courier
+
+import (
+	"belimang/internal/middleware"
+	"belimang/internal/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CourierRoutes(router *gin.Engine, handler *CourierHandler, jwtService *jwt.JWTService) {
+	couriers := router.Group("/couriers")
+	couriers.Use(middleware.RequireCourier(jwtService))
+	{
+		couriers.POST("/location", handler.UpdateLocation)
+		couriers.GET("/offer", handler.GetOffer)
+		couriers.POST("/offers/:orderId/accept", handler.AcceptOffer)
+		couriers.POST("/offers/:orderId/reject", handler.RejectOffer)
+	}
+}
@@ -0,0 +1,254 @@
+package courier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"belimang/internal/app/order"
+	"belimang/internal/config"
+	"belimang/internal/infrastructure/cache"
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+	"belimang/internal/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/uber/h3-go/v4"
+)
+
+type CourierService struct {
+	queries     *database.Queries
+	cache       *cache.RedisCache
+	events      *order.EventPublisher
+	locationTTL time.Duration
+}
+
+func NewCourierService(q *database.Queries, c *cache.RedisCache, events *order.EventPublisher, cfg config.DispatchConfig) *CourierService {
+	return &CourierService{
+		queries:     q,
+		cache:       c,
+		events:      events,
+		locationTTL: cfg.LocationTTL,
+	}
+}
+
+// UpdateLocation stores the courier's position and files the courier under
+// its H3 cell so the dispatcher can find couriers around a merchant.
+func (s *CourierService) UpdateLocation(ctx context.Context, courierID uuid.UUID, lat, lng float64) (LocationResponse, error) {
+	cell, err := h3.LatLngToCell(h3.LatLng{Lat: lat, Lng: lng}, utils.H3_SEARCH_RES)
+	if err != nil {
+		return LocationResponse{}, fmt.Errorf("failed to compute h3 cell: %w", err)
+	}
+
+	id := courierID.String()
+	locationKey := fmt.Sprintf(cache.CourierLocationKey, id)
+
+	var previous courierLocation
+	if err := s.cache.Get(ctx, locationKey, &previous); err == nil && previous.Cell != cell.String() {
+		if err := s.cache.SetRemove(ctx, fmt.Sprintf(cache.CourierCellKey, previous.Cell), id); err != nil {
+			return LocationResponse{}, err
+		}
+	}
+
+	now := time.Now()
+	if err := s.cache.Set(ctx, locationKey, courierLocation{
+		Lat:       lat,
+		Lng:       lng,
+		Cell:      cell.String(),
+		UpdatedAt: now,
+	}, s.locationTTL); err != nil {
+		return LocationResponse{}, err
+	}
+	if err := s.cache.SetAdd(ctx, fmt.Sprintf(cache.CourierCellKey, cell.String()), s.locationTTL, id); err != nil {
+		return LocationResponse{}, err
+	}
+
+	return LocationResponse{
+		Location:  Location{Lat: lat, Long: lng},
+		H3Cell:    cell.String(),
+		ExpiresAt: now.Add(s.locationTTL).Format(time.RFC3339),
+	}, nil
+}
+
+// CurrentOffer returns the offer waiting for the courier, if any
+func (s *CourierService) CurrentOffer(ctx context.Context, courierID uuid.UUID) (Offer, error) {
+	var offer Offer
+	err := s.cache.Get(ctx, fmt.Sprintf(cache.CourierOfferKey, courierID), &offer)
+	if errors.Is(err, redis.Nil) {
+		return Offer{}, ErrNoActiveOffer
+	}
+	if err != nil {
+		return Offer{}, err
+	}
+	return offer, nil
+}
+
+// AcceptOffer assigns the order to the courier. The assignment itself is a
+// conditional update, so an order can never end up with two couriers even
+// if an offer expires and is re-offered while the first courier accepts.
+func (s *CourierService) AcceptOffer(ctx context.Context, courierID, orderID uuid.UUID) (AcceptOfferResponse, error) {
+	if err := s.checkOffer(ctx, courierID, orderID); err != nil {
+		return AcceptOfferResponse{}, err
+	}
+
+	rows, err := s.queries.AssignOrderCourier(ctx, database.AssignOrderCourierParams{
+		CourierID: courierID,
+		ID:        orderID,
+	})
+	if err != nil {
+		return AcceptOfferResponse{}, fmt.Errorf("failed to assign courier: %w", err)
+	}
+
+	s.clearOffer(ctx, courierID, orderID)
+	if rows == 0 {
+		return AcceptOfferResponse{}, ErrOfferTaken
+	}
+
+	logger.InfoCtx(ctx, "Courier accepted order", "order_id", orderID, "courier_id", courierID)
+	s.events.Publish(ctx, orderID, order.OrderEvent{
+		Type:      order.EventCourierAssigned,
+		CourierID: courierID.String(),
+	})
+	return AcceptOfferResponse{OrderID: orderID.String(), CourierID: courierID.String()}, nil
+}
+
+// RejectOffer drops the offer right away so the dispatcher can move on to
+// the next courier without waiting for the timeout.
+func (s *CourierService) RejectOffer(ctx context.Context, courierID, orderID uuid.UUID) error {
+	if err := s.checkOffer(ctx, courierID, orderID); err != nil {
+		return err
+	}
+	s.clearOffer(ctx, courierID, orderID)
+
+	logger.InfoCtx(ctx, "Courier rejected order", "order_id", orderID, "courier_id", courierID)
+	return nil
+}
+
+// checkOffer makes sure orderID is the offer currently held by the courier
+func (s *CourierService) checkOffer(ctx context.Context, courierID, orderID uuid.UUID) error {
+	offer, err := s.CurrentOffer(ctx, courierID)
+	if errors.Is(err, ErrNoActiveOffer) {
+		return ErrOfferNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if offer.OrderID != orderID.String() {
+		return ErrOfferNotFound
+	}
+	return nil
+}
+
+func (s *CourierService) clearOffer(ctx context.Context, courierID, orderID uuid.UUID) {
+	// kunci yang gagal dihapus tetap kedaluwarsa sendiri setelah offer timeout
+	_ = s.cache.Delete(ctx, fmt.Sprintf(cache.CourierOfferKey, courierID))
+	_ = s.cache.Delete(ctx, fmt.Sprintf(cache.DispatchOfferKey, orderID))
+}
+
+// nearbyCourier is a courier found around a point, with its distance to it
+type nearbyCourier struct {
+	ID             uuid.UUID
+	DistanceMeters float64
+}
+
+// courierFilter keeps the couriers that can take an offer right now
+type courierFilter func(ctx context.Context, couriers []nearbyCourier) ([]nearbyCourier, error)
+
+// nearestCouriers walks H3 rings outwards from (lat, lng) until want couriers
+// passing available are found or radiusMeters is covered, and returns them
+// ordered by distance. Only couriers within radiusMeters are returned;
+// couriers whose location has expired are skipped. A nil filter keeps every
+// courier.
+func (s *CourierService) nearestCouriers(ctx context.Context, lat, lng, radiusMeters float64, want int, available courierFilter) ([]nearbyCourier, error) {
+	origin, err := h3.LatLngToCell(h3.LatLng{Lat: lat, Lng: lng}, utils.H3_SEARCH_RES)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute h3 cell: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var couriers []nearbyCourier
+	maxRings := utils.H3SearchRings(radiusMeters)
+	for k := 0; k <= maxRings && len(couriers) < want; k++ {
+		ring, err := origin.GridRing(k)
+		if err != nil {
+			// ring melewati pentagon; disk tetap bisa dipakai
+			if ring, err = origin.GridDisk(k); err != nil {
+				return nil, fmt.Errorf("failed to expand h3 ring: %w", err)
+			}
+		}
+
+		var ids []string
+		for _, cell := range ring {
+			members, err := s.cache.SetMembers(ctx, fmt.Sprintf(cache.CourierCellKey, cell.String()))
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range members {
+				if !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+
+		found, err := s.locateCouriers(ctx, lat, lng, radiusMeters, ids)
+		if err != nil {
+			return nil, err
+		}
+		// kurir yang tidak tersedia tidak dihitung, jadi pencarian terus melebar
+		if available != nil && len(found) > 0 {
+			if found, err = available(ctx, found); err != nil {
+				return nil, err
+			}
+		}
+		couriers = append(couriers, found...)
+	}
+
+	sort.Slice(couriers, func(i, j int) bool {
+		return couriers[i].DistanceMeters < couriers[j].DistanceMeters
+	})
+	return couriers, nil
+}
+
+// locateCouriers returns the couriers of ids whose last location is within
+// radiusMeters of (lat, lng)
+func (s *CourierService) locateCouriers(ctx context.Context, lat, lng, radiusMeters float64, ids []string) ([]nearbyCourier, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf(cache.CourierLocationKey, id)
+	}
+	locations, err := s.cache.GetMultiple(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var couriers []nearbyCourier
+	for i, id := range ids {
+		raw, ok := locations[keys[i]]
+		if !ok {
+			continue // lokasi sudah kedaluwarsa, kurir dianggap offline
+		}
+		var loc courierLocation
+		if err := json.Unmarshal([]byte(raw), &loc); err != nil {
+			continue
+		}
+		courierID, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		distance := utils.HaversineDistance(lat, lng, loc.Lat, loc.Lng)
+		if distance > radiusMeters {
+			continue
+		}
+		couriers = append(couriers, nearbyCourier{ID: courierID, DistanceMeters: distance})
+	}
+	return couriers, nil
+}
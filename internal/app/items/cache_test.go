@@ -0,0 +1,208 @@
+package items
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"belimang/internal/config"
+	"belimang/internal/infrastructure/cache"
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeItemsDB answers the queries ListItems and UpdateItem run from an
+// in-memory item list
+type fakeItemsDB struct {
+	adminID uuid.UUID
+	items   []database.ListItemsByMerchantRow
+}
+
+func queryName(sql string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(sql, "-- name: "), " ")
+	return name
+}
+
+func (db *fakeItemsDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &fakeTx{db: db}, nil
+}
+
+func (db *fakeItemsDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if queryName(sql) == "UpdateItem" {
+		for i, item := range db.items {
+			if item.ID != args[7].(uuid.UUID) {
+				continue
+			}
+			if price := args[2].(*int64); price != nil {
+				db.items[i].Price = *price
+			}
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		}
+		return pgconn.NewCommandTag("UPDATE 0"), nil
+	}
+	return pgconn.CommandTag{}, fmt.Errorf("unexpected exec %s", queryName(sql))
+}
+
+func (db *fakeItemsDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	switch queryName(sql) {
+	case "ListItemsByMerchant":
+		rows := &fakeRows{}
+		for _, item := range db.items {
+			rows.values = append(rows.values, []any{
+				item.ID, item.MerchantID, item.Name, item.ProductCategory, item.Price, item.ImageUrl,
+				item.CreatedAt, item.PreparationTimeMinutes, item.Stock, item.IsAvailable,
+			})
+		}
+		return rows, nil
+	case "ListItemOptions":
+		return &fakeRows{}, nil
+	}
+	return nil, fmt.Errorf("unexpected query %s", queryName(sql))
+}
+
+func (db *fakeItemsDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	switch queryName(sql) {
+	case "GetMerchantAccess":
+		return &fakeRows{values: [][]any{{db.adminID, true}}}
+	case "CountItemsByMerchant":
+		return &fakeRows{values: [][]any{{int64(len(db.items))}}}
+	}
+	return &fakeRows{err: fmt.Errorf("unexpected query %s", queryName(sql))}
+}
+
+func (db *fakeItemsDB) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, fmt.Errorf("unexpected copy into %v", tableName)
+}
+
+// fakeTx runs its queries straight against the fake database
+type fakeTx struct {
+	pgx.Tx
+	db *fakeItemsDB
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tx.db.Exec(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.db.Query(ctx, sql, args...)
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return tx.db.QueryRow(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error   { return nil }
+func (tx *fakeTx) Rollback(ctx context.Context) error { return nil }
+
+// fakeRows serves both as pgx.Rows and, for single row queries, pgx.Row
+type fakeRows struct {
+	pgx.Rows
+	values [][]any
+	next   int
+	err    error
+}
+
+func (r *fakeRows) Next() bool {
+	r.next++
+	return r.err == nil && r.next <= len(r.values)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.next == 0 && !r.Next() {
+		return pgx.ErrNoRows
+	}
+	for i, v := range r.values[r.next-1] {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func (r *fakeRows) Close()     {}
+func (r *fakeRows) Err() error { return r.err }
+
+func TestListItemsCacheNeverServesStalePrice(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	redisCache := cache.NewRedisCache(config.CacheConfig{RedisUrl: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { redisCache.Close() })
+
+	adminID, merchantID := uuid.New(), uuid.New()
+	db := &fakeItemsDB{
+		adminID: adminID,
+		items: []database.ListItemsByMerchantRow{{
+			ID:              uuid.New(),
+			MerchantID:      merchantID,
+			Name:            "Es Teh",
+			ProductCategory: "Beverage",
+			Price:           10000,
+			CreatedAt:       time.Now(),
+			IsAvailable:     true,
+		}},
+	}
+	store := database.NewDB(db)
+	s := NewItemService(store.Queries, store, redisCache)
+	ctx := context.Background()
+	req := ListItemsRequest{Limit: 5}
+
+	listPrice := func() int64 {
+		t.Helper()
+		items, _, err := s.ListItems(ctx, adminID, merchantID, req)
+		if err != nil {
+			t.Fatalf("ListItems() error = %v", err)
+		}
+		if len(items) != 1 {
+			t.Fatalf("ListItems() returned %d items, want 1", len(items))
+		}
+		return items[0].Price
+	}
+
+	if got := listPrice(); got != 10000 {
+		t.Fatalf("price = %d, want 10000", got)
+	}
+
+	// a reader that fetched the old price just before the update caches it
+	// only after the update has invalidated the cache
+	version, err := s.itemsCacheVersion(ctx, merchantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staleKey := s.generateListItemsCacheKey(merchantID, version, req)
+	stale := struct {
+		Items []ItemResponse
+		Total int64
+	}{Items: []ItemResponse{{ItemID: db.items[0].ID.String(), Price: 10000}}, Total: 1}
+
+	price := int64(12000)
+	updated, err := s.UpdateItem(ctx, adminID, merchantID, db.items[0].ID, UpdateItemRequest{Price: &price})
+	if err != nil {
+		t.Fatalf("UpdateItem() error = %v", err)
+	}
+	if updated.Price != 12000 {
+		t.Errorf("UpdateItem() price = %d, want 12000", updated.Price)
+	}
+	if err := redisCache.Set(ctx, staleKey, stale, cache.ProductListTTL); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := listPrice(); got != 12000 {
+		t.Errorf("price after update = %d, want 12000", got)
+	}
+	// the fresh list is cached too
+	db.items[0].Price = 15000
+	if got := listPrice(); got != 12000 {
+		t.Errorf("price read again = %d, want the cached 12000", got)
+	}
+}
@@ -1,7 +1,10 @@
 package items
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -21,6 +24,12 @@ func NewItemHandler(itemService *ItemService) *ItemHandler {
 }
 
 func (h *ItemHandler) CreateItem(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	merchantIDStr := c.Param("merchantId")
 	merchantID, err := uuid.Parse(merchantIDStr)
 	if err != nil {
@@ -65,6 +74,27 @@ func (h *ItemHandler) CreateItem(c *gin.Context) {
 		}
 	}
 
+	if prep, exists := rawData["preparationTimeInMinutes"]; exists && prep != nil {
+		if p, ok := prep.(float64); !ok || p != math.Trunc(p) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+			return
+		}
+	}
+
+	if stock, exists := rawData["stock"]; exists && stock != nil {
+		if s, ok := stock.(float64); !ok || s != math.Trunc(s) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+			return
+		}
+	}
+
+	if available, exists := rawData["isAvailable"]; exists && available != nil {
+		if _, ok := available.(bool); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+			return
+		}
+	}
+
 	var req CreateItemRequest
 	if name, exists := rawData["name"]; exists && name != nil {
 		if nameStr, ok := name.(string); ok {
@@ -91,6 +121,26 @@ func (h *ItemHandler) CreateItem(c *gin.Context) {
 			req.Price = p
 		}
 	}
+	if prep, exists := rawData["preparationTimeInMinutes"]; exists && prep != nil {
+		minutes := int(prep.(float64))
+		req.PreparationTimeInMinutes = &minutes
+	}
+	if stock, exists := rawData["stock"]; exists && stock != nil {
+		count := int(stock.(float64))
+		req.Stock = &count
+	}
+	if available, exists := rawData["isAvailable"]; exists && available != nil {
+		isAvailable := available.(bool)
+		req.IsAvailable = &isAvailable
+	}
+	if groups, exists := rawData["optionGroups"]; exists && groups != nil {
+		// grup opsi bersarang, cukup di-decode ulang ke struct-nya
+		encoded, _ := json.Marshal(groups)
+		if err := json.Unmarshal(encoded, &req.OptionGroups); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+			return
+		}
+	}
 
 	if req.ImageUrl != "" {
 		if !isValidImageURL(req.ImageUrl) {
@@ -103,32 +153,8 @@ func (h *ItemHandler) CreateItem(c *gin.Context) {
 
 	if err := validator.New().Struct(&req); err != nil {
 		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			var errorMessages []string
-			for _, e := range validationErrors {
-				field := e.Field()
-				tag := e.Tag()
-
-				switch tag {
-				case "required":
-					errorMessages = append(errorMessages, field+" is required")
-				case "min":
-					if field == "Price" {
-						errorMessages = append(errorMessages, field+" must be at least "+e.Param())
-					} else {
-						errorMessages = append(errorMessages, field+" must be at least "+e.Param()+" characters")
-					}
-				case "max":
-					errorMessages = append(errorMessages, field+" must not exceed "+e.Param()+" characters")
-				case "oneof":
-					errorMessages = append(errorMessages, field+" must be one of: Beverage, Food, Snack, Condiments, Additions")
-				case "url":
-					errorMessages = append(errorMessages, field+" must be a valid URL")
-				default:
-					errorMessages = append(errorMessages, "invalid "+field)
-				}
-			}
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": errorMessages,
+				"error": itemValidationMessages(validationErrors),
 			})
 			return
 		}
@@ -137,11 +163,15 @@ func (h *ItemHandler) CreateItem(c *gin.Context) {
 		return
 	}
 
-	itemID, err := h.itemService.CreateItem(c.Request.Context(), merchantID, req)
+	itemID, err := h.itemService.CreateItem(c.Request.Context(), adminID, merchantID, req)
 	if err != nil {
 		switch {
-		case errors.Is(err, errors.New("merchant not found")):
+		case errors.Is(err, ErrInvalidOptionGroup), errors.Is(err, ErrUnknownOption):
+			c.JSON(http.StatusBadRequest, gin.H{"error": []string{err.Error()}})
+		case errors.Is(err, ErrMerchantNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
+		case errors.Is(err, ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		case errors.Is(err, errors.New("validation failed")):
 			c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
 		default:
@@ -155,7 +185,106 @@ func (h *ItemHandler) CreateItem(c *gin.Context) {
 	})
 }
 
+// ImportItems creates many items at once from a CSV file or a JSON array.
+// The format follows the Content-Type (text/csv or application/json); a
+// multipart upload in field "file" is read as CSV when the file name ends in
+// .csv. With ?dryRun=true nothing is written and only the report is returned.
+func (h *ItemHandler) ImportItems(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchantId format"})
+		return
+	}
+
+	dryRun := false
+	if v := c.Query("dryRun"); v != "" {
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dryRun must be true or false"})
+			return
+		}
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+
+	var (
+		body  []byte
+		isCSV bool
+	)
+	switch c.ContentType() {
+	case "multipart/form-data":
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+			return
+		}
+		defer file.Close()
+		body, err = io.ReadAll(file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+			return
+		}
+		isCSV = strings.HasSuffix(strings.ToLower(fileHeader.Filename), ".csv")
+	default:
+		body, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "import file too large"})
+			return
+		}
+		isCSV = c.ContentType() == "text/csv"
+	}
+
+	var rows []importRow
+	if isCSV {
+		rows, err = parseItemsCSV(body)
+	} else {
+		rows, err = parseItemsJSON(body)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.itemService.ImportItems(c.Request.Context(), adminID, merchantID, rows, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNothingToImport):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "rows": resp.Errors})
+		case errors.Is(err, ErrMerchantNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
+		case errors.Is(err, ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
 func (h *ItemHandler) GetItems(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	merchantIDStr := c.Param("merchantId")
 	merchantID, err := uuid.Parse(merchantIDStr)
 	if err != nil {
@@ -215,12 +344,16 @@ func (h *ItemHandler) GetItems(c *gin.Context) {
 
 	}
 
-	data, total, err := h.itemService.ListItems(c.Request.Context(), merchantID, req)
+	data, total, err := h.itemService.ListItems(c.Request.Context(), adminID, merchantID, req)
 	if err != nil {
 		if errors.Is(err, ErrMerchantNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
 			return
 		}
+		if errors.Is(err, ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -235,6 +368,341 @@ func (h *ItemHandler) GetItems(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+func (h *ItemHandler) GetItem(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchantId format"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		return
+	}
+
+	resp, err := h.itemService.GetItem(c.Request.Context(), adminID, merchantID, itemID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		case errors.Is(err, ErrMerchantNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
+		case errors.Is(err, ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ItemHandler) UpdateItem(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchantId format"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		return
+	}
+
+	var req UpdateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+		return
+	}
+
+	if req.ImageUrl != nil && !isValidImageURL(*req.ImageUrl) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": []string{"ImageUrl must be a valid URL"},
+		})
+		return
+	}
+
+	if err := validator.New().Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": itemValidationMessages(validationErrors),
+			})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
+		return
+	}
+
+	resp, err := h.itemService.UpdateItem(c.Request.Context(), adminID, merchantID, itemID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		case errors.Is(err, ErrMerchantNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
+		case errors.Is(err, ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrInvalidOptionGroup), errors.Is(err, ErrUnknownOption):
+			c.JSON(http.StatusBadRequest, gin.H{"error": []string{err.Error()}})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ItemHandler) DeleteItem(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchantId format"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		return
+	}
+
+	if err := h.itemService.DeleteItem(c.Request.Context(), adminID, merchantID, itemID); err != nil {
+		switch {
+		case errors.Is(err, ErrItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		case errors.Is(err, ErrMerchantNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
+		case errors.Is(err, ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrItemInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ItemHandler) UpdateItemStock(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchantId format"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		return
+	}
+
+	var req UpdateItemStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+		return
+	}
+
+	if err := validator.New().Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			var errorMessages []string
+			for _, e := range validationErrors {
+				switch e.Tag() {
+				case "required":
+					errorMessages = append(errorMessages, e.Field()+" is required")
+				case "min":
+					errorMessages = append(errorMessages, e.Field()+" must be at least "+e.Param())
+				default:
+					errorMessages = append(errorMessages, "invalid "+e.Field())
+				}
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorMessages})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
+		return
+	}
+
+	resp, err := h.itemService.UpdateItemStock(c.Request.Context(), adminID, merchantID, itemID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		case errors.Is(err, ErrMerchantNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
+		case errors.Is(err, ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ItemHandler) UpdateItemOptions(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchantId format"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		return
+	}
+
+	var req UpdateItemOptionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+		return
+	}
+
+	if err := validator.New().Struct(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			var errorMessages []string
+			for _, e := range validationErrors {
+				errorMessages = append(errorMessages, optionGroupMessage(e))
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorMessages})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
+		return
+	}
+
+	resp, err := h.itemService.UpdateItemOptions(c.Request.Context(), adminID, merchantID, itemID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		case errors.Is(err, ErrMerchantNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
+		case errors.Is(err, ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrInvalidOptionGroup), errors.Is(err, ErrUnknownOption):
+			c.JSON(http.StatusBadRequest, gin.H{"error": []string{err.Error()}})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// itemValidationMessages describes the validation errors of an item request,
+// shared by create and update so both report the same rules
+func itemValidationMessages(validationErrors validator.ValidationErrors) []string {
+	var errorMessages []string
+	for _, e := range validationErrors {
+		field := e.Field()
+		tag := e.Tag()
+
+		if field == "OptionGroups" || field == "Options" || strings.Contains(e.Namespace(), ".OptionGroups[") {
+			errorMessages = append(errorMessages, optionGroupMessage(e))
+			continue
+		}
+
+		switch tag {
+		case "required":
+			errorMessages = append(errorMessages, field+" is required")
+		case "min":
+			if field == "Price" || field == "PreparationTimeInMinutes" || field == "Stock" {
+				errorMessages = append(errorMessages, field+" must be at least "+e.Param())
+			} else {
+				errorMessages = append(errorMessages, field+" must be at least "+e.Param()+" characters")
+			}
+		case "max":
+			if field == "PreparationTimeInMinutes" {
+				errorMessages = append(errorMessages, field+" must not exceed "+e.Param())
+			} else {
+				errorMessages = append(errorMessages, field+" must not exceed "+e.Param()+" characters")
+			}
+		case "oneof":
+			errorMessages = append(errorMessages, field+" must be one of: Beverage, Food, Snack, Condiments, Additions")
+		case "url":
+			errorMessages = append(errorMessages, field+" must be a valid URL")
+		default:
+			errorMessages = append(errorMessages, "invalid "+field)
+		}
+	}
+	return errorMessages
+}
+
+// optionGroupMessage describes a validation error inside optionGroups,
+// e.g. "OptionGroups[0].Options[1].Name is required"
+func optionGroupMessage(e validator.FieldError) string {
+	field := e.Namespace()
+	if i := strings.Index(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+
+	switch e.Tag() {
+	case "required":
+		return field + " is required"
+	case "min":
+		return field + " must be at least " + e.Param()
+	case "max":
+		return field + " must not exceed " + e.Param()
+	case "ltefield":
+		return field + " must not exceed MaxSelections"
+	default:
+		return "invalid " + field
+	}
+}
+
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	rawUserID, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, errors.New("user not authenticated")
+	}
+	userID, ok := rawUserID.(string)
+	if !ok {
+		return uuid.Nil, errors.New("invalid user context")
+	}
+	return uuid.Parse(userID)
+}
+
 func isValidImageURL(urlStr string) bool {
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
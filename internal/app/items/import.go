@@ -0,0 +1,176 @@
+package items
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	maxImportRows      = 1000            // rows per bulk import
+	maxImportBodyBytes = 5 * 1024 * 1024 // size of the uploaded file
+)
+
+// importRow is one parsed row of a bulk import. Errors holds the problems
+// found while parsing; validation adds to it later.
+type importRow struct {
+	Row    int
+	Item   CreateItemRequest
+	Errors []string
+}
+
+// csvImportColumns are the accepted CSV header names. Option groups are
+// nested, so they can only be imported from JSON.
+var csvImportColumns = map[string]bool{
+	"name":                     true,
+	"productCategory":          true,
+	"price":                    true,
+	"imageUrl":                 true,
+	"preparationTimeInMinutes": true,
+	"stock":                    true,
+	"isAvailable":              true,
+}
+
+// parseItemsJSON reads a JSON array of items. A row that does not decode into
+// CreateItemRequest is reported on its own instead of failing the whole file.
+func parseItemsJSON(body []byte) ([]importRow, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("%w: expected a JSON array of items", ErrInvalidImport)
+	}
+	if len(raw) > maxImportRows {
+		return nil, fmt.Errorf("%w: at most %d rows per import", ErrInvalidImport, maxImportRows)
+	}
+
+	rows := make([]importRow, len(raw))
+	for i, r := range raw {
+		rows[i].Row = i + 1
+		if err := json.Unmarshal(r, &rows[i].Item); err != nil {
+			rows[i].Errors = []string{"invalid JSON format"}
+		}
+	}
+	return rows, nil
+}
+
+// parseItemsCSV reads a CSV file whose first line names the columns
+func parseItemsCSV(body []byte) ([]importRow, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing CSV header", ErrInvalidImport)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// file CSV dari Excel sering diawali BOM
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !csvImportColumns[name] {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidImport, name)
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"name", "productCategory", "price", "imageUrl"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidImport, required)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("%w: at most %d rows per import", ErrInvalidImport, maxImportRows)
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row := importRow{Row: len(rows) + 1}
+		row.Item.Name = value("name")
+		row.Item.ProductCategory = value("productCategory")
+		row.Item.ImageUrl = value("imageUrl")
+
+		if v := value("price"); v != "" {
+			price, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				row.Errors = append(row.Errors, "Price must be a whole number")
+			}
+			row.Item.Price = price
+		}
+		if v := value("preparationTimeInMinutes"); v != "" {
+			minutes, err := strconv.Atoi(v)
+			if err != nil {
+				row.Errors = append(row.Errors, "PreparationTimeInMinutes must be a whole number")
+			} else {
+				row.Item.PreparationTimeInMinutes = &minutes
+			}
+		}
+		if v := value("stock"); v != "" {
+			stock, err := strconv.Atoi(v)
+			if err != nil {
+				row.Errors = append(row.Errors, "Stock must be a whole number")
+			} else {
+				row.Item.Stock = &stock
+			}
+		}
+		if v := value("isAvailable"); v != "" {
+			available, err := strconv.ParseBool(v)
+			if err != nil {
+				row.Errors = append(row.Errors, "IsAvailable must be true or false")
+			} else {
+				row.Item.IsAvailable = &available
+			}
+		}
+
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// validateImportRow applies the CreateItemRequest rules to a parsed row
+func validateImportRow(validate *validator.Validate, row *importRow) {
+	if len(row.Errors) > 0 {
+		return
+	}
+
+	if row.Item.ImageUrl != "" && !isValidImageURL(row.Item.ImageUrl) {
+		row.Errors = append(row.Errors, "ImageUrl must be a valid URL")
+	}
+	if err := validate.Struct(&row.Item); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			row.Errors = append(row.Errors, itemValidationMessages(validationErrors)...)
+		} else {
+			row.Errors = append(row.Errors, "validation failed")
+		}
+	}
+	if len(row.Errors) == 0 {
+		if err := validateOptionGroups(row.Item.OptionGroups); err != nil {
+			row.Errors = append(row.Errors, err.Error())
+		}
+		// item hasil import selalu baru, jadi belum punya grup atau opsi
+		if err := checkOptionIDs(row.Item.OptionGroups, optionIDs{}); err != nil {
+			row.Errors = append(row.Errors, err.Error())
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package items
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const csvHeader = "name,productCategory,price,imageUrl,preparationTimeInMinutes,stock,isAvailable\n"
+
+func TestParseItemsCSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    error
+		wantRows   int
+		wantErrors [][]string // per row
+	}{
+		{"empty file", "", ErrInvalidImport, 0, nil},
+		{"unknown column", "name,productCategory,price,imageUrl,color\n", ErrInvalidImport, 0, nil},
+		{"missing required column", "name,productCategory,price\n", ErrInvalidImport, 0, nil},
+		{"header only", csvHeader, nil, 0, nil},
+		{"header with BOM", "\ufeff" + csvHeader + "Es Teh,Beverage,5000,https://img.example.com/a.jpg,,,\n", nil, 1, [][]string{nil}},
+		{"columns in any order", "imageUrl,price,productCategory,name\nhttps://img.example.com/a.jpg,5000,Beverage,Es Teh\n", nil, 1, [][]string{nil}},
+		{
+			"bad numbers and booleans",
+			csvHeader + "Es Teh,Beverage,lima ribu,https://img.example.com/a.jpg,x,-,maybe\n",
+			nil, 1,
+			[][]string{{
+				"Price must be a whole number",
+				"PreparationTimeInMinutes must be a whole number",
+				"Stock must be a whole number",
+				"IsAvailable must be true or false",
+			}},
+		},
+		{"short record", csvHeader + "Es Teh,Beverage\n", nil, 1, [][]string{nil}},
+		{"malformed quote", csvHeader + "\"Es Teh,Beverage,5000,https://img.example.com/a.jpg\n", ErrInvalidImport, 0, nil},
+		{"too many rows", csvHeader + strings.Repeat("Es Teh,Beverage,5000,https://img.example.com/a.jpg,,,\n", maxImportRows+1), ErrInvalidImport, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseItemsCSV([]byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseItemsCSV() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(rows) != tt.wantRows {
+				t.Fatalf("parseItemsCSV() returned %d rows, want %d", len(rows), tt.wantRows)
+			}
+			for i, row := range rows {
+				if row.Row != i+1 {
+					t.Errorf("row %d numbered %d", i+1, row.Row)
+				}
+				if !reflect.DeepEqual(row.Errors, tt.wantErrors[i]) {
+					t.Errorf("row %d errors = %q, want %q", i+1, row.Errors, tt.wantErrors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseItemsCSVFields(t *testing.T) {
+	rows, err := parseItemsCSV([]byte(csvHeader + " Nasi Goreng , Food, 25000 ,https://img.example.com/b.jpg,15,3,false\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := rows[0].Item
+	if item.Name != "Nasi Goreng" || item.ProductCategory != "Food" || item.Price != 25000 {
+		t.Errorf("item = %+v", item)
+	}
+	if item.PreparationTimeInMinutes == nil || *item.PreparationTimeInMinutes != 15 {
+		t.Errorf("PreparationTimeInMinutes = %v, want 15", item.PreparationTimeInMinutes)
+	}
+	if item.Stock == nil || *item.Stock != 3 {
+		t.Errorf("Stock = %v, want 3", item.Stock)
+	}
+	if item.IsAvailable == nil || *item.IsAvailable {
+		t.Errorf("IsAvailable = %v, want false", item.IsAvailable)
+	}
+}
+
+func TestParseItemsJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    error
+		wantErrors [][]string
+	}{
+		{"not an array", `{"name":"Es Teh"}`, ErrInvalidImport, nil},
+		{"invalid JSON", `[{"name":`, ErrInvalidImport, nil},
+		{"empty array", `[]`, nil, nil},
+		{
+			"bad row reported on its own",
+			`[{"name":"Es Teh","price":5000},{"name":"Kopi","price":"mahal"}]`,
+			nil,
+			[][]string{nil, {"invalid JSON format"}},
+		},
+		{"too many rows", "[" + strings.Repeat(`{},`, maxImportRows) + "{}]", ErrInvalidImport, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseItemsJSON([]byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseItemsJSON() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(rows) != len(tt.wantErrors) {
+				t.Fatalf("parseItemsJSON() returned %d rows, want %d", len(rows), len(tt.wantErrors))
+			}
+			for i, row := range rows {
+				if !reflect.DeepEqual(row.Errors, tt.wantErrors[i]) {
+					t.Errorf("row %d errors = %q, want %q", i+1, row.Errors, tt.wantErrors[i])
+				}
+			}
+		})
+	}
+}
+
+func validImportItem() CreateItemRequest {
+	return CreateItemRequest{
+		Name:            "Es Teh",
+		ProductCategory: "Beverage",
+		Price:           5000,
+		ImageUrl:        "https://img.example.com/a.jpg",
+	}
+}
+
+func TestValidateImportRow(t *testing.T) {
+	minutes := 300
+	tests := []struct {
+		name       string
+		edit       func(*CreateItemRequest)
+		parseErrs  []string
+		wantErrors []string
+	}{
+		{"valid", func(*CreateItemRequest) {}, nil, nil},
+		{"parse errors are kept as is", func(i *CreateItemRequest) { i.Name = "" }, []string{"Price must be a whole number"}, []string{"Price must be a whole number"}},
+		{"missing name", func(i *CreateItemRequest) { i.Name = "" }, nil, []string{"Name is required"}},
+		{"unknown category", func(i *CreateItemRequest) { i.ProductCategory = "Dessert" }, nil, []string{"ProductCategory must be one of: Beverage, Food, Snack, Condiments, Additions"}},
+		{"zero price", func(i *CreateItemRequest) { i.Price = 0 }, nil, []string{"Price is required"}},
+		{"image without host", func(i *CreateItemRequest) { i.ImageUrl = "https://localhost/a.jpg" }, nil, []string{"ImageUrl must be a valid URL"}},
+		{"preparation too long", func(i *CreateItemRequest) { i.PreparationTimeInMinutes = &minutes }, nil, []string{"PreparationTimeInMinutes must not exceed 240"}},
+		{
+			"group needs more options than it has",
+			func(i *CreateItemRequest) {
+				i.OptionGroups = []OptionGroupRequest{{Name: "Ukuran", MinSelections: 2, MaxSelections: 2, Options: []OptionRequest{{Name: "Besar"}}}}
+			},
+			nil,
+			[]string{ErrInvalidOptionGroup.Error()},
+		},
+		{
+			"option id on a new item",
+			func(i *CreateItemRequest) {
+				i.OptionGroups = []OptionGroupRequest{{Name: "Ukuran", MaxSelections: 1, Options: []OptionRequest{{OptionID: uuid.NewString(), Name: "Besar"}}}}
+			},
+			nil,
+			[]string{ErrUnknownOption.Error()},
+		},
+	}
+
+	validate := validator.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := importRow{Row: 1, Item: validImportItem(), Errors: tt.parseErrs}
+			tt.edit(&row.Item)
+			validateImportRow(validate, &row)
+			if !reflect.DeepEqual(row.Errors, tt.wantErrors) {
+				t.Errorf("errors = %q, want %q", row.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestImportItemsDryRun(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	adminID, merchantID := uuid.New(), uuid.New()
+	// fakeItemsDB fails every write, so a dry run that touched the database would error
+	s := NewItemService(database.New(&fakeItemsDB{adminID: adminID}), nil, nil)
+
+	invalid := validImportItem()
+	invalid.Price = 0
+	rows := []importRow{
+		{Row: 1, Item: validImportItem()},
+		{Row: 2, Item: invalid},
+		{Row: 3, Item: validImportItem(), Errors: []string{"invalid JSON format"}},
+	}
+
+	resp, err := s.ImportItems(context.Background(), adminID, merchantID, rows, true)
+	if err != nil {
+		t.Fatalf("ImportItems() error = %v", err)
+	}
+	if !resp.DryRun || resp.Total != 3 || resp.Valid != 1 || resp.Failed != 2 || resp.Imported != 0 || len(resp.ItemIDs) != 0 {
+		t.Errorf("response = %+v", resp)
+	}
+	want := []ImportRowError{
+		{Row: 2, Errors: []string{"Price is required"}},
+		{Row: 3, Errors: []string{"invalid JSON format"}},
+	}
+	if !reflect.DeepEqual(resp.Errors, want) {
+		t.Errorf("errors = %v, want %v", resp.Errors, want)
+	}
+}
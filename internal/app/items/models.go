@@ -11,6 +11,99 @@ type CreateItemRequest struct {
 	ProductCategory string `json:"productCategory" validate:"required,oneof=Beverage Food Snack Condiments Additions"`
 	Price           int64  `json:"price" validate:"required,min=1"`
 	ImageUrl        string `json:"imageUrl" validate:"required,url"`
+	// nil berarti mengikuti waktu persiapan merchant
+	PreparationTimeInMinutes *int `json:"preparationTimeInMinutes" validate:"omitempty,min=0,max=240"`
+	// nil berarti stok tidak dilacak
+	Stock        *int                 `json:"stock" validate:"omitempty,min=0"`
+	IsAvailable  *bool                `json:"isAvailable"`
+	OptionGroups []OptionGroupRequest `json:"optionGroups" validate:"max=20,dive"`
+}
+
+// UpdateItemRequest changes only the fields that are present, with the same
+// rules as CreateItemRequest. OptionGroups, when present, replaces all groups.
+// Untracking stock again goes through the stock endpoint.
+type UpdateItemRequest struct {
+	Name                     *string               `json:"name" validate:"omitempty,min=2,max=30"`
+	ProductCategory          *string               `json:"productCategory" validate:"omitempty,oneof=Beverage Food Snack Condiments Additions"`
+	Price                    *int64                `json:"price" validate:"omitempty,min=1"`
+	ImageUrl                 *string               `json:"imageUrl" validate:"omitempty,url"`
+	PreparationTimeInMinutes *int                  `json:"preparationTimeInMinutes" validate:"omitempty,min=0,max=240"`
+	Stock                    *int                  `json:"stock" validate:"omitempty,min=0"`
+	IsAvailable              *bool                 `json:"isAvailable"`
+	OptionGroups             *[]OptionGroupRequest `json:"optionGroups" validate:"omitempty,max=20,dive"`
+}
+
+// OptionGroupRequest is one choice on an item (size, spice level, toppings).
+// The user picks between MinSelections and MaxSelections of its options.
+// GroupID and OptionID are left out for new groups and options; existing
+// ones are sent back with their id so estimates keep pointing at them.
+type OptionGroupRequest struct {
+	GroupID       string          `json:"groupId" validate:"omitempty,uuid"`
+	Name          string          `json:"name" validate:"required,min=1,max=50"`
+	MinSelections int             `json:"minSelections" validate:"min=0,ltefield=MaxSelections"`
+	MaxSelections int             `json:"maxSelections" validate:"required,min=1"`
+	Options       []OptionRequest `json:"options" validate:"required,min=1,max=50,dive"`
+}
+
+type OptionRequest struct {
+	OptionID   string `json:"optionId" validate:"omitempty,uuid"`
+	Name       string `json:"name" validate:"required,min=1,max=50"`
+	PriceDelta int64  `json:"priceDelta" validate:"min=0"` // added to the item price per unit
+}
+
+// UpdateItemOptionsRequest replaces all option groups of an item. Groups and
+// options missing from the request are removed.
+type UpdateItemOptionsRequest struct {
+	OptionGroups []OptionGroupRequest `json:"optionGroups" validate:"max=20,dive"`
+}
+
+type ItemOptionsResponse struct {
+	ItemID       string                `json:"itemId"`
+	OptionGroups []OptionGroupResponse `json:"optionGroups"`
+}
+
+type OptionGroupResponse struct {
+	GroupID       string           `json:"groupId"`
+	Name          string           `json:"name"`
+	MinSelections int              `json:"minSelections"`
+	MaxSelections int              `json:"maxSelections"`
+	Options       []OptionResponse `json:"options"`
+}
+
+type OptionResponse struct {
+	OptionID   string `json:"optionId"`
+	Name       string `json:"name"`
+	PriceDelta int64  `json:"priceDelta"`
+}
+
+// UpdateItemStockRequest replaces an item's stock count and availability
+type UpdateItemStockRequest struct {
+	// nil berarti stok tidak dilacak
+	Stock       *int  `json:"stock" validate:"omitempty,min=0"`
+	IsAvailable *bool `json:"isAvailable" validate:"required"`
+}
+
+type ItemStockResponse struct {
+	ItemID      string `json:"itemId"`
+	Stock       *int   `json:"stock"`
+	IsAvailable bool   `json:"isAvailable"`
+}
+
+// ImportItemsResponse reports a bulk import. Rows are numbered from 1 in
+// file order, not counting the CSV header.
+type ImportItemsResponse struct {
+	DryRun   bool             `json:"dryRun"`
+	Total    int              `json:"total"`
+	Valid    int              `json:"valid"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	ItemIDs  []string         `json:"itemIds"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+type ImportRowError struct {
+	Row    int      `json:"row"`
+	Errors []string `json:"errors"`
 }
 
 // items/types.go
@@ -30,6 +123,11 @@ type ItemResponse struct {
 	Price           int64  `json:"price"`
 	ImageUrl        string `json:"imageUrl"`
 	CreatedAt       string `json:"createdAt"`
+
+	PreparationTimeInMinutes *int                  `json:"preparationTimeInMinutes,omitempty"`
+	Stock                    *int                  `json:"stock"`
+	IsAvailable              bool                  `json:"isAvailable"`
+	OptionGroups             []OptionGroupResponse `json:"optionGroups"`
 }
 
 type ListItemsResponse struct {
@@ -41,4 +139,15 @@ type ListItemsResponse struct {
 	} `json:"meta"`
 }
 
-var ErrMerchantNotFound = errors.New("merchant not found")
+var (
+	ErrMerchantNotFound = errors.New("merchant not found")
+	ErrItemNotFound     = errors.New("item not found")
+	ErrForbidden        = errors.New("merchant belongs to another admin")
+	ErrItemInUse        = errors.New("item is part of orders still in progress")
+	ErrInvalidImport    = errors.New("invalid import file")
+	ErrNothingToImport  = errors.New("no valid rows to import")
+	// ErrInvalidOptionGroup is returned when a group requires more selections than it has options
+	ErrInvalidOptionGroup = errors.New("minSelections exceeds the number of options")
+	// ErrUnknownOption is returned when a groupId or optionId is not one of the item's
+	ErrUnknownOption = errors.New("groupId or optionId does not belong to the item")
+)
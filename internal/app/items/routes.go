@@ -13,5 +13,11 @@ func ItemRoutes(router *gin.Engine, handler *ItemHandler, jwt *jwt.JWTService) {
 	{
 		items.POST("/:merchantId/items", handler.CreateItem)
 		items.GET("/:merchantId/items", handler.GetItems)
+		items.POST("/:merchantId/items/import", handler.ImportItems)
+		items.GET("/:merchantId/items/:itemId", handler.GetItem)
+		items.PATCH("/:merchantId/items/:itemId", handler.UpdateItem)
+		items.DELETE("/:merchantId/items/:itemId", handler.DeleteItem)
+		items.PUT("/:merchantId/items/:itemId/stock", handler.UpdateItemStock)
+		items.PUT("/:merchantId/items/:itemId/options", handler.UpdateItemOptions)
 	}
 }
@@ -10,38 +10,63 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
 )
 
 type ItemService struct {
 	queries *database.Queries
+	store   *database.DB
 	cache   *cache.RedisCache
 }
 
-func NewItemService(queries *database.Queries, cache *cache.RedisCache) *ItemService {
+func NewItemService(queries *database.Queries, store *database.DB, cache *cache.RedisCache) *ItemService {
 	return &ItemService{
 		queries: queries,
+		store:   store,
 		cache:   cache,
 	}
 }
 
-func (s *ItemService) CreateItem(ctx context.Context, merchantID uuid.UUID, req CreateItemRequest) (uuid.UUID, error) {
-	exists, err := s.queries.MerchantExists(ctx, merchantID)
-	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to check merchant: %w", err)
+func (s *ItemService) CreateItem(ctx context.Context, adminID, merchantID uuid.UUID, req CreateItemRequest) (uuid.UUID, error) {
+	if err := s.authorizeMerchant(ctx, adminID, merchantID); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := validateOptionGroups(req.OptionGroups); err != nil {
+		return uuid.Nil, err
 	}
-	if !exists {
-		return uuid.Nil, errors.New("merchant not found")
+
+	isAvailable := true
+	if req.IsAvailable != nil {
+		isAvailable = *req.IsAvailable
 	}
 
-	itemID, err := s.queries.CreateItem(ctx, database.CreateItemParams{
-		Merchantid:      merchantID,
-		Name:            req.Name,
-		Productcategory: req.ProductCategory,
-		Price:           req.Price,
-		Imageurl:        req.ImageUrl,
+	var itemID uuid.UUID
+	err := s.store.WithTx(ctx, func(q *database.Queries) error {
+		var err error
+		itemID, err = q.CreateItem(ctx, database.CreateItemParams{
+			Merchantid:      merchantID,
+			Name:            req.Name,
+			Productcategory: req.ProductCategory,
+			Price:           req.Price,
+			Imageurl:        req.ImageUrl,
+
+			Preparationtimeminutes: req.PreparationTimeInMinutes,
+			Stock:                  req.Stock,
+			Isavailable:            isAvailable,
+		})
+		if err != nil {
+			return err
+		}
+		return saveOptionGroups(ctx, q, itemID, req.OptionGroups)
 	})
 	if err != nil {
+		if errors.Is(err, ErrUnknownOption) {
+			return uuid.Nil, err
+		}
 		return uuid.Nil, fmt.Errorf("failed to create item: %w", err)
 	}
 
@@ -54,26 +79,29 @@ func (s *ItemService) CreateItem(ctx context.Context, merchantID uuid.UUID, req
 	return itemID, nil
 }
 
-func (s *ItemService) ListItems(ctx context.Context, merchantID uuid.UUID, req ListItemsRequest) ([]ItemResponse, int64, error) {
-	exists, err := s.queries.MerchantExists(ctx, merchantID)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to check merchant: %w", err)
-	}
-	if !exists {
-		return nil, 0, errors.New("merchant not found")
+func (s *ItemService) ListItems(ctx context.Context, adminID, merchantID uuid.UUID, req ListItemsRequest) ([]ItemResponse, int64, error) {
+	if err := s.authorizeMerchant(ctx, adminID, merchantID); err != nil {
+		return nil, 0, err
 	}
 
-	cacheKey := s.generateListItemsCacheKey(merchantID, req)
+	// tanpa versi cache, hasil tidak boleh dibaca maupun disimpan ke cache
+	version, err := s.itemsCacheVersion(ctx, merchantID)
+	useCache := err == nil
+	if !useCache {
+		logger.WarnCtx(ctx, "Failed to read merchant items cache version", "merchantID", merchantID, "error", err)
+	}
+	cacheKey := s.generateListItemsCacheKey(merchantID, version, req)
 
 	var cachedResult struct {
 		Items []ItemResponse
 		Total int64
 	}
 
-	err = s.cache.Get(ctx, cacheKey, &cachedResult)
-	if err == nil {
-		logger.DebugCtx(ctx, "ListItems cache hit", "key", cacheKey)
-		return cachedResult.Items, cachedResult.Total, nil
+	if useCache {
+		if err := s.cache.Get(ctx, cacheKey, &cachedResult); err == nil {
+			logger.DebugCtx(ctx, "ListItems cache hit", "key", cacheKey)
+			return cachedResult.Items, cachedResult.Total, nil
+		}
 	}
 
 	logger.DebugCtx(ctx, "ListItems cache miss, fetching from database", "key", cacheKey)
@@ -143,8 +171,22 @@ func (s *ItemService) ListItems(ctx context.Context, merchantID uuid.UUID, req L
 		return nil, 0, fmt.Errorf("failed to count items: %w", err)
 	}
 
+	itemIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		itemIDs[i] = item.ID
+	}
+	optionGroups, err := ListOptionGroups(ctx, s.queries, itemIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	responses := make([]ItemResponse, len(items))
 	for i, item := range items {
+		groups := optionGroups[item.ID]
+		if groups == nil {
+			groups = []OptionGroupResponse{}
+		}
+
 		responses[i] = ItemResponse{
 			ItemID:          item.ID.String(),
 			Name:            item.Name,
@@ -152,6 +194,11 @@ func (s *ItemService) ListItems(ctx context.Context, merchantID uuid.UUID, req L
 			Price:           item.Price,
 			ImageUrl:        item.ImageUrl,
 			CreatedAt:       item.CreatedAt.Format(time.RFC3339Nano),
+
+			PreparationTimeInMinutes: item.PreparationTimeMinutes,
+			Stock:                    item.Stock,
+			IsAvailable:              item.IsAvailable,
+			OptionGroups:             groups,
 		}
 	}
 
@@ -163,20 +210,458 @@ func (s *ItemService) ListItems(ctx context.Context, merchantID uuid.UUID, req L
 		Total: total,
 	}
 
-	err = s.cache.Set(ctx, cacheKey, cacheData, cache.ProductListTTL)
-	if err != nil {
-		logger.WarnCtx(ctx, "Failed to cache ListItems result", "key", cacheKey, "error", err)
+	if useCache {
+		err = s.cache.Set(ctx, cacheKey, cacheData, cache.ProductListTTL)
+		if err != nil {
+			logger.WarnCtx(ctx, "Failed to cache ListItems result", "key", cacheKey, "error", err)
+		}
 	}
 
 	return responses, total, nil
 }
 
+// GetItem returns a single item of a merchant, served through the same cache as ListItems
+func (s *ItemService) GetItem(ctx context.Context, adminID, merchantID, itemID uuid.UUID) (ItemResponse, error) {
+	items, _, err := s.ListItems(ctx, adminID, merchantID, ListItemsRequest{ItemID: &itemID, Limit: 1})
+	if err != nil {
+		return ItemResponse{}, err
+	}
+	if len(items) == 0 {
+		return ItemResponse{}, ErrItemNotFound
+	}
+	return items[0], nil
+}
+
+// UpdateItem applies a partial update to a merchant's item
+func (s *ItemService) UpdateItem(ctx context.Context, adminID, merchantID, itemID uuid.UUID, req UpdateItemRequest) (ItemResponse, error) {
+	if err := s.authorizeMerchant(ctx, adminID, merchantID); err != nil {
+		return ItemResponse{}, err
+	}
+	if req.OptionGroups != nil {
+		if err := validateOptionGroups(*req.OptionGroups); err != nil {
+			return ItemResponse{}, err
+		}
+	}
+
+	err := s.store.WithTx(ctx, func(q *database.Queries) error {
+		updated, err := q.UpdateItem(ctx, database.UpdateItemParams{
+			Name:                   req.Name,
+			ProductCategory:        req.ProductCategory,
+			Price:                  req.Price,
+			ImageUrl:               req.ImageUrl,
+			PreparationTimeMinutes: req.PreparationTimeInMinutes,
+			Stock:                  req.Stock,
+			IsAvailable:            req.IsAvailable,
+			ItemID:                 itemID,
+			MerchantID:             merchantID,
+		})
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrItemNotFound
+		}
+
+		if req.OptionGroups == nil {
+			return nil
+		}
+		return saveOptionGroups(ctx, q, itemID, *req.OptionGroups)
+	})
+	if err != nil {
+		if errors.Is(err, ErrItemNotFound) || errors.Is(err, ErrUnknownOption) {
+			return ItemResponse{}, err
+		}
+		return ItemResponse{}, fmt.Errorf("failed to update item: %w", err)
+	}
+
+	err = s.invalidateMerchantItemsCache(ctx, merchantID)
+	if err != nil {
+		logger.WarnCtx(ctx, "Failed to invalidate merchant items cache", "merchantID", merchantID, "error", err)
+	}
+
+	return s.GetItem(ctx, adminID, merchantID, itemID)
+}
+
+// DeleteItem deletes a merchant's item. The item is only marked as deleted:
+// past orders keep it, while listings and new orders no longer see it. Items
+// in orders that are still in progress cannot be deleted.
+func (s *ItemService) DeleteItem(ctx context.Context, adminID, merchantID, itemID uuid.UUID) error {
+	if err := s.authorizeMerchant(ctx, adminID, merchantID); err != nil {
+		return err
+	}
+
+	err := s.store.WithTx(ctx, func(q *database.Queries) error {
+		active, err := q.HasActiveItemOrders(ctx, itemID)
+		if err != nil {
+			return err
+		}
+		if active {
+			return ErrItemInUse
+		}
+
+		deleted, err := q.DeleteItem(ctx, database.DeleteItemParams{
+			ItemID:     itemID,
+			MerchantID: merchantID,
+		})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrItemNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrItemNotFound) || errors.Is(err, ErrItemInUse) {
+			return err
+		}
+		return fmt.Errorf("failed to delete item: %w", err)
+	}
+
+	err = s.invalidateMerchantItemsCache(ctx, merchantID)
+	if err != nil {
+		logger.WarnCtx(ctx, "Failed to invalidate merchant items cache", "merchantID", merchantID, "error", err)
+	}
+
+	return nil
+}
+
+// ImportItems validates every row against the CreateItemRequest rules and,
+// unless dryRun is set, inserts the valid rows in one transaction with COPY.
+// Invalid rows are reported and skipped.
+func (s *ItemService) ImportItems(ctx context.Context, adminID, merchantID uuid.UUID, rows []importRow, dryRun bool) (ImportItemsResponse, error) {
+	if err := s.authorizeMerchant(ctx, adminID, merchantID); err != nil {
+		return ImportItemsResponse{}, err
+	}
+
+	resp := ImportItemsResponse{
+		DryRun:  dryRun,
+		Total:   len(rows),
+		ItemIDs: []string{},
+		Errors:  []ImportRowError{},
+	}
+
+	validate := validator.New()
+	valid := make([]importRow, 0, len(rows))
+	for i := range rows {
+		validateImportRow(validate, &rows[i])
+		if len(rows[i].Errors) > 0 {
+			resp.Errors = append(resp.Errors, ImportRowError{Row: rows[i].Row, Errors: rows[i].Errors})
+			continue
+		}
+		valid = append(valid, rows[i])
+	}
+	resp.Valid = len(valid)
+	resp.Failed = len(resp.Errors)
+
+	if dryRun {
+		return resp, nil
+	}
+	if len(valid) == 0 {
+		return resp, ErrNothingToImport
+	}
+
+	// created_at naik per baris supaya urutan menu mengikuti urutan file
+	createdAt := time.Now()
+	params := make([]database.CopyItemsParams, len(valid))
+	for i, row := range valid {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return ImportItemsResponse{}, fmt.Errorf("failed to generate item id: %w", err)
+		}
+		isAvailable := true
+		if row.Item.IsAvailable != nil {
+			isAvailable = *row.Item.IsAvailable
+		}
+		params[i] = database.CopyItemsParams{
+			ID:                     id,
+			MerchantID:             merchantID,
+			Name:                   row.Item.Name,
+			ProductCategory:        row.Item.ProductCategory,
+			Price:                  row.Item.Price,
+			ImageUrl:               row.Item.ImageUrl,
+			PreparationTimeMinutes: row.Item.PreparationTimeInMinutes,
+			Stock:                  row.Item.Stock,
+			IsAvailable:            isAvailable,
+			CreatedAt:              createdAt.Add(time.Duration(i) * time.Microsecond),
+		}
+	}
+
+	err := s.store.WithTx(ctx, func(q *database.Queries) error {
+		if _, err := q.CopyItems(ctx, params); err != nil {
+			return err
+		}
+		for i, row := range valid {
+			if len(row.Item.OptionGroups) == 0 {
+				continue
+			}
+			if err := saveOptionGroups(ctx, q, params[i].ID, row.Item.OptionGroups); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ImportItemsResponse{}, fmt.Errorf("failed to import items: %w", err)
+	}
+
+	err = s.invalidateMerchantItemsCache(ctx, merchantID)
+	if err != nil {
+		logger.WarnCtx(ctx, "Failed to invalidate merchant items cache", "merchantID", merchantID, "error", err)
+	}
+
+	resp.Imported = len(params)
+	for _, p := range params {
+		resp.ItemIDs = append(resp.ItemIDs, p.ID.String())
+	}
+	logger.InfoCtx(ctx, "Items imported", "merchantID", merchantID, "imported", resp.Imported, "failed", resp.Failed)
+	return resp, nil
+}
+
+// UpdateItemStock replaces the stock count and availability of a merchant's item
+func (s *ItemService) UpdateItemStock(ctx context.Context, adminID, merchantID, itemID uuid.UUID, req UpdateItemStockRequest) (ItemStockResponse, error) {
+	if err := s.authorizeMerchant(ctx, adminID, merchantID); err != nil {
+		return ItemStockResponse{}, err
+	}
+
+	updated, err := s.queries.UpdateItemStock(ctx, database.UpdateItemStockParams{
+		Stock:       req.Stock,
+		IsAvailable: *req.IsAvailable,
+		ItemID:      itemID,
+		MerchantID:  merchantID,
+	})
+	if err != nil {
+		return ItemStockResponse{}, fmt.Errorf("failed to update item stock: %w", err)
+	}
+	if updated == 0 {
+		return ItemStockResponse{}, ErrItemNotFound
+	}
+
+	err = s.invalidateMerchantItemsCache(ctx, merchantID)
+	if err != nil {
+		logger.WarnCtx(ctx, "Failed to invalidate merchant items cache", "merchantID", merchantID, "error", err)
+	}
+
+	return ItemStockResponse{
+		ItemID:      itemID.String(),
+		Stock:       req.Stock,
+		IsAvailable: *req.IsAvailable,
+	}, nil
+}
+
+// UpdateItemOptions replaces all option groups of a merchant's item. Groups
+// and options sent with their id are updated in place, so open estimates are
+// repriced with their new price; estimates and orders keep a copy of options
+// that are removed.
+func (s *ItemService) UpdateItemOptions(ctx context.Context, adminID, merchantID, itemID uuid.UUID, req UpdateItemOptionsRequest) (ItemOptionsResponse, error) {
+	if err := s.authorizeMerchant(ctx, adminID, merchantID); err != nil {
+		return ItemOptionsResponse{}, err
+	}
+	if err := validateOptionGroups(req.OptionGroups); err != nil {
+		return ItemOptionsResponse{}, err
+	}
+
+	err := s.store.WithTx(ctx, func(q *database.Queries) error {
+		exists, err := q.ItemExists(ctx, database.ItemExistsParams{
+			ItemID:     itemID,
+			MerchantID: merchantID,
+		})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrItemNotFound
+		}
+
+		return saveOptionGroups(ctx, q, itemID, req.OptionGroups)
+	})
+	if err != nil {
+		if errors.Is(err, ErrItemNotFound) || errors.Is(err, ErrUnknownOption) {
+			return ItemOptionsResponse{}, err
+		}
+		return ItemOptionsResponse{}, fmt.Errorf("failed to update item options: %w", err)
+	}
+
+	err = s.invalidateMerchantItemsCache(ctx, merchantID)
+	if err != nil {
+		logger.WarnCtx(ctx, "Failed to invalidate merchant items cache", "merchantID", merchantID, "error", err)
+	}
+
+	groups, err := ListOptionGroups(ctx, s.queries, []uuid.UUID{itemID})
+	if err != nil {
+		return ItemOptionsResponse{}, err
+	}
+	resp := ItemOptionsResponse{ItemID: itemID.String(), OptionGroups: groups[itemID]}
+	if resp.OptionGroups == nil {
+		resp.OptionGroups = []OptionGroupResponse{}
+	}
+	return resp, nil
+}
+
+// validateOptionGroups checks what the struct tags cannot: a group must have
+// enough options to satisfy its minimum
+func validateOptionGroups(groups []OptionGroupRequest) error {
+	for _, g := range groups {
+		if g.MinSelections > len(g.Options) {
+			return ErrInvalidOptionGroup
+		}
+	}
+	return nil
+}
+
+// saveOptionGroups makes groups the option groups of an item, in this order
+// for display. Groups and options with an id are updated, those without one
+// are created, and the item's other groups and options are deleted.
+func saveOptionGroups(ctx context.Context, q *database.Queries, itemID uuid.UUID, groups []OptionGroupRequest) error {
+	rows, err := q.ListItemOptions(ctx, []uuid.UUID{itemID})
+	if err != nil {
+		return err
+	}
+	existing := optionIDs{groups: make(map[uuid.UUID]bool), options: make(map[uuid.UUID]bool)}
+	for _, row := range rows {
+		existing.groups[row.GroupID] = true
+		existing.options[row.OptionID] = true
+	}
+	if err := checkOptionIDs(groups, existing); err != nil {
+		return err
+	}
+
+	// opsi yang tidak dikirim dihapus sebelum upsert, selagi opsi baru belum punya id;
+	// slice kosong (bukan nil) agar ANY() tidak bernilai NULL
+	keepOptions := make([]uuid.UUID, 0)
+	for _, g := range groups {
+		for _, opt := range g.Options {
+			if opt.OptionID != "" {
+				keepOptions = append(keepOptions, uuid.MustParse(opt.OptionID))
+			}
+		}
+	}
+	err = q.DeleteItemOptionsExcept(ctx, database.DeleteItemOptionsExceptParams{
+		ItemID:  itemID,
+		KeepIds: keepOptions,
+	})
+	if err != nil {
+		return err
+	}
+
+	keepGroups := make([]uuid.UUID, 0, len(groups))
+	for i, g := range groups {
+		params := database.UpsertItemOptionGroupParams{
+			ItemID:        itemID,
+			Name:          g.Name,
+			MinSelections: g.MinSelections,
+			MaxSelections: g.MaxSelections,
+			SortOrder:     i + 1,
+		}
+		if g.GroupID != "" {
+			params.GroupID = uuid.MustParse(g.GroupID)
+		}
+		groupID, err := q.UpsertItemOptionGroup(ctx, params)
+		if err != nil {
+			return err
+		}
+		keepGroups = append(keepGroups, groupID)
+
+		options := database.UpsertItemOptionsParams{GroupID: groupID}
+		for _, opt := range g.Options {
+			optionID := uuid.Nil
+			if opt.OptionID != "" {
+				optionID = uuid.MustParse(opt.OptionID)
+			}
+			options.OptionIds = append(options.OptionIds, optionID)
+			options.Names = append(options.Names, opt.Name)
+			options.PriceDeltas = append(options.PriceDeltas, opt.PriceDelta)
+		}
+		if err := q.UpsertItemOptions(ctx, options); err != nil {
+			return err
+		}
+	}
+
+	// opsi yang dipindah ke grup lain sudah keluar dari grup yang dihapus
+	return q.DeleteItemOptionGroupsExcept(ctx, database.DeleteItemOptionGroupsExceptParams{
+		ItemID:  itemID,
+		KeepIds: keepGroups,
+	})
+}
+
+// optionIDs are the ids of an item's current option groups and options
+type optionIDs struct {
+	groups  map[uuid.UUID]bool
+	options map[uuid.UUID]bool
+}
+
+// checkOptionIDs returns ErrUnknownOption unless every id sent in groups is
+// one of the item's, each used once. A new item has none.
+func checkOptionIDs(groups []OptionGroupRequest, existing optionIDs) error {
+	seen := make(map[string]bool)
+	check := func(id string, known map[uuid.UUID]bool) error {
+		if id == "" {
+			return nil
+		}
+		parsed, err := uuid.Parse(id)
+		if err != nil || !known[parsed] || seen[id] {
+			return ErrUnknownOption
+		}
+		seen[id] = true
+		return nil
+	}
+
+	for _, g := range groups {
+		if err := check(g.GroupID, existing.groups); err != nil {
+			return err
+		}
+		for _, opt := range g.Options {
+			if err := check(opt.OptionID, existing.options); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ListOptionGroups returns the option groups of each item in display order.
+// Purchase lists them the same way for the nearby merchants.
+func ListOptionGroups(ctx context.Context, q *database.Queries, itemIDs []uuid.UUID) (map[uuid.UUID][]OptionGroupResponse, error) {
+	groups := make(map[uuid.UUID][]OptionGroupResponse)
+	if len(itemIDs) == 0 {
+		return groups, nil
+	}
+
+	rows, err := q.ListItemOptions(ctx, itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list item options: %w", err)
+	}
+
+	var lastGroupID uuid.UUID
+	for _, row := range rows {
+		if row.GroupID != lastGroupID {
+			groups[row.ItemID] = append(groups[row.ItemID], OptionGroupResponse{
+				GroupID:       row.GroupID.String(),
+				Name:          row.GroupName,
+				MinSelections: row.MinSelections,
+				MaxSelections: row.MaxSelections,
+				Options:       []OptionResponse{},
+			})
+			lastGroupID = row.GroupID
+		}
+		itemGroups := groups[row.ItemID]
+		group := &itemGroups[len(itemGroups)-1]
+		group.Options = append(group.Options, OptionResponse{
+			OptionID:   row.OptionID.String(),
+			Name:       row.OptionName,
+			PriceDelta: row.PriceDelta,
+		})
+	}
+	return groups, nil
+}
+
 // generateListItemsCacheKey generates a consistent cache key based on the request parameters
-func (s *ItemService) generateListItemsCacheKey(merchantID uuid.UUID, req ListItemsRequest) string {
+func (s *ItemService) generateListItemsCacheKey(merchantID uuid.UUID, version int64, req ListItemsRequest) string {
 	keyParts := []string{
 		"items",
 		"list",
 		merchantID.String(),
+		fmt.Sprintf("v%d", version),
 		fmt.Sprintf("limit:%d", req.Limit),
 		fmt.Sprintf("offset:%d", req.Offset),
 	}
@@ -200,12 +685,54 @@ func (s *ItemService) generateListItemsCacheKey(merchantID uuid.UUID, req ListIt
 	return strings.Join(keyParts, ":")
 }
 
+// authorizeMerchant checks that the merchant exists and that adminID owns it
+// or is a platform admin
+func (s *ItemService) authorizeMerchant(ctx context.Context, adminID, merchantID uuid.UUID) error {
+	access, err := s.queries.GetMerchantAccess(ctx, database.GetMerchantAccessParams{
+		AdminID:    adminID,
+		MerchantID: merchantID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrMerchantNotFound
+		}
+		return fmt.Errorf("failed to check merchant: %w", err)
+	}
+	if !access.Allowed {
+		logger.WarnCtx(ctx, "Admin is not allowed to manage merchant", "adminId", adminID, "merchantId", merchantID)
+		return ErrForbidden
+	}
+	return nil
+}
+
+// itemsCacheVersion returns the version of a merchant's cached item lists.
+// Every write bumps it, so a list read from the database before the write
+// can only be cached under an old key that no later request reads.
+func (s *ItemService) itemsCacheVersion(ctx context.Context, merchantID uuid.UUID) (int64, error) {
+	version, err := s.cache.Client().Get(ctx, fmt.Sprintf(cache.ItemsVersionKey, merchantID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return version, err
+}
+
 // invalidateMerchantItemsCache menghapus semua cache terkait item milik merchant
 func (s *ItemService) invalidateMerchantItemsCache(ctx context.Context, merchantID uuid.UUID) error {
+	return InvalidateMerchantItemsCache(ctx, s.cache, merchantID)
+}
+
+// InvalidateMerchantItemsCache bumps the merchant's items cache version and
+// removes its cached item lists. The merchant service calls it on delete.
+func InvalidateMerchantItemsCache(ctx context.Context, redisCache *cache.RedisCache, merchantID uuid.UUID) error {
+	// naikkan versi dulu, baru bersihkan key lama
+	if err := redisCache.Client().Incr(ctx, fmt.Sprintf(cache.ItemsVersionKey, merchantID)).Err(); err != nil {
+		return fmt.Errorf("failed to bump items cache version: %w", err)
+	}
+
 	pattern := fmt.Sprintf("items:*:%s*", merchantID.String())
 
 	// Gunakan SCAN untuk mencari dan menghapus key secara aman
-	err := s.scanAndDeleteKeys(ctx, pattern)
+	err := deleteKeysMatching(ctx, redisCache, pattern)
 	if err != nil {
 		return fmt.Errorf("failed to scan and delete keys with pattern %s: %w", pattern, err)
 	}
@@ -216,7 +743,11 @@ func (s *ItemService) invalidateMerchantItemsCache(ctx context.Context, merchant
 
 // scanAndDeleteKeys menggunakan SCAN untuk mencari dan menghapus key sesuai pattern
 func (s *ItemService) scanAndDeleteKeys(ctx context.Context, pattern string) error {
-	iter := s.cache.Client().Scan(ctx, 0, pattern, 0).Iterator()
+	return deleteKeysMatching(ctx, s.cache, pattern)
+}
+
+func deleteKeysMatching(ctx context.Context, redisCache *cache.RedisCache, pattern string) error {
+	iter := redisCache.Client().Scan(ctx, 0, pattern, 0).Iterator()
 
 	var keysToDelete []string
 	for iter.Next(ctx) {
@@ -228,7 +759,7 @@ func (s *ItemService) scanAndDeleteKeys(ctx context.Context, pattern string) err
 	}
 
 	if len(keysToDelete) > 0 {
-		err := s.cache.Client().Del(ctx, keysToDelete...).Err()
+		err := redisCache.Client().Del(ctx, keysToDelete...).Err()
 		if err != nil {
 			return fmt.Errorf("delete error: %w", err)
 		}
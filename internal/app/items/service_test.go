@@ -0,0 +1,51 @@
+package items
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCheckOptionIDs(t *testing.T) {
+	groupID, optionID, otherID := uuid.New(), uuid.New(), uuid.New()
+	existing := optionIDs{
+		groups:  map[uuid.UUID]bool{groupID: true},
+		options: map[uuid.UUID]bool{optionID: true},
+	}
+	group := func(id string, optionIDs ...string) OptionGroupRequest {
+		g := OptionGroupRequest{GroupID: id}
+		for _, o := range optionIDs {
+			g.Options = append(g.Options, OptionRequest{OptionID: o})
+		}
+		return g
+	}
+
+	tests := []struct {
+		name    string
+		groups  []OptionGroupRequest
+		wantErr error
+	}{
+		{"all new", []OptionGroupRequest{group("", "", "")}, nil},
+		{"existing kept", []OptionGroupRequest{group(groupID.String(), optionID.String(), "")}, nil},
+		{"option moved to a new group", []OptionGroupRequest{group(""), group("", optionID.String())}, nil},
+		{"unknown group", []OptionGroupRequest{group(otherID.String(), "")}, ErrUnknownOption},
+		{"unknown option", []OptionGroupRequest{group("", otherID.String())}, ErrUnknownOption},
+		{"option id used as group id", []OptionGroupRequest{group(optionID.String(), "")}, ErrUnknownOption},
+		{"option sent twice", []OptionGroupRequest{group("", optionID.String()), group("", optionID.String())}, ErrUnknownOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkOptionIDs(tt.groups, existing); !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkOptionIDs() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("new item", func(t *testing.T) {
+		if err := checkOptionIDs([]OptionGroupRequest{group("", optionID.String())}, optionIDs{}); !errors.Is(err, ErrUnknownOption) {
+			t.Errorf("checkOptionIDs() = %v, want %v", err, ErrUnknownOption)
+		}
+	})
+}
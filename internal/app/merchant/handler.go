@@ -1,11 +1,15 @@
 package merchant
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
 	logger "belimang/internal/pkg/logging"
+	"belimang/internal/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -20,6 +24,8 @@ type MerchantHandler struct {
 func NewMerchantHandler(service *MerchantService, validate *validator.Validate) *MerchantHandler {
 	validate.RegisterValidation("merchantCategory", MerchantCategoryValidator)
 	validate.RegisterValidation("urlSuffix", imageURLValidator)
+	validate.RegisterValidation("h3Cell", h3CellValidator)
+	validate.RegisterValidation("clockTime", clockTimeValidator)
 
 	return &MerchantHandler{
 		service:  service,
@@ -65,6 +71,12 @@ func (h *MerchantHandler) CreateMerchantHandler(c *gin.Context) {
 }
 
 func (h *MerchantHandler) SearchMerchantsHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
 	filter := MerchantFilter{
 		MerchantID:       c.Query("merchantId"),
 		Name:             c.Query("name"),
@@ -93,7 +105,7 @@ func (h *MerchantHandler) SearchMerchantsHandler(c *gin.Context) {
 
 	// Validate merchantCategory
 	if filter.MerchantCategory != "" {
-		if _, ok := validMerchantCategories[filter.MerchantCategory]; !ok {
+		if !utils.IsMerchantCategory(filter.MerchantCategory) {
 			c.JSON(http.StatusOK, GetMerchantsResponse{Data: []Merchant{}, Meta: Meta{Limit: filter.Limit, Offset: filter.Offset, Total: 0}})
 			return
 		}
@@ -103,21 +115,419 @@ func (h *MerchantHandler) SearchMerchantsHandler(c *gin.Context) {
 		filter.CreatedAtSort = "desc"
 	}
 
-	resp, err := h.service.SearchMerchantsService(c, filter)
+	resp, err := h.service.SearchMerchantsService(c, adminID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MerchantHandler) GetMerchantHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse("not found", ErrMerchantNotFound.Error()))
+		return
+	}
+
+	resp, err := h.service.GetMerchantService(c, adminID, merchantID)
+	if err != nil {
+		if errors.Is(err, ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, NewErrorResponse("not found", err.Error()))
+			return
+		}
+		if errors.Is(err, ErrForbidden) {
+			c.JSON(http.StatusForbidden, NewErrorResponse("forbidden", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MerchantHandler) UpdateMerchantHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse("not found", ErrMerchantNotFound.Error()))
+		return
+	}
+
+	var req PatchMerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("validation error", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		var validationErrors []ValidationError
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, ValidationError{
+				Field:   err.Field(),
+				Message: getValidationMessage(err),
+				Value:   getFieldValue(err),
+			})
+		}
+		c.JSON(http.StatusBadRequest, NewValidationErrorResponse("Validation failed", validationErrors))
+		return
+	}
+
+	resp, err := h.service.UpdateMerchantService(c, adminID, merchantID, req)
+	if err != nil {
+		if errors.Is(err, ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, NewErrorResponse("not found", err.Error()))
+			return
+		}
+		if errors.Is(err, ErrForbidden) {
+			c.JSON(http.StatusForbidden, NewErrorResponse("forbidden", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MerchantHandler) DeleteMerchantHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse("not found", ErrMerchantNotFound.Error()))
+		return
+	}
+
+	if err := h.service.DeleteMerchantService(c, adminID, merchantID); err != nil {
+		switch {
+		case errors.Is(err, ErrMerchantNotFound):
+			c.JSON(http.StatusNotFound, NewErrorResponse("not found", err.Error()))
+		case errors.Is(err, ErrForbidden):
+			c.JSON(http.StatusForbidden, NewErrorResponse("forbidden", err.Error()))
+		case errors.Is(err, ErrMerchantInUse):
+			c.JSON(http.StatusConflict, NewErrorResponse("conflict", err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *MerchantHandler) GetDeliveryAreaHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse("not found", ErrMerchantNotFound.Error()))
+		return
+	}
+
+	resp, err := h.service.GetDeliveryAreaService(c, adminID, merchantID)
+	if err != nil {
+		if errors.Is(err, ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, NewErrorResponse("not found", err.Error()))
+			return
+		}
+		if errors.Is(err, ErrForbidden) {
+			c.JSON(http.StatusForbidden, NewErrorResponse("forbidden", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MerchantHandler) UpdateDeliveryAreaHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse("not found", ErrMerchantNotFound.Error()))
+		return
+	}
+
+	var req DeliveryAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("validation error", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		var validationErrors []ValidationError
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, ValidationError{
+				Field:   err.Field(),
+				Message: getValidationMessage(err),
+				Value:   getFieldValue(err),
+			})
+		}
+		c.JSON(http.StatusBadRequest, NewValidationErrorResponse("Validation failed", validationErrors))
+		return
+	}
+
+	resp, err := h.service.UpdateDeliveryAreaService(c, adminID, merchantID, req)
+	if err != nil {
+		if errors.Is(err, ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, NewErrorResponse("not found", err.Error()))
+			return
+		}
+		if errors.Is(err, ErrForbidden) {
+			c.JSON(http.StatusForbidden, NewErrorResponse("forbidden", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MerchantHandler) GetOpeningHoursHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse("not found", ErrMerchantNotFound.Error()))
+		return
+	}
+
+	resp, err := h.service.GetOpeningHoursService(c, adminID, merchantID)
+	if err != nil {
+		if errors.Is(err, ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, NewErrorResponse("not found", err.Error()))
+			return
+		}
+		if errors.Is(err, ErrForbidden) {
+			c.JSON(http.StatusForbidden, NewErrorResponse("forbidden", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MerchantHandler) UpdateOpeningHoursHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
+	merchantID, err := uuid.Parse(c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse("not found", ErrMerchantNotFound.Error()))
+		return
+	}
+
+	var req OpeningHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("validation error", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		var validationErrors []ValidationError
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, ValidationError{
+				Field:   err.Field(),
+				Message: getValidationMessage(err),
+				Value:   getFieldValue(err),
+			})
+		}
+		c.JSON(http.StatusBadRequest, NewValidationErrorResponse("Validation failed", validationErrors))
+		return
+	}
+
+	resp, err := h.service.UpdateOpeningHoursService(c, adminID, merchantID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMerchantNotFound):
+			c.JSON(http.StatusNotFound, NewErrorResponse("not found", err.Error()))
+		case errors.Is(err, ErrForbidden):
+			c.JSON(http.StatusForbidden, NewErrorResponse("forbidden", err.Error()))
+		case errors.Is(err, ErrInvalidHours):
+			c.JSON(http.StatusBadRequest, NewErrorResponse("validation error", err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateImportHandler queues a CSV file of merchants for the background
+// import. The file is sent as multipart field "file" or as a text/csv body.
+// Rows are validated right away; duplicates are only known once the import ran.
+func (h *MerchantHandler) CreateImportHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
+	opts := ImportOptions{DuplicateRadiusMeters: defaultDuplicateRadiusMeters}
+	if v := c.Query("duplicateRadiusInMeters"); v != "" {
+		radius, err := strconv.Atoi(v)
+		if err != nil || radius < 1 || radius > maxDuplicateRadiusMeters {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("validation error",
+				"duplicateRadiusInMeters must be between 1 and "+strconv.Itoa(maxDuplicateRadiusMeters)))
+			return
+		}
+		opts.DuplicateRadiusMeters = radius
+	}
+	if v := c.Query("importDuplicates"); v != "" {
+		opts.ImportDuplicates, err = strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("validation error", "importDuplicates must be true or false"))
+			return
+		}
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+
+	var (
+		body     []byte
+		fileName string
+	)
+	switch c.ContentType() {
+	case "multipart/form-data":
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("validation error", "file is required"))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("validation error", "failed to read file"))
+			return
+		}
+		defer file.Close()
+		if body, err = io.ReadAll(file); err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("validation error", "failed to read file"))
+			return
+		}
+		fileName = fileHeader.Filename
+	case "text/csv":
+		if body, err = io.ReadAll(c.Request.Body); err != nil {
+			c.JSON(http.StatusRequestEntityTooLarge, NewErrorResponse("validation error", "import file too large"))
+			return
+		}
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, NewErrorResponse("validation error", "expected a CSV file"))
+		return
+	}
+
+	rows, err := parseMerchantsCSV(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("validation error", err.Error()))
+		return
+	}
+	for i := range rows {
+		validateImportRow(h.validate, &rows[i])
+	}
+
+	resp, err := h.service.CreateImportService(c, adminID, fileName, rows, opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
 		return
 	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+func (h *MerchantHandler) GetImportHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
+	importID, err := uuid.Parse(c.Param("importId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse("not found", ErrImportNotFound.Error()))
+		return
+	}
+
+	resp, err := h.service.GetImportService(c, adminID, importID)
+	if err != nil {
+		if errors.Is(err, ErrImportNotFound) {
+			c.JSON(http.StatusNotFound, NewErrorResponse("not found", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		return
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
 
-var validMerchantCategories = map[string]struct{}{
-	"SmallRestaurant":       {},
-	"MediumRestaurant":      {},
-	"LargeRestaurant":       {},
-	"MerchandiseRestaurant": {},
-	"BoothKiosk":            {},
-	"ConvenienceStore":      {},
+// GetImportReportHandler downloads the per-row outcome of an import as CSV
+func (h *MerchantHandler) GetImportReportHandler(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("unathorized error", err.Error()))
+		return
+	}
+
+	importID, err := uuid.Parse(c.Param("importId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewErrorResponse("not found", ErrImportNotFound.Error()))
+		return
+	}
+
+	rows, err := h.service.ImportReportService(c, adminID, importID)
+	if err != nil {
+		if errors.Is(err, ErrImportNotFound) {
+			c.JSON(http.StatusNotFound, NewErrorResponse("not found", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		return
+	}
+
+	var report bytes.Buffer
+	if err := writeImportReport(&report, rows); err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("internal error", err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="merchant-import-`+importID.String()+`.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", report.Bytes())
 }
 
 func getUserID(c *gin.Context) (uuid.UUID, error) {
@@ -131,8 +541,7 @@ func getUserID(c *gin.Context) (uuid.UUID, error) {
 }
 
 func MerchantCategoryValidator(fl validator.FieldLevel) bool {
-	_, exists := validMerchantCategories[fl.Field().String()]
-	return exists
+	return utils.IsMerchantCategory(fl.Field().String())
 }
 
 func imageURLValidator(fl validator.FieldLevel) bool {
@@ -140,6 +549,16 @@ func imageURLValidator(fl validator.FieldLevel) bool {
 	return strings.HasSuffix(url, ".jpg") || strings.HasSuffix(url, ".jpeg")
 }
 
+func h3CellValidator(fl validator.FieldLevel) bool {
+	_, err := utils.ParseZoneCell(fl.Field().String())
+	return err == nil
+}
+
+func clockTimeValidator(fl validator.FieldLevel) bool {
+	_, err := utils.ParseClock(fl.Field().String())
+	return err == nil
+}
+
 // getValidationMessage returns a human-readable validation message
 func getValidationMessage(err validator.FieldError) string {
 	switch err.Tag() {
@@ -155,6 +574,20 @@ func getValidationMessage(err validator.FieldError) string {
 		return "Latitude must be between -90 and 90"
 	case "longitude":
 		return "Longitude must be between -180 and 180"
+	case "merchantCategory":
+		return "Must be a valid merchant category"
+	case "urlSuffix":
+		return "Must end with .jpg or .jpeg"
+	case "h3Cell":
+		return "Must be a valid H3 cell with resolution at most 10"
+	case "clockTime":
+		return "Must be a time in HH:MM format"
+	case "timezone":
+		return "Must be an IANA timezone such as Asia/Jakarta"
+	case "datetime":
+		return "Must be a date in " + err.Param() + " format"
+	case "required_unless":
+		return "This field is required unless the merchant is closed"
 	default:
 		return "Invalid value"
 	}
@@ -0,0 +1,329 @@
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	maxImportRows      = 10000            // rows per import file
+	maxImportBodyBytes = 10 * 1024 * 1024 // size of the uploaded file
+
+	defaultDuplicateRadiusMeters = 50
+	maxDuplicateRadiusMeters     = 1000
+)
+
+// importRow is one parsed row of an import file. Errors holds the problems
+// found while parsing; validation adds to it later.
+type importRow struct {
+	Row      int
+	Merchant PostMerchantRequest
+	Errors   []string
+}
+
+// ImportOptions controls how the rows of an import are created
+type ImportOptions struct {
+	DuplicateRadiusMeters int
+	ImportDuplicates      bool // create likely duplicates anyway, they are only flagged
+}
+
+// csvImportColumns are the accepted CSV header names
+var csvImportColumns = map[string]bool{
+	"name":                     true,
+	"merchantCategory":         true,
+	"imageUrl":                 true,
+	"lat":                      true,
+	"long":                     true,
+	"deliveryRadiusInMeters":   true,
+	"preparationTimeInMinutes": true,
+}
+
+// parseMerchantsCSV reads a CSV file whose first line names the columns
+func parseMerchantsCSV(body []byte) ([]importRow, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing CSV header", ErrInvalidImport)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// file CSV dari Excel sering diawali BOM
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !csvImportColumns[name] {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidImport, name)
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"name", "merchantCategory", "imageUrl", "lat", "long"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidImport, required)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("%w: at most %d rows per import", ErrInvalidImport, maxImportRows)
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row := importRow{Row: len(rows) + 1}
+		row.Merchant.Name = value("name")
+		row.Merchant.MerchantCategory = value("merchantCategory")
+		row.Merchant.ImageURL = value("imageUrl")
+
+		if v := value("lat"); v != "" {
+			lat, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				row.Errors = append(row.Errors, "Latitude must be a number")
+			}
+			row.Merchant.Location.Latitude = lat
+		}
+		if v := value("long"); v != "" {
+			lng, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				row.Errors = append(row.Errors, "Longitude must be a number")
+			}
+			row.Merchant.Location.Longitude = lng
+		}
+		if v := value("deliveryRadiusInMeters"); v != "" {
+			radius, err := strconv.Atoi(v)
+			if err != nil {
+				row.Errors = append(row.Errors, "DeliveryRadiusInMeters must be a whole number")
+			}
+			row.Merchant.DeliveryRadiusInMeters = radius
+		}
+		if v := value("preparationTimeInMinutes"); v != "" {
+			minutes, err := strconv.Atoi(v)
+			if err != nil {
+				row.Errors = append(row.Errors, "PreparationTimeInMinutes must be a whole number")
+			} else {
+				row.Merchant.PreparationTimeInMinutes = &minutes
+			}
+		}
+
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file has no rows", ErrInvalidImport)
+	}
+	return rows, nil
+}
+
+// validateImportRow applies the PostMerchantRequest rules to a parsed row
+func validateImportRow(validate *validator.Validate, row *importRow) {
+	if len(row.Errors) > 0 {
+		return
+	}
+	if err := validate.Struct(&row.Merchant); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			row.Errors = append(row.Errors, "validation failed")
+			return
+		}
+		for _, fe := range validationErrors {
+			row.Errors = append(row.Errors, fe.Field()+": "+getValidationMessage(fe))
+		}
+	}
+}
+
+// importReportHeader are the columns of the downloadable import report
+var importReportHeader = []string{
+	"row", "name", "merchantCategory", "status", "merchantId", "duplicateOf", "duplicateDistanceInMeters", "errors",
+}
+
+// writeImportReport writes one CSV line per import row
+func writeImportReport(w io.Writer, rows []database.ListMerchantImportRowsRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(importReportHeader); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		distance := ""
+		if row.DuplicateDistanceMeters != nil {
+			distance = strconv.FormatFloat(*row.DuplicateDistanceMeters, 'f', 0, 64)
+		}
+		record := []string{
+			strconv.Itoa(row.RowNumber),
+			row.Name,
+			row.MerchantCategory,
+			string(row.Status),
+			uuidOrEmpty(row.MerchantID),
+			uuidOrEmpty(row.DuplicateOf),
+			distance,
+			row.Errors,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func uuidOrEmpty(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+// CreateImportService stores the rows of an import file for the background
+// worker. Invalid rows are kept too, so they show up in the report.
+func (s *MerchantService) CreateImportService(ctx context.Context, adminID uuid.UUID, fileName string, rows []importRow, opts ImportOptions) (MerchantImportResponse, error) {
+	logger.InfoCtx(ctx, "Create merchant import process", "adminId", adminID, "fileName", fileName, "rows", len(rows))
+
+	params := database.AddMerchantImportRowsParams{
+		RowNumbers:         make([]int, len(rows)),
+		Names:              make([]string, len(rows)),
+		MerchantCategories: make([]string, len(rows)),
+		ImageUrls:          make([]string, len(rows)),
+		Lats:               make([]float64, len(rows)),
+		Lngs:               make([]float64, len(rows)),
+		DeliveryRadii:      make([]int, len(rows)),
+		PreparationTimes:   make([]int, len(rows)),
+		Statuses:           make([]string, len(rows)),
+		Errors:             make([]string, len(rows)),
+	}
+	for i, row := range rows {
+		params.RowNumbers[i] = row.Row
+		params.Names[i] = row.Merchant.Name
+		params.MerchantCategories[i] = row.Merchant.MerchantCategory
+		params.ImageUrls[i] = row.Merchant.ImageURL
+		params.Lats[i] = row.Merchant.Location.Latitude
+		params.Lngs[i] = row.Merchant.Location.Longitude
+		params.DeliveryRadii[i] = row.Merchant.DeliveryRadiusInMeters
+		params.PreparationTimes[i] = -1
+		if row.Merchant.PreparationTimeInMinutes != nil {
+			params.PreparationTimes[i] = *row.Merchant.PreparationTimeInMinutes
+		}
+		params.Statuses[i] = string(database.MerchantImportRowStatusPending)
+		if len(row.Errors) > 0 {
+			params.Statuses[i] = string(database.MerchantImportRowStatusInvalid)
+			params.Errors[i] = strings.Join(row.Errors, "; ")
+		}
+	}
+
+	var importID uuid.UUID
+	err := s.store.WithTx(ctx, func(q *database.Queries) error {
+		var err error
+		importID, err = q.CreateMerchantImport(ctx, database.CreateMerchantImportParams{
+			AdminID:               adminID,
+			FileName:              fileName,
+			DuplicateRadiusMeters: opts.DuplicateRadiusMeters,
+			ImportDuplicates:      opts.ImportDuplicates,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create merchant import: %w", err)
+		}
+		params.ImportID = importID
+		if err := q.AddMerchantImportRows(ctx, params); err != nil {
+			return fmt.Errorf("failed to store merchant import rows: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to create merchant import", "error", err)
+		return MerchantImportResponse{}, err
+	}
+
+	logger.InfoCtx(ctx, "Merchant import queued", "importId", importID)
+	return s.GetImportService(ctx, adminID, importID)
+}
+
+// GetImportService returns the progress of an import started by adminID
+func (s *MerchantService) GetImportService(ctx context.Context, adminID, importID uuid.UUID) (MerchantImportResponse, error) {
+	row, err := s.db.GetMerchantImport(ctx, database.GetMerchantImportParams{
+		ImportID: importID,
+		AdminID:  adminID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return MerchantImportResponse{}, ErrImportNotFound
+		}
+		logger.ErrorCtx(ctx, "Failed to get merchant import", "importId", importID, "error", err)
+		return MerchantImportResponse{}, err
+	}
+
+	resp := MerchantImportResponse{
+		ImportID:                row.ID.String(),
+		FileName:                row.FileName,
+		Status:                  string(row.Status),
+		DuplicateRadiusInMeters: row.DuplicateRadiusMeters,
+		ImportDuplicates:        row.ImportDuplicates,
+		Error:                   row.Error,
+		Rows: MerchantImportCounts{
+			Total:     int(row.TotalRows),
+			Pending:   int(row.PendingRows),
+			Imported:  int(row.ImportedRows),
+			Duplicate: int(row.DuplicateRows),
+			Invalid:   int(row.InvalidRows),
+			Failed:    int(row.FailedRows),
+		},
+		ReportURL: fmt.Sprintf("/admin/merchants/imports/%s/report", row.ID),
+		CreatedAt: row.CreatedAt.Format(time.RFC3339Nano),
+	}
+	if row.StartedAt != nil {
+		startedAt := row.StartedAt.Format(time.RFC3339Nano)
+		resp.StartedAt = &startedAt
+	}
+	if row.FinishedAt != nil {
+		finishedAt := row.FinishedAt.Format(time.RFC3339Nano)
+		resp.FinishedAt = &finishedAt
+	}
+	return resp, nil
+}
+
+// ImportReportService returns the outcome of every row of an import started
+// by adminID. Rows the worker has not reached yet are still pending.
+func (s *MerchantService) ImportReportService(ctx context.Context, adminID, importID uuid.UUID) ([]database.ListMerchantImportRowsRow, error) {
+	_, err := s.db.GetMerchantImport(ctx, database.GetMerchantImportParams{
+		ImportID: importID,
+		AdminID:  adminID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrImportNotFound
+		}
+		logger.ErrorCtx(ctx, "Failed to get merchant import", "importId", importID, "error", err)
+		return nil, err
+	}
+
+	rows, err := s.db.ListMerchantImportRows(ctx, importID)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to list merchant import rows", "importId", importID, "error", err)
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,147 @@
+package merchant
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"belimang/internal/infrastructure/database"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const csvHeader = "name,merchantCategory,imageUrl,lat,long,deliveryRadiusInMeters,preparationTimeInMinutes\n"
+
+func TestParseMerchantsCSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    error
+		wantErrors [][]string // per row
+	}{
+		{"empty file", "", ErrInvalidImport, nil},
+		{"unknown column", "name,merchantCategory,imageUrl,lat,long,rating\n", ErrInvalidImport, nil},
+		{"missing required column", "name,merchantCategory,imageUrl,lat\n", ErrInvalidImport, nil},
+		{"header only", csvHeader, ErrInvalidImport, nil},
+		{"header with BOM", "\ufeff" + csvHeader + "Warung Bu Sri,SmallRestaurant,https://img.example.com/a.jpg,-6.2,106.8,,\n", nil, [][]string{nil}},
+		{"columns in any order", "long,lat,imageUrl,merchantCategory,name\n106.8,-6.2,https://img.example.com/a.jpg,SmallRestaurant,Warung Bu Sri\n", nil, [][]string{nil}},
+		{
+			"bad numbers",
+			csvHeader + "Warung Bu Sri,SmallRestaurant,https://img.example.com/a.jpg,selatan,timur,jauh,lama\n",
+			nil,
+			[][]string{{
+				"Latitude must be a number",
+				"Longitude must be a number",
+				"DeliveryRadiusInMeters must be a whole number",
+				"PreparationTimeInMinutes must be a whole number",
+			}},
+		},
+		{"short record", csvHeader + "Warung Bu Sri,SmallRestaurant\n", nil, [][]string{nil}},
+		{"malformed quote", csvHeader + "\"Warung Bu Sri,SmallRestaurant,https://img.example.com/a.jpg,-6.2,106.8\n", ErrInvalidImport, nil},
+		{"too many rows", csvHeader + strings.Repeat("Warung Bu Sri,SmallRestaurant,https://img.example.com/a.jpg,-6.2,106.8,,\n", maxImportRows+1), ErrInvalidImport, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseMerchantsCSV([]byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseMerchantsCSV() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(rows) != len(tt.wantErrors) {
+				t.Fatalf("parseMerchantsCSV() returned %d rows, want %d", len(rows), len(tt.wantErrors))
+			}
+			for i, row := range rows {
+				if row.Row != i+1 {
+					t.Errorf("row %d numbered %d", i+1, row.Row)
+				}
+				if !reflect.DeepEqual(row.Errors, tt.wantErrors[i]) {
+					t.Errorf("row %d errors = %q, want %q", i+1, row.Errors, tt.wantErrors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseMerchantsCSVFields(t *testing.T) {
+	rows, err := parseMerchantsCSV([]byte(csvHeader + " Warung Bu Sri , SmallRestaurant,https://img.example.com/a.jpg, -6.2 ,106.8,500,0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rows[0].Merchant
+	if m.Name != "Warung Bu Sri" || m.MerchantCategory != "SmallRestaurant" || m.ImageURL != "https://img.example.com/a.jpg" {
+		t.Errorf("merchant = %+v", m)
+	}
+	if m.Location.Latitude != -6.2 || m.Location.Longitude != 106.8 || m.DeliveryRadiusInMeters != 500 {
+		t.Errorf("merchant = %+v", m)
+	}
+	// 0 menit berbeda dengan kolom kosong yang berarti default kategori
+	if m.PreparationTimeInMinutes == nil || *m.PreparationTimeInMinutes != 0 {
+		t.Errorf("PreparationTimeInMinutes = %v, want 0", m.PreparationTimeInMinutes)
+	}
+}
+
+func validImportMerchant() PostMerchantRequest {
+	return PostMerchantRequest{
+		Name:             "Warung Bu Sri",
+		MerchantCategory: "SmallRestaurant",
+		ImageURL:         "https://img.example.com/a.jpg",
+		Location:         Location{Latitude: -6.2, Longitude: 106.8},
+	}
+}
+
+func TestValidateImportRow(t *testing.T) {
+	minutes := 300
+	tests := []struct {
+		name       string
+		edit       func(*PostMerchantRequest)
+		parseErrs  []string
+		wantErrors []string
+	}{
+		{"valid", func(*PostMerchantRequest) {}, nil, nil},
+		{"parse errors are kept as is", func(m *PostMerchantRequest) { m.Name = "" }, []string{"Latitude must be a number"}, []string{"Latitude must be a number"}},
+		{"missing name", func(m *PostMerchantRequest) { m.Name = "" }, nil, []string{"Name: This field is required"}},
+		{"unknown category", func(m *PostMerchantRequest) { m.MerchantCategory = "FoodCourt" }, nil, []string{"MerchantCategory: Must be a valid merchant category"}},
+		{"image not a jpg", func(m *PostMerchantRequest) { m.ImageURL = "https://img.example.com/a.png" }, nil, []string{"ImageURL: Must end with .jpg or .jpeg"}},
+		{"latitude out of range", func(m *PostMerchantRequest) { m.Location.Latitude = 91 }, nil, []string{"Latitude: Latitude must be between -90 and 90"}},
+		{"delivery radius too small", func(m *PostMerchantRequest) { m.DeliveryRadiusInMeters = 50 }, nil, []string{"DeliveryRadiusInMeters: Value is too short (minimum 100 characters)"}},
+		{"preparation too long", func(m *PostMerchantRequest) { m.PreparationTimeInMinutes = &minutes }, nil, []string{"PreparationTimeInMinutes: Value is too long (maximum 240 characters)"}},
+	}
+
+	validate := NewMerchantHandler(nil, validator.New()).validate
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := importRow{Row: 1, Merchant: validImportMerchant(), Errors: tt.parseErrs}
+			tt.edit(&row.Merchant)
+			validateImportRow(validate, &row)
+			if !reflect.DeepEqual(row.Errors, tt.wantErrors) {
+				t.Errorf("errors = %q, want %q", row.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestWriteImportReport(t *testing.T) {
+	merchantID := uuid.MustParse("0190a6c2-7d1e-7a3b-9c4d-5e6f7a8b9c0d")
+	duplicateOf := uuid.MustParse("0190a6c2-7d1e-7a3b-9c4d-5e6f7a8b9c0e")
+	distance := 12.4
+
+	rows := []database.ListMerchantImportRowsRow{
+		{RowNumber: 1, Name: "Warung Bu Sri", MerchantCategory: "SmallRestaurant", Status: database.MerchantImportRowStatusImported, MerchantID: merchantID},
+		{RowNumber: 2, Name: "Warung, Bu Sri", MerchantCategory: "SmallRestaurant", Status: database.MerchantImportRowStatusDuplicate, DuplicateOf: duplicateOf, DuplicateDistanceMeters: &distance},
+		{RowNumber: 3, Name: "", MerchantCategory: "FoodCourt", Status: database.MerchantImportRowStatusInvalid, Errors: "Name: This field is required"},
+	}
+
+	var report strings.Builder
+	if err := writeImportReport(&report, rows); err != nil {
+		t.Fatalf("writeImportReport() error = %v", err)
+	}
+	want := "row,name,merchantCategory,status,merchantId,duplicateOf,duplicateDistanceInMeters,errors\n" +
+		"1,Warung Bu Sri,SmallRestaurant,imported," + merchantID.String() + ",,,\n" +
+		"2,\"Warung, Bu Sri\",SmallRestaurant,duplicate,," + duplicateOf.String() + ",12,\n" +
+		"3,,FoodCourt,invalid,,,,Name: This field is required\n"
+	if report.String() != want {
+		t.Errorf("report =\n%s\nwant\n%s", report.String(), want)
+	}
+}
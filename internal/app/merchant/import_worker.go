@@ -0,0 +1,191 @@
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"belimang/internal/config"
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+	"belimang/internal/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// baris import yang diproses sebelum progress job diperbarui
+const importBatchSize = 100
+
+// ImportWorker creates the merchants of uploaded import files in the
+// background. Rows are handled one by one in file order, so a row is also
+// checked against the merchants created from earlier rows of the same file.
+// A worker that dies mid-import leaves the job running without progress;
+// another worker takes it over after StaleAfter and continues where it stopped.
+type ImportWorker struct {
+	store      *database.DB
+	interval   time.Duration
+	staleAfter time.Duration
+}
+
+func NewImportWorker(store *database.DB, cfg config.MerchantImportConfig) *ImportWorker {
+	return &ImportWorker{
+		store:      store,
+		interval:   cfg.Interval,
+		staleAfter: cfg.StaleAfter,
+	}
+}
+
+// Run processes waiting imports every interval until ctx is cancelled
+func (w *ImportWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	logger.InfoCtx(ctx, "Merchant import worker started", "interval", w.interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.ProcessWaiting(ctx); err != nil {
+				logger.ErrorCtx(ctx, "Merchant import failed", "error", err)
+			}
+		}
+	}
+}
+
+// ProcessWaiting processes imports until none is waiting
+func (w *ImportWorker) ProcessWaiting(ctx context.Context) error {
+	for {
+		job, err := w.store.Queries.ClaimMerchantImport(ctx, time.Now().Add(-w.staleAfter))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to claim merchant import: %w", err)
+		}
+		logger.InfoCtx(ctx, "Merchant import started", "importId", job.ID)
+
+		status, message := database.MerchantImportStatusCompleted, ""
+		if err := w.process(ctx, job); err != nil {
+			if ctx.Err() != nil {
+				// dihentikan saat shutdown, dilanjutkan setelah StaleAfter
+				return ctx.Err()
+			}
+			logger.ErrorCtx(ctx, "Merchant import stopped", "importId", job.ID, "error", err)
+			status, message = database.MerchantImportStatusFailed, err.Error()
+		}
+
+		err = w.store.Queries.FinishMerchantImport(ctx, database.FinishMerchantImportParams{
+			Status:   status,
+			Error:    message,
+			ImportID: job.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to finish merchant import %s: %w", job.ID, err)
+		}
+		logger.InfoCtx(ctx, "Merchant import finished", "importId", job.ID, "status", status)
+	}
+}
+
+// process handles the pending rows of job batch by batch
+func (w *ImportWorker) process(ctx context.Context, job database.ClaimMerchantImportRow) error {
+	for {
+		rows, err := w.store.Queries.ListPendingMerchantImportRows(ctx, database.ListPendingMerchantImportRowsParams{
+			ImportID:   job.ID,
+			LimitCount: importBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list merchant import rows: %w", err)
+		}
+
+		for _, row := range rows {
+			if err := w.importRow(ctx, job, row); err != nil {
+				return err
+			}
+		}
+		if len(rows) < importBatchSize {
+			return nil
+		}
+
+		if err := w.store.Queries.TouchMerchantImport(ctx, job.ID); err != nil {
+			return fmt.Errorf("failed to update merchant import progress: %w", err)
+		}
+	}
+}
+
+// errRowProcessed is returned inside the row's transaction when another
+// worker already processed the row, rolling back the merchant it created
+var errRowProcessed = errors.New("merchant import row already processed")
+
+// importRow creates the merchant of one row, unless a merchant with the same
+// name lies within the job's duplicate radius. A row another worker already
+// processed, e.g. after taking over the job, is skipped.
+func (w *ImportWorker) importRow(ctx context.Context, job database.ClaimMerchantImportRow, row database.ListPendingMerchantImportRowsRow) error {
+	update := database.UpdateMerchantImportRowParams{
+		ImportID:  job.ID,
+		RowNumber: row.RowNumber,
+	}
+
+	duplicate, err := w.store.Queries.FindDuplicateMerchant(ctx, database.FindDuplicateMerchantParams{
+		Lat:          row.Lat,
+		Lng:          row.Lng,
+		Rings:        utils.H3SearchRings(float64(job.DuplicateRadiusMeters)),
+		Name:         row.Name,
+		RadiusMeters: float64(job.DuplicateRadiusMeters),
+	})
+	switch {
+	case err == nil:
+		update.DuplicateOf = duplicate.ID
+		update.DuplicateDistanceMeters = &duplicate.DistanceMeters
+	case !errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("failed to check duplicate merchant for row %d: %w", row.RowNumber, err)
+	}
+
+	if update.DuplicateOf != uuid.Nil && !job.ImportDuplicates {
+		update.Status = database.MerchantImportRowStatusDuplicate
+		if _, err := w.store.Queries.UpdateMerchantImportRow(ctx, update); err != nil {
+			return fmt.Errorf("failed to update merchant import row %d: %w", row.RowNumber, err)
+		}
+		return nil
+	}
+
+	req := PostMerchantRequest{
+		Name:                     row.Name,
+		MerchantCategory:         row.MerchantCategory,
+		ImageURL:                 row.ImageUrl,
+		Location:                 Location{Latitude: row.Lat, Longitude: row.Lng},
+		DeliveryRadiusInMeters:   row.DeliveryRadiusMeters,
+		PreparationTimeInMinutes: row.PreparationTimeMinutes,
+	}
+	err = w.store.WithTx(ctx, func(q *database.Queries) error {
+		created, err := q.CreateMerchant(ctx, newCreateMerchantParams(job.AdminID, req))
+		if err != nil {
+			return err
+		}
+		update.Status = database.MerchantImportRowStatusImported
+		update.MerchantID = created.ID
+		updated, err := q.UpdateMerchantImportRow(ctx, update)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return errRowProcessed
+		}
+		return nil
+	})
+	if err == nil || errors.Is(err, errRowProcessed) {
+		return nil
+	}
+
+	// hanya baris ini yang gagal, baris berikutnya tetap diproses
+	logger.WarnCtx(ctx, "Failed to import merchant row", "importId", job.ID, "row", row.RowNumber, "error", err)
+	update.Status = database.MerchantImportRowStatusFailed
+	update.MerchantID = uuid.Nil
+	update.Errors = "failed to create merchant"
+	if _, err := w.store.Queries.UpdateMerchantImportRow(ctx, update); err != nil {
+		return fmt.Errorf("failed to update merchant import row %d: %w", row.RowNumber, err)
+	}
+	return nil
+}
@@ -11,16 +11,115 @@ type Location struct {
 }
 
 type PostMerchantRequest struct {
-	Name             string   `json:"name" validate:"required,min=2,max=30"`
-	MerchantCategory string   `json:"merchantCategory" validate:"required,merchantCategory"`
-	ImageURL         string   `json:"imageUrl" validate:"required,url,urlSuffix"`
-	Location         Location `json:"location" validate:"required"`
+	Name                   string   `json:"name" validate:"required,min=2,max=30"`
+	MerchantCategory       string   `json:"merchantCategory" validate:"required,merchantCategory"`
+	ImageURL               string   `json:"imageUrl" validate:"required,url,urlSuffix"`
+	Location               Location `json:"location" validate:"required"`
+	DeliveryRadiusInMeters int      `json:"deliveryRadiusInMeters" validate:"omitempty,min=100,max=20000"`
+	// nil berarti pakai default sesuai merchantCategory
+	PreparationTimeInMinutes *int `json:"preparationTimeInMinutes" validate:"omitempty,min=0,max=240"`
 }
 
 type PostMerchantResponse struct {
 	MerchantID string `json:"merchantId"`
 }
 
+// PatchMerchantRequest updates only the fields that are present. A new
+// location must carry both lat and long.
+type PatchMerchantRequest struct {
+	Name                     *string   `json:"name" validate:"omitempty,min=2,max=30"`
+	MerchantCategory         *string   `json:"merchantCategory" validate:"omitempty,merchantCategory"`
+	ImageURL                 *string   `json:"imageUrl" validate:"omitempty,url,urlSuffix"`
+	Location                 *Location `json:"location" validate:"omitempty"`
+	DeliveryRadiusInMeters   *int      `json:"deliveryRadiusInMeters" validate:"omitempty,min=100,max=20000"`
+	PreparationTimeInMinutes *int      `json:"preparationTimeInMinutes" validate:"omitempty,min=0,max=240"`
+}
+
+type MerchantDetailResponse struct {
+	MerchantID               string   `json:"merchantId"`
+	Name                     string   `json:"name"`
+	MerchantCategory         string   `json:"merchantCategory"`
+	ImageURL                 string   `json:"imageUrl"`
+	Location                 Location `json:"location"`
+	H3Index                  string   `json:"h3Index"`
+	DeliveryRadiusInMeters   int      `json:"deliveryRadiusInMeters"`
+	PreparationTimeInMinutes *int     `json:"preparationTimeInMinutes"`
+	CreatedAt                string   `json:"createdAt"`
+}
+
+// DeliveryAreaRequest replaces a merchant's delivery radius and service zone.
+// An empty ServiceZone removes the zone so only the radius applies.
+type DeliveryAreaRequest struct {
+	DeliveryRadiusInMeters int      `json:"deliveryRadiusInMeters" validate:"required,min=100,max=20000"`
+	ServiceZone            []string `json:"serviceZone" validate:"omitempty,max=1000,dive,h3Cell"`
+}
+
+type DeliveryAreaResponse struct {
+	MerchantID             string   `json:"merchantId"`
+	DeliveryRadiusInMeters int      `json:"deliveryRadiusInMeters"`
+	ServiceZone            []string `json:"serviceZone"`
+}
+
+// OpeningHoursRequest replaces a merchant's timezone, weekly hours and
+// date exceptions. Without weekly hours the merchant is open around the clock.
+type OpeningHoursRequest struct {
+	Timezone   string                  `json:"timezone" validate:"required,timezone"`
+	Weekly     []WeeklyOpeningHours    `json:"weekly" validate:"max=50,dive"`
+	Exceptions []OpeningHoursException `json:"exceptions" validate:"max=366,dive"`
+}
+
+// WeeklyOpeningHours is one opening window on a day of the week. A close
+// time not after the open time runs past midnight into the next day.
+type WeeklyOpeningHours struct {
+	DayOfWeek int    `json:"dayOfWeek" validate:"min=0,max=6"` // 0 = Sunday
+	Open      string `json:"open" validate:"required,clockTime"`
+	Close     string `json:"close" validate:"required,clockTime"`
+}
+
+// OpeningHoursException closes the merchant on a local date (holiday) or
+// replaces that day's weekly hours with a single window
+type OpeningHoursException struct {
+	Date   string `json:"date" validate:"required,datetime=2006-01-02"`
+	Closed bool   `json:"closed"`
+	Open   string `json:"open,omitempty" validate:"required_unless=Closed true,omitempty,clockTime"`
+	Close  string `json:"close,omitempty" validate:"required_unless=Closed true,omitempty,clockTime"`
+	Note   string `json:"note,omitempty" validate:"max=100"`
+}
+
+type OpeningHoursResponse struct {
+	MerchantID string                  `json:"merchantId"`
+	Timezone   string                  `json:"timezone"`
+	Weekly     []WeeklyOpeningHours    `json:"weekly"`
+	Exceptions []OpeningHoursException `json:"exceptions"` // from two days ago onwards
+	IsOpen     bool                    `json:"isOpen"`
+}
+
+// MerchantImportResponse is the progress of a bulk merchant import. Rows are
+// processed in the background; ReportURL serves the per-row outcome as CSV.
+type MerchantImportResponse struct {
+	ImportID                string               `json:"importId"`
+	FileName                string               `json:"fileName"`
+	Status                  string               `json:"status"` // pending, running, completed or failed
+	DuplicateRadiusInMeters int                  `json:"duplicateRadiusInMeters"`
+	ImportDuplicates        bool                 `json:"importDuplicates"`
+	Error                   string               `json:"error,omitempty"`
+	Rows                    MerchantImportCounts `json:"rows"`
+	ReportURL               string               `json:"reportUrl"`
+	CreatedAt               string               `json:"createdAt"`
+	StartedAt               *string              `json:"startedAt"`
+	FinishedAt              *string              `json:"finishedAt"`
+}
+
+// MerchantImportCounts counts the rows of an import by outcome
+type MerchantImportCounts struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Imported  int `json:"imported"`
+	Duplicate int `json:"duplicate"` // skipped, a merchant with the same name is nearby
+	Invalid   int `json:"invalid"`
+	Failed    int `json:"failed"`
+}
+
 // MerchantFilter holds filter params for searching merchants
 type MerchantFilter struct {
 	MerchantID       string
@@ -54,6 +153,12 @@ type Meta struct {
 // Domain errors for merchants operations
 var (
 	ErrUserNotFound     = errors.New("user not found")
+	ErrMerchantNotFound = errors.New("merchant not found")
+	ErrInvalidHours     = errors.New("invalid opening hours")
+	ErrMerchantInUse    = errors.New("merchant still has active orders")
+	ErrForbidden        = errors.New("merchant belongs to another admin")
+	ErrImportNotFound   = errors.New("merchant import not found")
+	ErrInvalidImport    = errors.New("invalid import file")
 	ErrUnauthorized     = errors.New("user is not an admin")
 	ErrInvalidDataType  = errors.New("invalid data type")
 	ErrFailedConversion = errors.New("failed conversion")
@@ -13,5 +13,15 @@ func MerchantRoutes(router *gin.Engine, handler *MerchantHandler, jwtService *jw
 	{
 		merchants.POST("", handler.CreateMerchantHandler)
 		merchants.GET("", handler.SearchMerchantsHandler)
+		merchants.POST("/imports", handler.CreateImportHandler)
+		merchants.GET("/imports/:importId", handler.GetImportHandler)
+		merchants.GET("/imports/:importId/report", handler.GetImportReportHandler)
+		merchants.GET("/:merchantId", handler.GetMerchantHandler)
+		merchants.PATCH("/:merchantId", handler.UpdateMerchantHandler)
+		merchants.DELETE("/:merchantId", handler.DeleteMerchantHandler)
+		merchants.GET("/:merchantId/delivery-area", handler.GetDeliveryAreaHandler)
+		merchants.PUT("/:merchantId/delivery-area", handler.UpdateDeliveryAreaHandler)
+		merchants.GET("/:merchantId/opening-hours", handler.GetOpeningHoursHandler)
+		merchants.PUT("/:merchantId/opening-hours", handler.UpdateOpeningHoursHandler)
 	}
 }
@@ -2,26 +2,31 @@ package merchant
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"belimang/internal/app/items"
 	"belimang/internal/infrastructure/cache"
 	"belimang/internal/infrastructure/database"
 	logger "belimang/internal/pkg/logging"
+	"belimang/internal/pkg/utils"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // MerchantService handles Merchant business logic
 type MerchantService struct {
 	cache *cache.RedisCache
 	db    database.Querier
+	store *database.DB
 }
 
 // NewMerchantService creates a new MerchantService
-func NewMerchantService(cache *cache.RedisCache, database database.Querier) *MerchantService {
-	return &MerchantService{cache: cache, db: database}
+func NewMerchantService(cache *cache.RedisCache, database database.Querier, store *database.DB) *MerchantService {
+	return &MerchantService{cache: cache, db: database, store: store}
 }
 
 func (s *MerchantService) CreateMerchantService(ctx context.Context, adminID uuid.UUID, req PostMerchantRequest) (PostMerchantResponse, error) {
@@ -31,14 +36,7 @@ func (s *MerchantService) CreateMerchantService(ctx context.Context, adminID uui
 	// Should check db admin existed?
 
 	// update db merchant
-	rows, err := s.db.CreateMerchant(ctx, database.CreateMerchantParams{
-		AdminID:          adminID,
-		Name:             req.Name,
-		MerchantCategory: req.MerchantCategory,
-		ImageUrl:         req.ImageURL,
-		Lat:              req.Location.Latitude,
-		Lng:              req.Location.Longitude,
-	})
+	rows, err := s.db.CreateMerchant(ctx, newCreateMerchantParams(adminID, req))
 	if err != nil {
 		logger.ErrorCtx(ctx, "Failed to create merchant", "error", err)
 		return PostMerchantResponse{}, err
@@ -56,10 +54,43 @@ func (s *MerchantService) CreateMerchantService(ctx context.Context, adminID uui
 	return resp, nil
 }
 
-// SearchMerchantsService searches merchants using filter params
-func (s *MerchantService) SearchMerchantsService(ctx context.Context, filter MerchantFilter) (GetMerchantsResponse, error) {
+// newCreateMerchantParams maps a validated request to the insert of a merchant
+// owned by adminID
+func newCreateMerchantParams(adminID uuid.UUID, req PostMerchantRequest) database.CreateMerchantParams {
+	deliveryRadius := req.DeliveryRadiusInMeters
+	if deliveryRadius == 0 {
+		deliveryRadius = int(utils.DEFAULT_DELIVERY_RADIUS_M)
+	}
+
+	return database.CreateMerchantParams{
+		AdminID:              adminID,
+		Name:                 req.Name,
+		MerchantCategory:     req.MerchantCategory,
+		ImageUrl:             req.ImageURL,
+		Lat:                  req.Location.Latitude,
+		Lng:                  req.Location.Longitude,
+		DeliveryRadiusMeters: deliveryRadius,
+
+		PreparationTimeMinutes: req.PreparationTimeInMinutes,
+	}
+}
+
+// SearchMerchantsService searches the merchants of adminID using filter params.
+// Platform admins search every merchant.
+func (s *MerchantService) SearchMerchantsService(ctx context.Context, adminID uuid.UUID, filter MerchantFilter) (GetMerchantsResponse, error) {
 	logger.InfoCtx(ctx, "Create search merchants process", "merchantId", filter.MerchantID, "name", filter.Name, "category", filter.MerchantCategory, "sort", filter.CreatedAtSort)
 
+	platformAdmin, err := s.db.IsPlatformAdmin(ctx, adminID)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to check platform admin", "adminId", adminID, "error", err)
+		return GetMerchantsResponse{}, err
+	}
+	// uuid.Nil berarti tanpa filter admin
+	ownerFilter := adminID
+	if platformAdmin {
+		ownerFilter = uuid.Nil
+	}
+
 	var merchantId uuid.UUID
 	if filter.MerchantID != "" {
 		id, err := uuid.Parse(filter.MerchantID)
@@ -70,7 +101,7 @@ func (s *MerchantService) SearchMerchantsService(ctx context.Context, filter Mer
 
 	merchantCategory := filter.MerchantCategory
 	if merchantCategory != "" {
-		if _, ok := validMerchantCategories[merchantCategory]; !ok {
+		if !utils.IsMerchantCategory(merchantCategory) {
 			return GetMerchantsResponse{
 				Data: []Merchant{},
 				Meta: Meta{Limit: filter.Limit, Offset: filter.Offset, Total: 0},
@@ -110,6 +141,7 @@ func (s *MerchantService) SearchMerchantsService(ctx context.Context, filter Mer
 				Column3: filter.MerchantCategory,
 				Limit:   int32(limit),
 				Offset:  int32(offset),
+				Column6: ownerFilter,
 			})
 
 			if err != nil {
@@ -138,6 +170,7 @@ func (s *MerchantService) SearchMerchantsService(ctx context.Context, filter Mer
 				Column3: filter.MerchantCategory,
 				Limit:   int32(limit),
 				Offset:  int32(offset),
+				Column6: ownerFilter,
 			})
 
 			if err != nil {
@@ -169,6 +202,7 @@ func (s *MerchantService) SearchMerchantsService(ctx context.Context, filter Mer
 			Column1: merchantId,
 			Column2: filter.Name,
 			Column3: filter.MerchantCategory,
+			Column4: ownerFilter,
 		})
 	}()
 
@@ -185,3 +219,411 @@ func (s *MerchantService) SearchMerchantsService(ctx context.Context, filter Mer
 	logger.InfoCtx(ctx, "Merchant searched successfully", "data", data, "Meta", meta)
 	return GetMerchantsResponse{Data: data, Meta: meta}, nil
 }
+
+// GetMerchantService returns a merchant adminID may manage
+func (s *MerchantService) GetMerchantService(ctx context.Context, adminID, merchantID uuid.UUID) (MerchantDetailResponse, error) {
+	ownerID, err := s.authorizeMerchant(ctx, adminID, merchantID)
+	if err != nil {
+		return MerchantDetailResponse{}, err
+	}
+
+	row, err := s.db.GetAdminMerchant(ctx, database.GetAdminMerchantParams{
+		MerchantID: merchantID,
+		AdminID:    ownerID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return MerchantDetailResponse{}, ErrMerchantNotFound
+		}
+		logger.ErrorCtx(ctx, "Failed to get merchant", "merchantId", merchantID, "error", err)
+		return MerchantDetailResponse{}, err
+	}
+
+	return toMerchantDetailResponse(database.UpdateMerchantRow(row)), nil
+}
+
+// UpdateMerchantService applies a partial update to a merchant adminID may manage
+func (s *MerchantService) UpdateMerchantService(ctx context.Context, adminID, merchantID uuid.UUID, req PatchMerchantRequest) (MerchantDetailResponse, error) {
+	logger.InfoCtx(ctx, "Update merchant process", "merchantId", merchantID, "request", req)
+
+	ownerID, err := s.authorizeMerchant(ctx, adminID, merchantID)
+	if err != nil {
+		return MerchantDetailResponse{}, err
+	}
+
+	params := database.UpdateMerchantParams{
+		Name:                   req.Name,
+		MerchantCategory:       req.MerchantCategory,
+		ImageUrl:               req.ImageURL,
+		DeliveryRadiusMeters:   req.DeliveryRadiusInMeters,
+		PreparationTimeMinutes: req.PreparationTimeInMinutes,
+		MerchantID:             merchantID,
+		AdminID:                ownerID,
+	}
+	if req.Location != nil {
+		params.Lat = &req.Location.Latitude
+		params.Lng = &req.Location.Longitude
+	}
+
+	row, err := s.db.UpdateMerchant(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return MerchantDetailResponse{}, ErrMerchantNotFound
+		}
+		logger.ErrorCtx(ctx, "Failed to update merchant", "merchantId", merchantID, "error", err)
+		return MerchantDetailResponse{}, err
+	}
+
+	s.invalidateMerchantCache(ctx, merchantID)
+
+	logger.InfoCtx(ctx, "Merchant updated successfully", "merchantId", merchantID, "h3Index", row.H3Index)
+	return toMerchantDetailResponse(row), nil
+}
+
+// DeleteMerchantService deletes a merchant adminID may manage. The merchant is
+// only marked as deleted, so past orders keep it; it disappears from every
+// listing, search and new estimate. Merchants with orders still in progress
+// cannot be deleted.
+func (s *MerchantService) DeleteMerchantService(ctx context.Context, adminID, merchantID uuid.UUID) error {
+	logger.InfoCtx(ctx, "Delete merchant process", "merchantId", merchantID)
+
+	ownerID, err := s.authorizeMerchant(ctx, adminID, merchantID)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.WithTx(ctx, func(q *database.Queries) error {
+		active, err := q.HasActiveMerchantOrders(ctx, merchantID)
+		if err != nil {
+			return err
+		}
+		if active {
+			return ErrMerchantInUse
+		}
+
+		deleted, err := q.DeleteMerchant(ctx, database.DeleteMerchantParams{
+			MerchantID: merchantID,
+			AdminID:    ownerID,
+		})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrMerchantNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrMerchantNotFound) && !errors.Is(err, ErrMerchantInUse) {
+			logger.ErrorCtx(ctx, "Failed to delete merchant", "merchantId", merchantID, "error", err)
+		}
+		return err
+	}
+
+	s.invalidateMerchantCache(ctx, merchantID)
+	if err := items.InvalidateMerchantItemsCache(ctx, s.cache, merchantID); err != nil {
+		logger.WarnCtx(ctx, "Failed to invalidate merchant items cache", "merchantId", merchantID, "error", err)
+	}
+
+	logger.InfoCtx(ctx, "Merchant deleted successfully", "merchantId", merchantID)
+	return nil
+}
+
+// authorizeMerchant checks that adminID may manage merchantID, i.e. owns it or
+// is a platform admin, and returns the merchant's owner for the admin-scoped queries
+func (s *MerchantService) authorizeMerchant(ctx context.Context, adminID, merchantID uuid.UUID) (uuid.UUID, error) {
+	access, err := s.db.GetMerchantAccess(ctx, database.GetMerchantAccessParams{
+		AdminID:    adminID,
+		MerchantID: merchantID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrMerchantNotFound
+		}
+		logger.ErrorCtx(ctx, "Failed to check merchant access", "merchantId", merchantID, "error", err)
+		return uuid.Nil, err
+	}
+	if !access.Allowed {
+		logger.WarnCtx(ctx, "Admin is not allowed to manage merchant", "adminId", adminID, "merchantId", merchantID)
+		return uuid.Nil, ErrForbidden
+	}
+	return access.AdminID, nil
+}
+
+// invalidateMerchantCache removes the merchant:{merchantID} entries written by
+// CreateMerchantService. Failures are logged by the cache and otherwise ignored,
+// the entries expire after MerchantTTL anyway.
+func (s *MerchantService) invalidateMerchantCache(ctx context.Context, merchantID uuid.UUID) {
+	s.cache.Delete(ctx, fmt.Sprintf(cache.MerchantKey, merchantID))
+	s.cache.Delete(ctx, fmt.Sprintf(cache.MerchantExistsKey, merchantID))
+}
+
+func toMerchantDetailResponse(row database.UpdateMerchantRow) MerchantDetailResponse {
+	return MerchantDetailResponse{
+		MerchantID:               row.ID.String(),
+		Name:                     row.Name,
+		MerchantCategory:         row.MerchantCategory,
+		ImageURL:                 row.ImageUrl,
+		Location:                 Location{Latitude: row.Lat, Longitude: row.Lng},
+		H3Index:                  row.H3Index,
+		DeliveryRadiusInMeters:   row.DeliveryRadiusMeters,
+		PreparationTimeInMinutes: row.PreparationTimeMinutes,
+		CreatedAt:                row.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
+
+// GetDeliveryAreaService returns the delivery radius and service zone of a merchant adminID may manage
+func (s *MerchantService) GetDeliveryAreaService(ctx context.Context, adminID, merchantID uuid.UUID) (DeliveryAreaResponse, error) {
+	ownerID, err := s.authorizeMerchant(ctx, adminID, merchantID)
+	if err != nil {
+		return DeliveryAreaResponse{}, err
+	}
+
+	radius, err := s.db.GetMerchantDeliveryRadius(ctx, database.GetMerchantDeliveryRadiusParams{
+		MerchantID: merchantID,
+		AdminID:    ownerID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return DeliveryAreaResponse{}, ErrMerchantNotFound
+		}
+		logger.ErrorCtx(ctx, "Failed to get merchant delivery radius", "merchantId", merchantID, "error", err)
+		return DeliveryAreaResponse{}, err
+	}
+
+	zone, err := s.db.ListMerchantServiceZone(ctx, merchantID)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to list merchant service zone", "merchantId", merchantID, "error", err)
+		return DeliveryAreaResponse{}, err
+	}
+
+	return DeliveryAreaResponse{
+		MerchantID:             merchantID.String(),
+		DeliveryRadiusInMeters: radius,
+		ServiceZone:            zone,
+	}, nil
+}
+
+// UpdateDeliveryAreaService replaces the delivery radius and service zone of a merchant adminID may manage
+func (s *MerchantService) UpdateDeliveryAreaService(ctx context.Context, adminID, merchantID uuid.UUID, req DeliveryAreaRequest) (DeliveryAreaResponse, error) {
+	logger.InfoCtx(ctx, "Update merchant delivery area process", "merchantId", merchantID, "radius", req.DeliveryRadiusInMeters, "cells", len(req.ServiceZone))
+
+	ownerID, err := s.authorizeMerchant(ctx, adminID, merchantID)
+	if err != nil {
+		return DeliveryAreaResponse{}, err
+	}
+
+	err = s.store.WithTx(ctx, func(q *database.Queries) error {
+		updated, err := q.UpdateMerchantDeliveryRadius(ctx, database.UpdateMerchantDeliveryRadiusParams{
+			DeliveryRadiusMeters: req.DeliveryRadiusInMeters,
+			MerchantID:           merchantID,
+			AdminID:              ownerID,
+		})
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrMerchantNotFound
+		}
+
+		if err := q.DeleteMerchantServiceZone(ctx, merchantID); err != nil {
+			return err
+		}
+		if len(req.ServiceZone) == 0 {
+			return nil
+		}
+		return q.AddMerchantServiceZoneCells(ctx, database.AddMerchantServiceZoneCellsParams{
+			MerchantID: merchantID,
+			H3Cells:    req.ServiceZone,
+		})
+	})
+	if err != nil {
+		if !errors.Is(err, ErrMerchantNotFound) {
+			logger.ErrorCtx(ctx, "Failed to update merchant delivery area", "merchantId", merchantID, "error", err)
+		}
+		return DeliveryAreaResponse{}, err
+	}
+
+	return s.GetDeliveryAreaService(ctx, adminID, merchantID)
+}
+
+// GetOpeningHoursService returns the timezone, weekly hours and upcoming exceptions of a merchant adminID may manage
+func (s *MerchantService) GetOpeningHoursService(ctx context.Context, adminID, merchantID uuid.UUID) (OpeningHoursResponse, error) {
+	ownerID, err := s.authorizeMerchant(ctx, adminID, merchantID)
+	if err != nil {
+		return OpeningHoursResponse{}, err
+	}
+
+	timezone, err := s.db.GetMerchantTimezone(ctx, database.GetMerchantTimezoneParams{
+		MerchantID: merchantID,
+		AdminID:    ownerID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OpeningHoursResponse{}, ErrMerchantNotFound
+		}
+		logger.ErrorCtx(ctx, "Failed to get merchant timezone", "merchantId", merchantID, "error", err)
+		return OpeningHoursResponse{}, err
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return OpeningHoursResponse{}, fmt.Errorf("invalid timezone for merchant %s: %w", merchantID, err)
+	}
+
+	hours, err := s.db.ListMerchantOpeningHours(ctx, []uuid.UUID{merchantID})
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to list merchant opening hours", "merchantId", merchantID, "error", err)
+		return OpeningHoursResponse{}, err
+	}
+
+	exceptions, err := s.db.ListMerchantOpeningExceptions(ctx, database.ListMerchantOpeningExceptionsParams{
+		MerchantIds: []uuid.UUID{merchantID},
+		FromDate:    time.Now().UTC().AddDate(0, 0, -2).Format(utils.DateLayout),
+	})
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to list merchant opening exceptions", "merchantId", merchantID, "error", err)
+		return OpeningHoursResponse{}, err
+	}
+
+	schedule := utils.OpeningSchedule{
+		Location:   loc,
+		Weekly:     make(map[time.Weekday][]utils.OpeningWindow),
+		Exceptions: make(map[string]utils.OpeningException, len(exceptions)),
+	}
+	resp := OpeningHoursResponse{
+		MerchantID: merchantID.String(),
+		Timezone:   timezone,
+		Weekly:     make([]WeeklyOpeningHours, 0, len(hours)),
+		Exceptions: make([]OpeningHoursException, 0, len(exceptions)),
+	}
+	for _, h := range hours {
+		day := time.Weekday(h.DayOfWeek)
+		schedule.Weekly[day] = append(schedule.Weekly[day], utils.OpeningWindow{OpenMinute: h.OpenMinute, CloseMinute: h.CloseMinute})
+		resp.Weekly = append(resp.Weekly, WeeklyOpeningHours{
+			DayOfWeek: h.DayOfWeek,
+			Open:      utils.FormatClock(h.OpenMinute),
+			Close:     utils.FormatClock(h.CloseMinute),
+		})
+	}
+	for _, ex := range exceptions {
+		schedule.Exceptions[ex.ExceptionDate] = utils.OpeningException{
+			Closed: ex.IsClosed,
+			Window: utils.OpeningWindow{OpenMinute: ex.OpenMinute, CloseMinute: ex.CloseMinute},
+		}
+		item := OpeningHoursException{Date: ex.ExceptionDate, Closed: ex.IsClosed, Note: ex.Note}
+		if !ex.IsClosed {
+			item.Open = utils.FormatClock(ex.OpenMinute)
+			item.Close = utils.FormatClock(ex.CloseMinute)
+		}
+		resp.Exceptions = append(resp.Exceptions, item)
+	}
+	resp.IsOpen = schedule.IsOpenAt(time.Now())
+
+	return resp, nil
+}
+
+// UpdateOpeningHoursService replaces the timezone, weekly hours and exceptions of a merchant adminID may manage
+func (s *MerchantService) UpdateOpeningHoursService(ctx context.Context, adminID, merchantID uuid.UUID, req OpeningHoursRequest) (OpeningHoursResponse, error) {
+	logger.InfoCtx(ctx, "Update merchant opening hours process", "merchantId", merchantID, "timezone", req.Timezone, "weekly", len(req.Weekly), "exceptions", len(req.Exceptions))
+
+	ownerID, err := s.authorizeMerchant(ctx, adminID, merchantID)
+	if err != nil {
+		return OpeningHoursResponse{}, err
+	}
+
+	hours, err := toOpeningHoursParams(merchantID, req.Weekly)
+	if err != nil {
+		return OpeningHoursResponse{}, err
+	}
+	exceptions, err := toOpeningExceptionsParams(merchantID, req.Exceptions)
+	if err != nil {
+		return OpeningHoursResponse{}, err
+	}
+
+	err = s.store.WithTx(ctx, func(q *database.Queries) error {
+		updated, err := q.UpdateMerchantTimezone(ctx, database.UpdateMerchantTimezoneParams{
+			Timezone:   req.Timezone,
+			MerchantID: merchantID,
+			AdminID:    ownerID,
+		})
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrMerchantNotFound
+		}
+
+		if err := q.DeleteMerchantOpeningHours(ctx, merchantID); err != nil {
+			return err
+		}
+		if len(hours.Days) > 0 {
+			if err := q.AddMerchantOpeningHours(ctx, hours); err != nil {
+				return err
+			}
+		}
+
+		if err := q.DeleteMerchantOpeningExceptions(ctx, merchantID); err != nil {
+			return err
+		}
+		if len(exceptions.Dates) == 0 {
+			return nil
+		}
+		return q.AddMerchantOpeningExceptions(ctx, exceptions)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrMerchantNotFound) {
+			logger.ErrorCtx(ctx, "Failed to update merchant opening hours", "merchantId", merchantID, "error", err)
+		}
+		return OpeningHoursResponse{}, err
+	}
+
+	return s.GetOpeningHoursService(ctx, adminID, merchantID)
+}
+
+// toOpeningHoursParams converts validated weekly hours, rejecting two windows
+// opening at the same time on the same day
+func toOpeningHoursParams(merchantID uuid.UUID, weekly []WeeklyOpeningHours) (database.AddMerchantOpeningHoursParams, error) {
+	params := database.AddMerchantOpeningHoursParams{MerchantID: merchantID}
+	seen := make(map[[2]int]struct{}, len(weekly))
+	for _, w := range weekly {
+		open, _ := utils.ParseClock(w.Open)
+		closeAt, _ := utils.ParseClock(w.Close)
+
+		key := [2]int{w.DayOfWeek, open}
+		if _, ok := seen[key]; ok {
+			return params, fmt.Errorf("%w: duplicate window on day %d opening at %s", ErrInvalidHours, w.DayOfWeek, w.Open)
+		}
+		seen[key] = struct{}{}
+
+		params.Days = append(params.Days, w.DayOfWeek)
+		params.OpenMinutes = append(params.OpenMinutes, open)
+		params.CloseMinutes = append(params.CloseMinutes, closeAt)
+	}
+	return params, nil
+}
+
+// toOpeningExceptionsParams converts validated exceptions, one per date
+func toOpeningExceptionsParams(merchantID uuid.UUID, exceptions []OpeningHoursException) (database.AddMerchantOpeningExceptionsParams, error) {
+	params := database.AddMerchantOpeningExceptionsParams{MerchantID: merchantID}
+	seen := make(map[string]struct{}, len(exceptions))
+	for _, ex := range exceptions {
+		if _, ok := seen[ex.Date]; ok {
+			return params, fmt.Errorf("%w: duplicate exception date %s", ErrInvalidHours, ex.Date)
+		}
+		seen[ex.Date] = struct{}{}
+
+		var open, closeAt int
+		if !ex.Closed {
+			open, _ = utils.ParseClock(ex.Open)
+			closeAt, _ = utils.ParseClock(ex.Close)
+		}
+
+		params.Dates = append(params.Dates, ex.Date)
+		params.IsClosed = append(params.IsClosed, ex.Closed)
+		params.OpenMinutes = append(params.OpenMinutes, open)
+		params.CloseMinutes = append(params.CloseMinutes, closeAt)
+		params.Notes = append(params.Notes, ex.Note)
+	}
+	return params, nil
+}
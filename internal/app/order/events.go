@@ -0,0 +1,197 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"belimang/internal/infrastructure/cache"
+	logger "belimang/internal/pkg/logging"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Order event types streamed to GET /users/orders/:orderId/events
+const (
+	EventCreated         = "order.created"
+	EventStatusChanged   = "order.status"
+	EventCourierAssigned = "order.courier_assigned"
+	EventReleased        = "order.released" // scheduled order passed on to the merchants
+	EventSnapshot        = "order.snapshot" // current state, sent when events cannot be replayed
+	EventETA             = "order.eta"
+)
+
+// jumlah event terakhir per order yang disimpan untuk replay Last-Event-ID
+const orderEventLogSize = 100
+
+// ErrEventsClosed is returned by Subscribe once the server is shutting down
+var ErrEventsClosed = errors.New("order events are shutting down")
+
+// OrderEvent is a single change of an order. IDs increase per order, so a
+// client can resume after the last ID it saw.
+type OrderEvent struct {
+	ID                  int64  `json:"id"`
+	Type                string `json:"type"`
+	OrderID             string `json:"orderId"`
+	Status              string `json:"status,omitempty"`
+	FromStatus          string `json:"fromStatus,omitempty"`
+	CourierID           string `json:"courierId,omitempty"`
+	EstimatedDeliveryAt string `json:"estimatedDeliveryAt,omitempty"`
+	OccurredAt          string `json:"occurredAt"`
+}
+
+type ETAEvent struct {
+	OrderID             string `json:"orderId"`
+	EstimatedDeliveryAt string `json:"estimatedDeliveryAt"`
+	RemainingSeconds    int64  `json:"remainingSeconds"`
+}
+
+// EventPublisher fans order events out over Redis pub/sub so every app
+// replica can stream them, and keeps the latest ones for replay.
+type EventPublisher struct {
+	cache *cache.RedisCache
+
+	mu      sync.Mutex
+	closed  bool
+	closing chan struct{} // ditutup saat shutdown agar stream yang terbuka berakhir
+	streams sync.WaitGroup
+}
+
+func NewEventPublisher(c *cache.RedisCache) *EventPublisher {
+	return &EventPublisher{cache: c, closing: make(chan struct{})}
+}
+
+// Publish assigns the event its ID and sends it to subscribers. Publishing is
+// best effort: the change itself is already stored, so a Redis failure is
+// only logged and clients catch up from the snapshot on reconnect.
+func (p *EventPublisher) Publish(ctx context.Context, orderID uuid.UUID, event OrderEvent) {
+	client := p.cache.Client()
+	seqKey := fmt.Sprintf(cache.OrderEventsSeqKey, orderID)
+	logKey := fmt.Sprintf(cache.OrderEventsLogKey, orderID)
+
+	id, err := client.Incr(ctx, seqKey).Result()
+	if err != nil {
+		logger.WarnCtx(ctx, "Failed to assign order event ID", "order_id", orderID, "error", err)
+		return
+	}
+
+	event.ID = id
+	event.OrderID = orderID.String()
+	event.OccurredAt = time.Now().Format(time.RFC3339Nano)
+	data, err := json.Marshal(event)
+	if err != nil {
+		logger.WarnCtx(ctx, "Failed to encode order event", "order_id", orderID, "error", err)
+		return
+	}
+
+	pipe := client.TxPipeline()
+	pipe.RPush(ctx, logKey, data)
+	pipe.LTrim(ctx, logKey, -orderEventLogSize, -1)
+	pipe.Expire(ctx, logKey, cache.OrderEventsTTL)
+	pipe.Expire(ctx, seqKey, cache.OrderEventsTTL)
+	pipe.Publish(ctx, fmt.Sprintf(cache.OrderEventsChannel, orderID), data)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.WarnCtx(ctx, "Failed to publish order event", "order_id", orderID, "type", event.Type, "error", err)
+		return
+	}
+	logger.DebugCtx(ctx, "Order event published", "order_id", orderID, "type", event.Type, "id", id)
+}
+
+// Subscription is an open subscription to an order's events
+type Subscription struct {
+	*redis.PubSub
+	release sync.Once
+	done    func()
+}
+
+// Close unsubscribes and lets Close on the publisher stop waiting for it
+func (s *Subscription) Close() error {
+	err := s.PubSub.Close()
+	s.release.Do(s.done)
+	return err
+}
+
+// Subscribe listens on the order's channel. The subscription is confirmed
+// before returning, so nothing published afterwards is missed. It must be
+// closed, and stream loops should stop once Closing is closed.
+func (p *EventPublisher) Subscribe(ctx context.Context, orderID uuid.UUID) (*Subscription, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrEventsClosed
+	}
+	p.streams.Add(1)
+	p.mu.Unlock()
+
+	sub := p.cache.Client().Subscribe(ctx, fmt.Sprintf(cache.OrderEventsChannel, orderID))
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		p.streams.Done()
+		return nil, fmt.Errorf("failed to subscribe to order events: %w", err)
+	}
+	return &Subscription{PubSub: sub, done: p.streams.Done}, nil
+}
+
+// Closing is closed when the server starts shutting down
+func (p *EventPublisher) Closing() <-chan struct{} {
+	return p.closing
+}
+
+// Close ends the open event streams and waits until all their subscriptions
+// are closed, or until ctx is done. New subscriptions are refused afterwards.
+func (p *EventPublisher) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.closing)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.streams.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LastEventID returns the ID of the latest event published for the order
+func (p *EventPublisher) LastEventID(ctx context.Context, orderID uuid.UUID) (int64, error) {
+	id, err := p.cache.Client().Get(ctx, fmt.Sprintf(cache.OrderEventsSeqKey, orderID)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return id, err
+}
+
+// EventsAfter returns the kept events with an ID greater than afterID, oldest first
+func (p *EventPublisher) EventsAfter(ctx context.Context, orderID uuid.UUID, afterID int64) ([]OrderEvent, error) {
+	raw, err := p.cache.Client().LRange(ctx, fmt.Sprintf(cache.OrderEventsLogKey, orderID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read order events: %w", err)
+	}
+
+	var events []OrderEvent
+	for _, r := range raw {
+		var event OrderEvent
+		if err := json.Unmarshal([]byte(r), &event); err != nil {
+			continue
+		}
+		if event.ID > afterID {
+			events = append(events, event)
+		}
+	}
+	// publisher bersamaan bisa menulis log tidak berurutan
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
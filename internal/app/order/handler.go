@@ -0,0 +1,241 @@
+package order
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"belimang/internal/infrastructure/database"
+	"belimang/internal/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type OrderHandler struct {
+	orderService *OrderService
+	validate     *validator.Validate
+}
+
+func NewOrderHandler(orderService *OrderService, v *validator.Validate) *OrderHandler {
+	return &OrderHandler{orderService: orderService, validate: v}
+}
+
+func (h *OrderHandler) UpdateStatus(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrOrderNotFound.Error()})
+		return
+	}
+
+	var req UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	resp, err := h.orderService.AdvanceByAdmin(c, adminID, orderID, database.OrderStatus(req.Status))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *OrderHandler) GetStatusHistory(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrOrderNotFound.Error()})
+		return
+	}
+
+	resp, err := h.orderService.StatusHistory(c, adminID, orderID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *OrderHandler) Cancel(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrOrderNotFound.Error()})
+		return
+	}
+
+	resp, err := h.orderService.CancelByUser(c, userID, orderID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *OrderHandler) ListUserOrders(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	filter := UserOrderFilter{
+		Limit:  5,
+		Offset: 0,
+	}
+
+	// --- limit & offset ---
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.ParseInt(limitStr, 10, 32); err == nil && l > 0 {
+			filter.Limit = int32(l)
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if o, err := strconv.ParseInt(offsetStr, 10, 32); err == nil && o >= 0 {
+			filter.Offset = int32(o)
+		}
+	}
+
+	// merchantId atau merchantCategory yang tidak valid tidak akan cocok dengan order apa pun
+	if merchantIDStr := c.Query("merchantId"); merchantIDStr != "" {
+		merchantID, err := uuid.Parse(merchantIDStr)
+		if err != nil {
+			h.respondEmpty(c, filter)
+			return
+		}
+		filter.MerchantID = merchantID
+	}
+	if category := c.Query("merchantCategory"); category != "" {
+		if !utils.IsMerchantCategory(category) {
+			h.respondEmpty(c, filter)
+			return
+		}
+		filter.MerchantCategory = category
+	}
+
+	filter.Name = c.Query("name")
+
+	// --- date range (RFC3339 or YYYY-MM-DD, inclusive) ---
+	if from := c.Query("createdAtFrom"); from != "" {
+		t, err := parseDateParam(from, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid createdAtFrom"})
+			return
+		}
+		filter.CreatedFrom = t
+	}
+	if to := c.Query("createdAtTo"); to != "" {
+		t, err := parseDateParam(to, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid createdAtTo"})
+			return
+		}
+		filter.CreatedTo = t
+	}
+
+	resp, err := h.orderService.ListUserOrders(c, userID, filter)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *OrderHandler) GetUserOrder(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrOrderNotFound.Error()})
+		return
+	}
+
+	resp, err := h.orderService.GetUserOrder(c, userID, orderID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *OrderHandler) respondEmpty(c *gin.Context, filter UserOrderFilter) {
+	c.JSON(http.StatusOK, ListUserOrdersResponse{
+		Data: []UserOrderResponse{},
+		Meta: PaginationMeta{Limit: filter.Limit, Offset: filter.Offset, Total: 0},
+	})
+}
+
+// parseDateParam accepts RFC3339 timestamps or plain dates. A plain date used
+// as an upper bound covers the whole day.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+func (h *OrderHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrCancelNotAllowed), errors.Is(err, ErrStatusConflict),
+		errors.Is(err, ErrOrderNotReleased):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	rawUserID, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, errors.New("user not authenticated")
+	}
+	userID, ok := rawUserID.(string)
+	if !ok {
+		return uuid.Nil, errors.New("invalid user context")
+	}
+	return uuid.Parse(userID)
+}
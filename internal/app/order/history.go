@@ -0,0 +1,171 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"belimang/internal/infrastructure/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListUserOrders returns a page of the user's orders, newest first. An order
+// matches the merchant filters if any of its merchants does.
+func (s *OrderService) ListUserOrders(ctx context.Context, userID uuid.UUID, filter UserOrderFilter) (ListUserOrdersResponse, error) {
+	rows, err := s.db.Queries.ListUserOrders(ctx, database.ListUserOrdersParams{
+		UserID:           userID,
+		CreatedFrom:      filter.CreatedFrom,
+		CreatedTo:        filter.CreatedTo,
+		MerchantID:       filter.MerchantID,
+		MerchantCategory: filter.MerchantCategory,
+		Name:             filter.Name,
+		LimitCount:       filter.Limit,
+		OffsetCount:      filter.Offset,
+	})
+	if err != nil {
+		return ListUserOrdersResponse{}, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	total, err := s.db.Queries.CountUserOrders(ctx, database.CountUserOrdersParams{
+		UserID:           userID,
+		CreatedFrom:      filter.CreatedFrom,
+		CreatedTo:        filter.CreatedTo,
+		MerchantID:       filter.MerchantID,
+		MerchantCategory: filter.MerchantCategory,
+		Name:             filter.Name,
+	})
+	if err != nil {
+		return ListUserOrdersResponse{}, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	orders := make([]UserOrderResponse, len(rows))
+	orderIDs := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		orderIDs[i] = row.ID
+		orders[i] = UserOrderResponse{
+			OrderID:                        row.ID.String(),
+			Status:                         string(row.Status),
+			TotalPrice:                     row.TotalPrice,
+			EstimatedDeliveryTimeInMinutes: row.EstimatedDeliveryTimeInMinutes,
+			CreatedAt:                      row.CreatedAt.Format(time.RFC3339Nano),
+		}
+	}
+
+	if err := s.attachOrderDetails(ctx, orders, orderIDs); err != nil {
+		return ListUserOrdersResponse{}, err
+	}
+
+	return ListUserOrdersResponse{
+		Data: orders,
+		Meta: PaginationMeta{
+			Limit:  filter.Limit,
+			Offset: filter.Offset,
+			Total:  total,
+		},
+	}, nil
+}
+
+// GetUserOrder returns one of the user's orders. Orders of other users are
+// reported as not found.
+func (s *OrderService) GetUserOrder(ctx context.Context, userID, orderID uuid.UUID) (UserOrderResponse, error) {
+	row, err := s.db.Queries.GetUserOrder(ctx, database.GetUserOrderParams{
+		ID:     orderID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return UserOrderResponse{}, ErrOrderNotFound
+		}
+		return UserOrderResponse{}, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	orders := []UserOrderResponse{{
+		OrderID:                        row.ID.String(),
+		Status:                         string(row.Status),
+		TotalPrice:                     row.TotalPrice,
+		EstimatedDeliveryTimeInMinutes: row.EstimatedDeliveryTimeInMinutes,
+		CreatedAt:                      row.CreatedAt.Format(time.RFC3339Nano),
+	}}
+	if err := s.attachOrderDetails(ctx, orders, []uuid.UUID{row.ID}); err != nil {
+		return UserOrderResponse{}, err
+	}
+
+	return orders[0], nil
+}
+
+// attachOrderDetails loads the merchants and items of orders in one query.
+// orderIDs[i] must be the id of orders[i].
+func (s *OrderService) attachOrderDetails(ctx context.Context, orders []UserOrderResponse, orderIDs []uuid.UUID) error {
+	for i := range orders {
+		orders[i].Orders = []MerchantOrder{}
+	}
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	details, err := s.db.Queries.GetOrderDetailsByIds(ctx, orderIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get order details: %w", err)
+	}
+
+	optionRows, err := s.db.Queries.ListOrderItemOptions(ctx, orderIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get order item options: %w", err)
+	}
+	options := make(map[uuid.UUID][]OrderItemOption)
+	for _, row := range optionRows {
+		options[row.OrderItemID] = append(options[row.OrderItemID], OrderItemOption{
+			OptionID:   row.OptionID.String(),
+			GroupName:  row.GroupName,
+			Name:       row.OptionName,
+			PriceDelta: row.PriceDelta,
+		})
+	}
+
+	index := make(map[uuid.UUID]int, len(orderIDs))
+	for i, id := range orderIDs {
+		index[id] = i
+	}
+
+	// baris sudah terurut per order lalu per merchant sesuai rute
+	var lastOrderMerchantID uuid.UUID
+	for _, d := range details {
+		order := &orders[index[d.OrderID]]
+		if d.OrderMerchantID != lastOrderMerchantID {
+			order.Orders = append(order.Orders, MerchantOrder{
+				Merchant: MerchantInfo{
+					MerchantID:       d.MerchantID.String(),
+					Name:             d.MerchantName,
+					MerchantCategory: d.MerchantCategory,
+					ImageUrl:         d.MerchantImageUrl,
+					Location:         Location{Lat: d.Lat, Long: d.Lng},
+					CreatedAt:        d.MerchantCreatedAt.Format(time.RFC3339Nano),
+				},
+				Items: []OrderItemInfo{},
+			})
+			lastOrderMerchantID = d.OrderMerchantID
+		}
+
+		itemOptions := options[d.OrderItemID]
+		if itemOptions == nil {
+			itemOptions = []OrderItemOption{}
+		}
+
+		merchantOrder := &order.Orders[len(order.Orders)-1]
+		merchantOrder.Items = append(merchantOrder.Items, OrderItemInfo{
+			ItemID:          d.ItemID.String(),
+			Name:            d.ItemName,
+			ProductCategory: d.ProductCategory,
+			Price:           d.UnitPrice,
+			Quantity:        d.Quantity,
+			ImageUrl:        d.ItemImageUrl,
+			CreatedAt:       d.ItemCreatedAt.Format(time.RFC3339Nano),
+			Options:         itemOptions,
+		})
+	}
+
+	return nil
+}
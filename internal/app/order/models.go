@@ -0,0 +1,145 @@
+package order
+
+import (
+	"errors"
+	"time"
+
+	"belimang/internal/infrastructure/database"
+
+	"github.com/google/uuid"
+)
+
+// Actor roles recorded in the status history
+const (
+	ActorUser   = "user"
+	ActorAdmin  = "admin"
+	ActorSystem = "system"
+)
+
+// Actor is whoever triggers a status change
+type Actor struct {
+	ID   uuid.UUID
+	Role string
+}
+
+// SystemActor is used for transitions made by background jobs
+var SystemActor = Actor{ID: uuid.Nil, Role: ActorSystem}
+
+type UpdateStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=accepted preparing picked_up delivered cancelled"`
+}
+
+type StatusResponse struct {
+	OrderID string `json:"orderId"`
+	Status  string `json:"status"`
+}
+
+type StatusHistoryEntry struct {
+	FromStatus *string `json:"fromStatus"` // null for the initial status
+	ToStatus   string  `json:"toStatus"`
+	ActorID    string  `json:"actorId"`
+	ActorRole  string  `json:"actorRole"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+type StatusHistoryResponse struct {
+	OrderID string               `json:"orderId"`
+	Status  string               `json:"status"`
+	History []StatusHistoryEntry `json:"history"`
+}
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrForbidden         = errors.New("order belongs to another admin's merchant")
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrCancelNotAllowed  = errors.New("order can no longer be cancelled")
+	ErrStatusConflict    = errors.New("order status was changed by another request")
+	ErrOrderNotReleased  = errors.New("scheduled order has not been released yet")
+)
+
+// transitions lists the statuses each status may move to
+var transitions = map[database.OrderStatus][]database.OrderStatus{
+	database.OrderStatusPlaced:    {database.OrderStatusAccepted, database.OrderStatusCancelled},
+	database.OrderStatusAccepted:  {database.OrderStatusPreparing, database.OrderStatusCancelled},
+	database.OrderStatusPreparing: {database.OrderStatusPickedUp, database.OrderStatusCancelled},
+	database.OrderStatusPickedUp:  {database.OrderStatusDelivered},
+}
+
+// CanTransition reports whether an order may move from one status to another
+func CanTransition(from, to database.OrderStatus) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// UserOrderFilter holds the filters of GET /users/orders
+type UserOrderFilter struct {
+	MerchantID       uuid.UUID
+	Name             string
+	MerchantCategory string
+	CreatedFrom      time.Time // zero means unbounded
+	CreatedTo        time.Time // zero means unbounded
+	Limit            int32
+	Offset           int32
+}
+
+type Location struct {
+	Lat  float64 `json:"lat"`
+	Long float64 `json:"long"`
+}
+
+type MerchantInfo struct {
+	MerchantID       string   `json:"merchantId"`
+	Name             string   `json:"name"`
+	MerchantCategory string   `json:"merchantCategory"`
+	ImageUrl         string   `json:"imageUrl"`
+	Location         Location `json:"location"`
+	CreatedAt        string   `json:"createdAt"`
+}
+
+type OrderItemInfo struct {
+	ItemID          string            `json:"itemId"`
+	Name            string            `json:"name"`
+	ProductCategory string            `json:"productCategory"`
+	Price           int64             `json:"price"`
+	Quantity        int               `json:"quantity"`
+	ImageUrl        string            `json:"imageUrl"`
+	CreatedAt       string            `json:"createdAt"`
+	Options         []OrderItemOption `json:"options"`
+}
+
+// OrderItemOption is an option chosen for an item, as priced when ordered
+type OrderItemOption struct {
+	OptionID   string `json:"optionId"`
+	GroupName  string `json:"groupName"`
+	Name       string `json:"name"`
+	PriceDelta int64  `json:"priceDelta"`
+}
+
+type MerchantOrder struct {
+	Merchant MerchantInfo    `json:"merchant"`
+	Items    []OrderItemInfo `json:"items"`
+}
+
+type UserOrderResponse struct {
+	OrderID                        string          `json:"orderId"`
+	Status                         string          `json:"status"`
+	TotalPrice                     int64           `json:"totalPrice"`
+	EstimatedDeliveryTimeInMinutes int             `json:"estimatedDeliveryTimeInMinutes"`
+	CreatedAt                      string          `json:"createdAt"`
+	Orders                         []MerchantOrder `json:"orders"` // in route order
+}
+
+type PaginationMeta struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+	Total  int64 `json:"total"`
+}
+
+type ListUserOrdersResponse struct {
+	Data []UserOrderResponse `json:"data"`
+	Meta PaginationMeta      `json:"meta"`
+}
@@ -0,0 +1,26 @@
+package order
+
+import (
+	"belimang/internal/middleware"
+	"belimang/internal/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+func OrderRoutes(router *gin.Engine, handler *OrderHandler, jwtService *jwt.JWTService) {
+	admin := router.Group("/admin/orders")
+	admin.Use(middleware.RequireAdmin(jwtService))
+	{
+		admin.PATCH("/:orderId/status", handler.UpdateStatus)
+		admin.GET("/:orderId/history", handler.GetStatusHistory)
+	}
+
+	users := router.Group("/users/orders")
+	users.Use(middleware.RequireUser(jwtService))
+	{
+		users.GET("", handler.ListUserOrders)
+		users.GET("/:orderId", handler.GetUserOrder)
+		users.GET("/:orderId/events", handler.Events)
+		users.POST("/:orderId/cancel", handler.Cancel)
+	}
+}
@@ -0,0 +1,78 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"belimang/internal/config"
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+)
+
+// order terjadwal yang dirilis per putaran scheduler
+const releaseBatchSize = 100
+
+// ReleaseScheduler passes scheduled orders on to their merchants once their
+// release time is reached. The release time is fixed when the order is
+// created: the scheduled delivery time minus the estimate's ETA, so the food
+// is prepared and driven just in time. Until then the order stays "placed"
+// but hidden from merchants' status changes and from courier dispatch.
+type ReleaseScheduler struct {
+	queries  *database.Queries
+	events   *EventPublisher
+	interval time.Duration
+}
+
+func NewReleaseScheduler(q *database.Queries, events *EventPublisher, cfg config.ScheduleConfig) *ReleaseScheduler {
+	return &ReleaseScheduler{
+		queries:  q,
+		events:   events,
+		interval: cfg.ReleaseInterval,
+	}
+}
+
+// Run releases due orders every interval until ctx is cancelled
+func (s *ReleaseScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	logger.InfoCtx(ctx, "Order release scheduler started", "interval", s.interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ReleaseDue(ctx); err != nil {
+				logger.ErrorCtx(ctx, "Order release failed", "error", err)
+			}
+		}
+	}
+}
+
+// ReleaseDue releases every scheduled order whose release time has passed
+func (s *ReleaseScheduler) ReleaseDue(ctx context.Context) error {
+	for {
+		released, err := s.queries.ReleaseDueOrders(ctx, releaseBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to release scheduled orders: %w", err)
+		}
+
+		for _, order := range released {
+			logger.InfoCtx(ctx, "Scheduled order released", "orderId", order.ID)
+
+			event := OrderEvent{
+				Type:   EventReleased,
+				Status: string(database.OrderStatusPlaced),
+			}
+			if order.ScheduledDeliveryAt != nil {
+				event.EstimatedDeliveryAt = order.ScheduledDeliveryAt.Format(time.RFC3339)
+			}
+			s.events.Publish(ctx, order.ID, event)
+		}
+
+		if len(released) < releaseBatchSize {
+			return nil
+		}
+	}
+}
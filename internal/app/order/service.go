@@ -0,0 +1,254 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"belimang/internal/app/items"
+	"belimang/internal/infrastructure/cache"
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type OrderService struct {
+	db     *database.DB
+	cache  *cache.RedisCache
+	events *EventPublisher
+}
+
+func NewOrderService(db *database.DB, redisCache *cache.RedisCache, events *EventPublisher) *OrderService {
+	return &OrderService{db: db, cache: redisCache, events: events}
+}
+
+// AdvanceByAdmin moves an order to the given status on behalf of an admin who
+// owns every merchant of the order, or a platform admin.
+func (s *OrderService) AdvanceByAdmin(ctx context.Context, adminID, orderID uuid.UUID, to database.OrderStatus) (StatusResponse, error) {
+	if err := s.checkAdminOwnsOrder(ctx, adminID, orderID); err != nil {
+		return StatusResponse{}, err
+	}
+
+	return s.Transition(ctx, orderID, to, Actor{ID: adminID, Role: ActorAdmin}, func(current database.GetOrderStatusRow) error {
+		// order terjadwal baru boleh diproses merchant setelah dirilis scheduler
+		if current.ReleasedAt == nil && to != database.OrderStatusCancelled {
+			return ErrOrderNotReleased
+		}
+		return nil
+	})
+}
+
+// CancelByUser cancels the user's own order, which is only allowed while it
+// has not been accepted yet.
+func (s *OrderService) CancelByUser(ctx context.Context, userID, orderID uuid.UUID) (StatusResponse, error) {
+	return s.Transition(ctx, orderID, database.OrderStatusCancelled, Actor{ID: userID, Role: ActorUser}, func(current database.GetOrderStatusRow) error {
+		if current.UserID != userID {
+			return ErrOrderNotFound
+		}
+		if current.Status != database.OrderStatusPlaced {
+			return ErrCancelNotAllowed
+		}
+		return nil
+	})
+}
+
+// Transition moves an order to status to and records it in the history.
+// guard, if set, can veto the change after the current status is read.
+func (s *OrderService) Transition(ctx context.Context, orderID uuid.UUID, to database.OrderStatus, actor Actor, guard func(database.GetOrderStatusRow) error) (StatusResponse, error) {
+	var from database.OrderStatus
+	var restocked []uuid.UUID // merchant yang stok itemnya dikembalikan
+	err := s.db.WithTx(ctx, func(q *database.Queries) error {
+		current, err := q.GetOrderStatus(ctx, orderID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrOrderNotFound
+			}
+			return fmt.Errorf("failed to get order status: %w", err)
+		}
+
+		if guard != nil {
+			if err := guard(current); err != nil {
+				return err
+			}
+		}
+
+		if !CanTransition(current.Status, to) {
+			return ErrInvalidTransition
+		}
+		from = current.Status
+
+		// status ikut dicek di WHERE agar dua transisi bersamaan tidak saling menimpa
+		updated, err := q.UpdateOrderStatus(ctx, database.UpdateOrderStatusParams{
+			ToStatus:   to,
+			ID:         orderID,
+			FromStatus: current.Status,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+		if updated == 0 {
+			return ErrStatusConflict
+		}
+
+		err = q.CreateOrderStatusHistory(ctx, database.CreateOrderStatusHistoryParams{
+			OrderID:    orderID,
+			FromStatus: database.NullOrderStatus{OrderStatus: current.Status, Valid: true},
+			ToStatus:   to,
+			ActorID:    actor.ID,
+			ActorRole:  actor.Role,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save order status history: %w", err)
+		}
+
+		if to == database.OrderStatusCancelled {
+			// promo dikunci sebelum item, sama seperti saat order dibuat, agar tidak deadlock
+			if err := q.ReleaseOrderPromotions(ctx, orderID); err != nil {
+				return fmt.Errorf("failed to release order promotions: %w", err)
+			}
+			restocked, err = restoreStock(ctx, q, orderID)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return StatusResponse{}, err
+	}
+
+	for _, merchantID := range restocked {
+		if err := items.InvalidateMerchantItemsCache(ctx, s.cache, merchantID); err != nil {
+			logger.WarnCtx(ctx, "Failed to invalidate merchant items cache", "merchantId", merchantID, "error", err)
+		}
+	}
+
+	logger.InfoCtx(ctx, "Order status changed", "orderId", orderID, "status", to, "actorRole", actor.Role)
+	s.events.Publish(ctx, orderID, OrderEvent{
+		Type:       EventStatusChanged,
+		Status:     string(to),
+		FromStatus: string(from),
+	})
+
+	return StatusResponse{OrderID: orderID.String(), Status: string(to)}, nil
+}
+
+// StatusHistory returns the status changes of an order whose merchants all
+// belong to adminID
+func (s *OrderService) StatusHistory(ctx context.Context, adminID, orderID uuid.UUID) (StatusHistoryResponse, error) {
+	if err := s.checkAdminOwnsOrder(ctx, adminID, orderID); err != nil {
+		return StatusHistoryResponse{}, err
+	}
+
+	current, err := s.db.Queries.GetOrderStatus(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return StatusHistoryResponse{}, ErrOrderNotFound
+		}
+		return StatusHistoryResponse{}, fmt.Errorf("failed to get order status: %w", err)
+	}
+
+	rows, err := s.db.Queries.ListOrderStatusHistory(ctx, orderID)
+	if err != nil {
+		return StatusHistoryResponse{}, fmt.Errorf("failed to list order status history: %w", err)
+	}
+
+	history := make([]StatusHistoryEntry, len(rows))
+	for i, row := range rows {
+		var from *string
+		if row.FromStatus.Valid {
+			status := string(row.FromStatus.OrderStatus)
+			from = &status
+		}
+		history[i] = StatusHistoryEntry{
+			FromStatus: from,
+			ToStatus:   string(row.ToStatus),
+			ActorID:    row.ActorID.String(),
+			ActorRole:  row.ActorRole,
+			CreatedAt:  row.CreatedAt.Format(time.RFC3339Nano),
+		}
+	}
+
+	return StatusHistoryResponse{
+		OrderID: orderID.String(),
+		Status:  string(current.Status),
+		History: history,
+	}, nil
+}
+
+// TrackUserOrder returns what the event stream needs to know about the
+// user's own order
+func (s *OrderService) TrackUserOrder(ctx context.Context, userID, orderID uuid.UUID) (database.GetOrderTrackingRow, error) {
+	order, err := s.db.Queries.GetOrderTracking(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return database.GetOrderTrackingRow{}, ErrOrderNotFound
+		}
+		return database.GetOrderTrackingRow{}, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.UserID != userID {
+		return database.GetOrderTrackingRow{}, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// restoreStock puts a cancelled order's quantities back on its items' stock
+// and returns the merchants whose items changed
+func restoreStock(ctx context.Context, q *database.Queries, orderID uuid.UUID) ([]uuid.UUID, error) {
+	quantities, err := q.ListOrderItemQuantities(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order items: %w", err)
+	}
+	if len(quantities) == 0 {
+		return nil, nil
+	}
+
+	itemIDs := make([]uuid.UUID, len(quantities))
+	deltas := make([]int, len(quantities))
+	seen := make(map[uuid.UUID]bool)
+	var merchantIDs []uuid.UUID
+	for i, row := range quantities {
+		itemIDs[i] = row.ItemID
+		deltas[i] = row.Quantity
+		if !seen[row.MerchantID] {
+			seen[row.MerchantID] = true
+			merchantIDs = append(merchantIDs, row.MerchantID)
+		}
+	}
+
+	// kunci dengan urutan yang sama seperti saat order dibuat agar tidak deadlock
+	if _, err := q.LockItemsForUpdate(ctx, itemIDs); err != nil {
+		return nil, fmt.Errorf("failed to lock items: %w", err)
+	}
+	err = q.AdjustItemStock(ctx, database.AdjustItemStockParams{
+		ItemIds: itemIDs,
+		Deltas:  deltas,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore item stock: %w", err)
+	}
+	return merchantIDs, nil
+}
+
+func (s *OrderService) checkAdminOwnsOrder(ctx context.Context, adminID, orderID uuid.UUID) error {
+	owns, err := s.db.Queries.IsOrderMerchantAdmin(ctx, database.IsOrderMerchantAdminParams{
+		OrderID: orderID,
+		AdminID: adminID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check order ownership: %w", err)
+	}
+	if owns {
+		return nil
+	}
+
+	// bedakan order yang tidak ada dari order milik admin lain
+	if _, err := s.db.Queries.GetOrderStatus(ctx, orderID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrOrderNotFound
+		}
+		return fmt.Errorf("failed to check order: %w", err)
+	}
+	return ErrForbidden
+}
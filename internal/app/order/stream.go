@@ -0,0 +1,214 @@
+package order
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// komentar kosong agar proxy dan klien tidak menutup koneksi idle
+	streamHeartbeatInterval = 15 * time.Second
+	streamETAInterval       = 30 * time.Second
+	// jeda reconnect yang disarankan ke EventSource, dalam milidetik
+	streamRetryMillis = 3000
+)
+
+// Events streams an order's events to its owner as Server-Sent Events.
+//
+// A new connection starts with a snapshot of the order. A reconnect that
+// sends Last-Event-ID gets the events it missed instead, or a fresh snapshot
+// when those are no longer kept. Live events arrive through Redis pub/sub,
+// ETA countdown ticks are computed here, and the stream ends once the order
+// is delivered or cancelled, or the server shuts down.
+func (h *OrderHandler) Events(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrOrderNotFound.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	order, err := h.orderService.TrackUserOrder(ctx, userID, orderID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	events := h.orderService.events
+	// subscribe sebelum replay supaya tidak ada event yang terlewat di antaranya
+	sub, err := events.Subscribe(ctx, orderID)
+	if errors.Is(err, ErrEventsClosed) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "server is shutting down"})
+		return
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to open order event stream", "order_id", orderID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open event stream"})
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	lastSent, err := h.resumeStream(c, &order, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to resume order event stream", "order_id", orderID, "error", err)
+		return
+	}
+	sendETA(c, order)
+	if isFinalStatus(order.Status) {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	eta := time.NewTicker(streamETAInterval)
+	defer eta.Stop()
+	messages := sub.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-events.Closing():
+			// klien menyambung ulang ke instance lain dengan Last-Event-ID
+			return
+		case <-heartbeat.C:
+			c.Writer.WriteString(": heartbeat\n\n")
+			c.Writer.Flush()
+		case <-eta.C:
+			sendETA(c, order)
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event OrderEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil || event.ID <= lastSent {
+				continue
+			}
+			sendEvent(c, event)
+			lastSent = event.ID
+
+			if event.Status != "" {
+				order.Status = database.OrderStatus(event.Status)
+			}
+			if isFinalStatus(order.Status) {
+				return
+			}
+		}
+	}
+}
+
+// resumeStream replays the events after lastEventID, falling back to a
+// snapshot for new connections or when some of them have been dropped. order
+// is brought up to date and the ID of the last event sent is returned.
+func (h *OrderHandler) resumeStream(c *gin.Context, order *database.GetOrderTrackingRow, lastEventID string) (int64, error) {
+	ctx := c.Request.Context()
+	events := h.orderService.events
+
+	latest, err := events.LastEventID(ctx, order.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	if after, err := strconv.ParseInt(lastEventID, 10, 64); err == nil && after <= latest {
+		missed, err := events.EventsAfter(ctx, order.ID, after)
+		if err != nil {
+			return 0, err
+		}
+		if after == latest || (len(missed) > 0 && missed[0].ID == after+1) {
+			for _, event := range missed {
+				sendEvent(c, event)
+				after = event.ID
+				if event.Status != "" {
+					order.Status = database.OrderStatus(event.Status)
+				}
+			}
+			return after, nil
+		}
+	}
+
+	// status terbaru diambil ulang karena event bisa saja terbit setelah order dibaca
+	current, err := h.orderService.TrackUserOrder(ctx, order.UserID, order.ID)
+	if err != nil {
+		return 0, err
+	}
+	snapshot := OrderEvent{
+		ID:                  latest,
+		Type:                EventSnapshot,
+		OrderID:             current.ID.String(),
+		Status:              string(current.Status),
+		EstimatedDeliveryAt: estimatedDeliveryAt(current).Format(time.RFC3339),
+		OccurredAt:          time.Now().Format(time.RFC3339Nano),
+	}
+	if current.CourierID != uuid.Nil {
+		snapshot.CourierID = current.CourierID.String()
+	}
+	sendEvent(c, snapshot)
+
+	*order = current
+	return latest, nil
+}
+
+func sendEvent(c *gin.Context, event OrderEvent) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(event.ID, 10),
+		Event: event.Type,
+		Retry: streamRetryMillis,
+		Data:  event,
+	})
+	c.Writer.Flush()
+}
+
+// sendETA sends the countdown to the estimated delivery time. ETA ticks carry
+// no ID so they never move the client's Last-Event-ID.
+func sendETA(c *gin.Context, order database.GetOrderTrackingRow) {
+	if isFinalStatus(order.Status) {
+		return
+	}
+	deliveryAt := estimatedDeliveryAt(order)
+	remaining := int64(time.Until(deliveryAt).Seconds())
+	if remaining < 0 {
+		remaining = 0
+	}
+	c.Render(-1, sse.Event{
+		Event: EventETA,
+		Data: ETAEvent{
+			OrderID:             order.ID.String(),
+			EstimatedDeliveryAt: deliveryAt.Format(time.RFC3339),
+			RemainingSeconds:    remaining,
+		},
+	})
+	c.Writer.Flush()
+}
+
+func estimatedDeliveryAt(order database.GetOrderTrackingRow) time.Time {
+	if order.ScheduledDeliveryAt != nil {
+		return *order.ScheduledDeliveryAt
+	}
+	return order.CreatedAt.Add(time.Duration(order.EstimatedDeliveryTimeInMinutes) * time.Minute)
+}
+
+func isFinalStatus(status database.OrderStatus) bool {
+	return status == database.OrderStatusDelivered || status == database.OrderStatusCancelled
+}
@@ -0,0 +1,53 @@
+package purchase
+
+import (
+	"math"
+
+	"belimang/internal/config"
+)
+
+// FeeEngine prices the delivery of an estimate on top of its items.
+type FeeEngine struct {
+	cfg config.FeeConfig
+}
+
+func NewFeeEngine(cfg config.FeeConfig) *FeeEngine {
+	return &FeeEngine{cfg: cfg}
+}
+
+// Calculate builds the price breakdown for a basket of itemsTotal delivered
+// along a route of routeDistanceMeters visiting stopCount merchants.
+func (e *FeeEngine) Calculate(itemsTotal int64, routeDistanceMeters float64, stopCount int) PriceBreakdown {
+	breakdown := PriceBreakdown{
+		ItemsTotal: itemsTotal,
+		BaseFee:    e.cfg.BaseFee,
+		ServiceFee: e.cfg.ServiceFee,
+	}
+
+	// dihitung per km yang sudah dimulai, jadi 1.2 km = 2 km
+	km := int64(math.Ceil(routeDistanceMeters / 1000))
+	breakdown.DistanceFee = km * e.cfg.PerKmFee
+
+	if stopCount > 1 {
+		breakdown.ExtraStopFee = int64(stopCount-1) * e.cfg.PerExtraStopFee
+	}
+
+	breakdown.SmallOrderFee = e.smallOrderFee(itemsTotal)
+
+	return breakdown
+}
+
+// Reprice replaces the item subtotal of an existing breakdown, keeping the
+// route-based fees that were quoted with it.
+func (e *FeeEngine) Reprice(breakdown PriceBreakdown, itemsTotal int64) PriceBreakdown {
+	breakdown.ItemsTotal = itemsTotal
+	breakdown.SmallOrderFee = e.smallOrderFee(itemsTotal)
+	return breakdown
+}
+
+func (e *FeeEngine) smallOrderFee(itemsTotal int64) int64 {
+	if itemsTotal < e.cfg.SmallOrderThreshold {
+		return e.cfg.SmallOrderFee
+	}
+	return 0
+}
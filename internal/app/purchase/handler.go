@@ -1,17 +1,22 @@
 package purchase
 
 import (
-	"context"
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
+	logger "belimang/internal/pkg/logging"
+	"belimang/internal/pkg/utils"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 )
 
+// maxNearbyLimit is the largest page of nearby merchants a client may request
+const maxNearbyLimit = 100
+
 type PurchaseHandler struct {
 	purchaseService *PurchaseService
 	validate        *validator.Validate
@@ -58,7 +63,17 @@ func (h *PurchaseHandler) Estimate(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		case "coordinates too far":
 			c.JSON(http.StatusBadRequest, gin.H{"error": "coordinates too far"})
-		case "exactly one order must have isStartingPoint=true",
+		case "scheduled delivery time is too soon", "scheduled delivery time is too far ahead":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "merchant closed":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "merchant closed"})
+		case "item unavailable", "invalid item options":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "voucher not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "voucher not found"})
+		case "voucher is not applicable to this order", "voucher usage limit reached":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "at most one order can have isStartingPoint=true",
 			"orders cannot be empty",
 			"starting point not found":
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
@@ -102,11 +117,21 @@ func (h *PurchaseHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.purchaseService.CreateOrderByEstimateId(c, userUUID, estimateID)
+	resp, err := h.purchaseService.CreateOrderByEstimateId(c, userUUID, estimateID, req.Reprice)
 	if err != nil {
-		switch err.Error() {
-		case "estimate not found":
+		switch {
+		case errors.Is(err, ErrEstimateNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "estimate not found"})
+		case errors.Is(err, ErrEstimateExpired):
+			c.JSON(http.StatusGone, gin.H{"error": "estimate expired"})
+		case errors.Is(err, ErrOrderAlreadyExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "order already exists for estimate"})
+		case errors.Is(err, ErrScheduleTooSoon):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrMerchantClosed):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "merchant closed"})
+		case errors.Is(err, ErrPromotionUnavailable), errors.Is(err, ErrItemOutOfStock):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -141,22 +166,68 @@ func (h *PurchaseHandler) GetMerchantsNearbyHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "latitude must be [-90,90], longitude [-180,180]"})
 		return
 	}
-	// Call service
-	ctx := context.Background() // or use c.Request.Context() if you have timeouts/tracing
-	
-    name := c.DefaultQuery("name", "")
-	fmt.Print("name is ", name)
-	response, err := h.purchaseService.GetMerchantsNearby(ctx, lat, lng, name)
-	if err != nil {
-		// Log the error internally
-		// logger.Error("Failed to get nearby merchants", "error", err)
 
+	filter := NearbyMerchantFilter{
+		Lat:    lat,
+		Lng:    lng,
+		Name:   c.Query("name"),
+		Limit:  5,
+		Offset: 0,
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		l, err := strconv.ParseInt(limitStr, 10, 32)
+		if err == nil && l < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must not be negative"})
+			return
+		}
+		if err == nil && l > 0 {
+			filter.Limit = int(min(l, maxNearbyLimit))
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		o, err := strconv.ParseInt(offsetStr, 10, 32)
+		if err == nil && o < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must not be negative"})
+			return
+		}
+		if err == nil {
+			filter.Offset = int(o)
+		}
+	}
+
+	// merchantId atau merchantCategory yang tidak valid tidak akan cocok dengan merchant apa pun
+	if merchantIDStr := c.Query("merchantId"); merchantIDStr != "" {
+		merchantID, err := uuid.Parse(merchantIDStr)
+		if err != nil {
+			respondEmptyNearby(c, filter)
+			return
+		}
+		filter.MerchantID = merchantID
+	}
+	if category := c.Query("merchantCategory"); category != "" {
+		if !utils.IsMerchantCategory(category) {
+			respondEmptyNearby(c, filter)
+			return
+		}
+		filter.MerchantCategory = category
+	}
+
+	response, err := h.purchaseService.GetMerchantsNearby(c.Request.Context(), filter)
+	if err != nil {
+		logger.ErrorCtx(c.Request.Context(), "Failed to get nearby merchants", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to fetch nearby merchants",
 		})
 		return
 	}
 
-	// Return success response
 	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
+
+func respondEmptyNearby(c *gin.Context, filter NearbyMerchantFilter) {
+	c.JSON(http.StatusOK, GetMerchantsNearbyResponse{
+		Data: []MerchantWithItemsResponse{},
+		Meta: PaginationMeta{Limit: filter.Limit, Offset: filter.Offset, Total: 0},
+	})
+}
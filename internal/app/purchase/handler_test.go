@@ -0,0 +1,27 @@
+package purchase
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetMerchantsNearbyRejectsNegativePaging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewPurchaseHandler(nil, nil)
+	router.GET("/merchants/nearby/:coords", handler.GetMerchantsNearbyHandler)
+
+	for _, query := range []string{"limit=-1", "offset=-5"} {
+		t.Run(query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/merchants/nearby/-6.2,106.8?"+query, nil)
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -1,5 +1,13 @@
 package purchase
 
+import (
+	"time"
+
+	"belimang/internal/app/items"
+
+	"github.com/google/uuid"
+)
+
 type UserLocation struct {
 	Lat  float64 `json:"lat" validate:"required,gte=-90,lte=90"`
 	Long float64 `json:"long" validate:"required,gte=-180,lte=180"`
@@ -8,10 +16,14 @@ type UserLocation struct {
 type OrderItem struct {
 	ItemID   string `json:"itemId" validate:"required"`
 	Quantity int    `json:"quantity" validate:"required,min=1"`
+	// OptionIDs are the chosen options across all of the item's option groups
+	OptionIDs []string `json:"optionIds" validate:"max=50"`
 }
 
 type Order struct {
-	MerchantID      string      `json:"merchantId" validate:"required"`
+	MerchantID string `json:"merchantId" validate:"required"`
+	// IsStartingPoint is optional: when no order sets it, the merchant giving
+	// the fastest delivery is picked as the starting point
 	IsStartingPoint bool        `json:"isStartingPoint"`
 	Items           []OrderItem `json:"items" validate:"dive"`
 }
@@ -19,20 +31,98 @@ type Order struct {
 type EstimateRequest struct {
 	UserLocation UserLocation `json:"userLocation" validate:"required"`
 	Orders       []Order      `json:"orders" validate:"required,min=1,dive"`
+	// ScheduledDeliveryAt requests delivery at a later time (RFC 3339); nil means as soon as possible
+	ScheduledDeliveryAt *time.Time `json:"scheduledDeliveryAt"`
+	VoucherCode         string     `json:"voucherCode" validate:"omitempty,max=50"`
 }
 
 type EstimateResponse struct {
-	TotalPrice                     int64  `json:"totalPrice"`
-	EstimatedDeliveryTimeInMinutes int    `json:"estimatedDeliveryTimeInMinutes"`
-	CalculatedEstimateId           string `json:"calculatedEstimateId"`
+	TotalPrice                     int64          `json:"totalPrice"`
+	EstimatedDeliveryTimeInMinutes int            `json:"estimatedDeliveryTimeInMinutes"`
+	CalculatedEstimateId           string         `json:"calculatedEstimateId"`
+	ExpiresAt                      string         `json:"expiresAt"`
+	ScheduledDeliveryAt            *string        `json:"scheduledDeliveryAt,omitempty"`
+	Route                          DeliveryRoute  `json:"route"`
+	StartingPointMerchantID        string         `json:"startingPointMerchantId"`
+	StartingPointAutoSelected      bool           `json:"startingPointAutoSelected"` // false when the client chose it
+	PriceBreakdown                 PriceBreakdown `json:"priceBreakdown"`
+	Discounts                      []DiscountLine `json:"discounts"`
+}
+
+// PriceBreakdown splits totalPrice into the item subtotal, each fee and the
+// promotion discount
+type PriceBreakdown struct {
+	ItemsTotal    int64 `json:"itemsTotal"`
+	BaseFee       int64 `json:"baseFee"`
+	DistanceFee   int64 `json:"distanceFee"`
+	ExtraStopFee  int64 `json:"extraStopFee"`
+	SmallOrderFee int64 `json:"smallOrderFee"`
+	ServiceFee    int64 `json:"serviceFee"`
+	Discount      int64 `json:"discount"` // sum of the discount lines
+}
+
+// Total is the amount the user pays, never below zero
+func (b PriceBreakdown) Total() int64 {
+	return max(b.ItemsTotal+b.BaseFee+b.DistanceFee+b.ExtraStopFee+b.SmallOrderFee+b.ServiceFee-b.Discount, 0)
+}
+
+// DeliveryFees is the part of the price a free delivery promotion can waive
+func (b PriceBreakdown) DeliveryFees() int64 {
+	return b.BaseFee + b.DistanceFee + b.ExtraStopFee
+}
+
+// DiscountLine is one promotion applied to an estimate
+type DiscountLine struct {
+	PromotionID string `json:"promotionId"`
+	Code        string `json:"code,omitempty"` // empty for automatic promotions
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Amount      int64  `json:"amount"`
+}
+
+// DeliveryRoute is the ordered list of merchants the courier visits,
+// followed by the final leg to the user.
+type DeliveryRoute struct {
+	Stops    []RouteStop `json:"stops"`
+	FinalLeg RouteLeg    `json:"finalLeg"`
+}
+
+type RouteStop struct {
+	Sequence                 int      `json:"sequence"`
+	MerchantID               string   `json:"merchantId"`
+	Location                 Location `json:"location"`
+	IsStartingPoint          bool     `json:"isStartingPoint"`
+	Subtotal                 int64    `json:"subtotal"`
+	Leg                      RouteLeg `json:"leg"` // leg arriving at this stop, zero for the starting point
+	PreparationTimeInMinutes int      `json:"preparationTimeInMinutes"`
+	WaitTimeInMinutes        int      `json:"waitTimeInMinutes"` // courier waiting for the food at this stop
+}
+
+type RouteLeg struct {
+	DistanceInMeters       float64 `json:"distanceInMeters"`
+	EstimatedTimeInMinutes int     `json:"estimatedTimeInMinutes"`
 }
 
 type CreateOrderRequest struct {
 	CalculatedEstimateId string `json:"calculatedEstimateId" validate:"required"`
+	// Reprice recomputes the item prices at order time instead of honouring the quoted total
+	Reprice bool `json:"reprice"`
 }
 
 type CreateOrderResponse struct {
-	OrderId string `json:"orderId"`
+	OrderId   string     `json:"orderId"`
+	Repricing *Repricing `json:"repricing,omitempty"`
+	// ReleaseAt is when a scheduled order is passed on to the merchants
+	ReleaseAt *string `json:"releaseAt,omitempty"`
+}
+
+// Repricing reports how the order total moved from the estimate's quote
+type Repricing struct {
+	EstimatedTotalPrice int64          `json:"estimatedTotalPrice"`
+	TotalPrice          int64          `json:"totalPrice"`
+	Difference          int64          `json:"difference"` // TotalPrice - EstimatedTotalPrice
+	PriceBreakdown      PriceBreakdown `json:"priceBreakdown"`
+	Discounts           []DiscountLine `json:"discounts"` // recomputed for the current prices
 }
 
 type MerchantPoint struct {
@@ -41,6 +131,7 @@ type MerchantPoint struct {
 	IsStart    bool
 	Order      Order
 }
+
 // models :
 type MerchantWithItemsResponse struct {
 	Merchant MerchantInfo `json:"merchant"`
@@ -53,23 +144,42 @@ type MerchantInfo struct {
 	MerchantCategory string   `json:"merchantCategory"`
 	ImageUrl         string   `json:"imageUrl"`
 	Location         Location `json:"location"`
-	CreatedAt        string   `json:"createdAt"` // ISO 8601 with nanoseconds
+	CreatedAt        string   `json:"createdAt"`        // ISO 8601 with nanoseconds
+	DistanceInMeters float64  `json:"distanceInMeters"` // dari lokasi user
+	IsOpen           bool     `json:"isOpen"`
 }
 
 type ItemInfo struct {
-	ItemID          string `json:"itemId"`
-	Name            string `json:"name"`
-	ProductCategory string `json:"productCategory"`
-	Price           int64  `json:"price"`
-	ImageUrl        string `json:"imageUrl"`
-	CreatedAt       string `json:"createdAt"` // ISO 8601 with nanoseconds
+	ItemID          string            `json:"itemId"`
+	Name            string            `json:"name"`
+	ProductCategory string            `json:"productCategory"`
+	Price           int64             `json:"price"`
+	ImageUrl        string            `json:"imageUrl"`
+	CreatedAt       string            `json:"createdAt"` // ISO 8601 with nanoseconds
+	OptionGroups    []OptionGroupInfo `json:"optionGroups"`
 }
 
+// OptionGroupInfo is one choice the user makes for an item, e.g. its size
+type OptionGroupInfo = items.OptionGroupResponse
+
+type OptionInfo = items.OptionResponse
+
 type Location struct {
 	Lat  float64 `json:"lat"`
 	Long float64 `json:"long"`
 }
 
+// NearbyMerchantFilter narrows GET /merchants/nearby; zero values mean no filter
+type NearbyMerchantFilter struct {
+	Lat              float64
+	Lng              float64
+	Name             string
+	MerchantID       uuid.UUID
+	MerchantCategory string
+	Limit            int
+	Offset           int
+}
+
 type GetMerchantsNearbyResponse struct {
 	Data []MerchantWithItemsResponse `json:"data"`
 	Meta PaginationMeta              `json:"meta"`
@@ -79,4 +189,4 @@ type PaginationMeta struct {
 	Limit  int `json:"limit"`
 	Offset int `json:"offset"`
 	Total  int `json:"total"`
-}
\ No newline at end of file
+}
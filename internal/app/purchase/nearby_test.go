@@ -0,0 +1,146 @@
+package purchase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"belimang/internal/config"
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeNearbyDB answers the nearby queries from merchants at fixed distances
+// and records the radius the total was counted at
+type fakeNearbyDB struct {
+	distances   []float64
+	countRadius float64
+}
+
+func (db *fakeNearbyDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, fmt.Errorf("unexpected exec %s", queryName(sql))
+}
+
+func (db *fakeNearbyDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	switch queryName(sql) {
+	case "ListNearbyMerchants":
+		maxDistance, limit, offset := args[3].(float64), int(args[7].(int32)), int(args[8].(int32))
+		rows := &fakeRows{}
+		for _, d := range db.distances {
+			if d > maxDistance {
+				continue
+			}
+			if offset > 0 {
+				offset--
+				continue
+			}
+			if len(rows.values) == limit {
+				break
+			}
+			rows.values = append(rows.values, fields(database.ListNearbyMerchantsRow{ID: uuid.New(), CreatedAt: time.Now(), DistanceMeters: d}))
+		}
+		return rows, nil
+	case "ListMerchantTimezones", "ListMerchantOpeningHours", "ListMerchantOpeningExceptions", "ListItemsByMerchantIds", "ListItemOptions":
+		return &fakeRows{}, nil
+	}
+	return nil, fmt.Errorf("unexpected query %s", queryName(sql))
+}
+
+func (db *fakeNearbyDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	if queryName(sql) == "CountNearbyMerchants" {
+		db.countRadius = args[3].(float64)
+		var count int64
+		for _, d := range db.distances {
+			if d <= db.countRadius {
+				count++
+			}
+		}
+		return &fakeRows{values: [][]any{{count}}}
+	}
+	return &fakeRows{err: fmt.Errorf("unexpected query %s", queryName(sql))}
+}
+
+func (db *fakeNearbyDB) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, fmt.Errorf("unexpected copy into %v", tableName)
+}
+
+func TestGetMerchantsNearbyCountsAtFinalRadius(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	tests := []struct {
+		name        string
+		limit       int
+		wantRadius  float64
+		wantTotal   int
+		wantResults int
+	}{
+		{"page full in the first pass", 2, 3000, 2, 2},
+		{"page full after expanding", 3, 6000, 3, 3},
+		{"page never full", 10, 20000, 4, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeNearbyDB{distances: []float64{1000, 2000, 5000, 15000, 30000}}
+			s := NewPurchaseService(database.New(db), nil, nil, config.DeliveryConfig{NearbyMaxRadiusMeters: 20000}, nil)
+
+			resp, err := s.GetMerchantsNearby(context.Background(), NearbyMerchantFilter{Limit: tt.limit})
+			if err != nil {
+				t.Fatalf("GetMerchantsNearby() error = %v", err)
+			}
+			if db.countRadius != tt.wantRadius {
+				t.Errorf("counted at %v m, want %v m", db.countRadius, tt.wantRadius)
+			}
+			if resp.Meta.Total != tt.wantTotal || len(resp.Data) != tt.wantResults {
+				t.Errorf("total = %d with %d results, want %d with %d", resp.Meta.Total, len(resp.Data), tt.wantTotal, tt.wantResults)
+			}
+		})
+	}
+}
+
+// BenchmarkGetMerchantsNearby runs the nearby lookup against a database
+// seeded with seeds/02_benchmark_merchants.sql:
+//
+//	make seed-bench bench-nearby-go
+func BenchmarkGetMerchantsNearby(b *testing.B) {
+	dbURL := os.Getenv("NEARBY_BENCH_DATABASE_URL")
+	if dbURL == "" {
+		b.Skip("NEARBY_BENCH_DATABASE_URL is not set")
+	}
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	ctx := context.Background()
+	db, err := database.NewDatabase(ctx, dbURL)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(db.Close)
+	s := NewPurchaseService(db.Queries, db, nil, config.DeliveryConfig{NearbyMaxRadiusMeters: 20000}, nil)
+
+	// pusat Jakarta (padat) dan pinggir dataset (ring search harus melebar)
+	benchmarks := []struct {
+		name   string
+		filter NearbyMerchantFilter
+	}{
+		{"central", NearbyMerchantFilter{Lat: -6.2000, Lng: 106.8167, Limit: 5}},
+		{"central deep page", NearbyMerchantFilter{Lat: -6.2000, Lng: 106.8167, Limit: 5, Offset: 500}},
+		{"central by name", NearbyMerchantFilter{Lat: -6.2000, Lng: 106.8167, Name: "Bench Merchant 4", Limit: 5}},
+		{"edge of dataset", NearbyMerchantFilter{Lat: -6.4100, Lng: 106.6400, Limit: 5}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := s.GetMerchantsNearby(ctx, bm.filter); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package purchase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"belimang/internal/infrastructure/database"
+	"belimang/internal/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+var ErrMerchantClosed = errors.New("merchant closed")
+
+// loadOpeningSchedules fetches the opening schedules of the given merchants.
+// Only exceptions from two days ago onwards are read, which still covers
+// "yesterday" in every timezone for windows running past midnight.
+func (s *PurchaseService) loadOpeningSchedules(ctx context.Context, merchantIDs []uuid.UUID) (map[uuid.UUID]utils.OpeningSchedule, error) {
+	timezones, err := s.queries.ListMerchantTimezones(ctx, merchantIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch merchant timezones: %w", err)
+	}
+
+	schedules := make(map[uuid.UUID]utils.OpeningSchedule, len(timezones))
+	for _, tz := range timezones {
+		loc, err := time.LoadLocation(tz.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone for merchant %s: %w", tz.ID, err)
+		}
+		schedules[tz.ID] = utils.OpeningSchedule{
+			Location:   loc,
+			Weekly:     make(map[time.Weekday][]utils.OpeningWindow),
+			Exceptions: make(map[string]utils.OpeningException),
+		}
+	}
+
+	hours, err := s.queries.ListMerchantOpeningHours(ctx, merchantIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch merchant opening hours: %w", err)
+	}
+	for _, h := range hours {
+		// merchant yang sudah dihapus tidak punya jadwal
+		schedule, ok := schedules[h.MerchantID]
+		if !ok {
+			continue
+		}
+		day := time.Weekday(h.DayOfWeek)
+		schedule.Weekly[day] = append(schedule.Weekly[day], utils.OpeningWindow{
+			OpenMinute:  h.OpenMinute,
+			CloseMinute: h.CloseMinute,
+		})
+	}
+
+	exceptions, err := s.queries.ListMerchantOpeningExceptions(ctx, database.ListMerchantOpeningExceptionsParams{
+		MerchantIds: merchantIDs,
+		FromDate:    time.Now().UTC().AddDate(0, 0, -2).Format(utils.DateLayout),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch merchant opening exceptions: %w", err)
+	}
+	for _, ex := range exceptions {
+		schedule, ok := schedules[ex.MerchantID]
+		if !ok {
+			continue
+		}
+		schedule.Exceptions[ex.ExceptionDate] = utils.OpeningException{
+			Closed: ex.IsClosed,
+			Window: utils.OpeningWindow{OpenMinute: ex.OpenMinute, CloseMinute: ex.CloseMinute},
+		}
+	}
+
+	return schedules, nil
+}
+
+// checkMerchantsOpen returns ErrMerchantClosed unless every merchant is open at
+// t. A merchant deleted since the estimate was quoted counts as closed.
+func (s *PurchaseService) checkMerchantsOpen(ctx context.Context, merchantIDs []uuid.UUID, t time.Time) error {
+	schedules, err := s.loadOpeningSchedules(ctx, merchantIDs)
+	if err != nil {
+		return err
+	}
+	for _, id := range merchantIDs {
+		if schedule, ok := schedules[id]; !ok || !schedule.IsOpenAt(t) {
+			return ErrMerchantClosed
+		}
+	}
+	return nil
+}
+
+// preparationStart is when the merchants start working on an order: now, or
+// for a scheduled delivery the moment it is released to them
+func preparationStart(scheduledDeliveryAt *time.Time, etaMinutes int) time.Time {
+	if scheduledDeliveryAt == nil {
+		return time.Now()
+	}
+	return scheduledDeliveryAt.Add(-time.Duration(etaMinutes) * time.Minute)
+}
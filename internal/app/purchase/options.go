@@ -0,0 +1,85 @@
+package purchase
+
+import (
+	"context"
+	"fmt"
+
+	"belimang/internal/infrastructure/database"
+
+	"github.com/google/uuid"
+)
+
+// SelectedOption is one chosen item option as priced on the estimate
+type SelectedOption struct {
+	OptionID   uuid.UUID
+	GroupName  string
+	OptionName string
+	PriceDelta int64
+}
+
+// itemOptionCatalog holds the option groups of the items in a request
+type itemOptionCatalog struct {
+	groups  map[uuid.UUID][]database.ListItemOptionsRow // per item, first row of each group
+	options map[uuid.UUID]database.ListItemOptionsRow   // by option id
+}
+
+func (s *PurchaseService) loadItemOptions(ctx context.Context, itemIDs []uuid.UUID) (itemOptionCatalog, error) {
+	rows, err := s.queries.ListItemOptions(ctx, itemIDs)
+	if err != nil {
+		return itemOptionCatalog{}, fmt.Errorf("failed to fetch item options: %w", err)
+	}
+
+	catalog := itemOptionCatalog{
+		groups:  make(map[uuid.UUID][]database.ListItemOptionsRow),
+		options: make(map[uuid.UUID]database.ListItemOptionsRow, len(rows)),
+	}
+	// baris terurut per item lalu per grup
+	var lastGroupID uuid.UUID
+	for _, row := range rows {
+		if row.GroupID != lastGroupID {
+			catalog.groups[row.ItemID] = append(catalog.groups[row.ItemID], row)
+			lastGroupID = row.GroupID
+		}
+		catalog.options[row.OptionID] = row
+	}
+	return catalog, nil
+}
+
+// selectOptions checks the options chosen for one item against the item's
+// groups and returns them with their price per unit. Every group must get
+// between its minimum and maximum number of selections.
+func (c itemOptionCatalog) selectOptions(itemID uuid.UUID, optionIDs []string) ([]SelectedOption, int64, error) {
+	selected := make([]SelectedOption, 0, len(optionIDs))
+	perGroup := make(map[uuid.UUID]int)
+	seen := make(map[uuid.UUID]bool, len(optionIDs))
+	price := int64(0)
+
+	for _, raw := range optionIDs {
+		optionID, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, 0, ErrInvalidItemOptions
+		}
+		opt, exists := c.options[optionID]
+		if !exists || opt.ItemID != itemID || seen[optionID] {
+			return nil, 0, ErrInvalidItemOptions
+		}
+		seen[optionID] = true
+		perGroup[opt.GroupID]++
+
+		selected = append(selected, SelectedOption{
+			OptionID:   opt.OptionID,
+			GroupName:  opt.GroupName,
+			OptionName: opt.OptionName,
+			PriceDelta: opt.PriceDelta,
+		})
+		price += opt.PriceDelta
+	}
+
+	for _, group := range c.groups[itemID] {
+		count := perGroup[group.GroupID]
+		if count < group.MinSelections || count > group.MaxSelections {
+			return nil, 0, ErrInvalidItemOptions
+		}
+	}
+	return selected, price, nil
+}
@@ -0,0 +1,231 @@
+package purchase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"belimang/internal/app/order"
+	"belimang/internal/config"
+	"belimang/internal/infrastructure/cache"
+	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeOrderDB answers the queries of CreateOrderByEstimateId from in-memory
+// estimates and item stock
+type fakeOrderDB struct {
+	merchantID uuid.UUID
+	estimates  map[uuid.UUID]database.Estimates
+	details    map[uuid.UUID][]database.GetEstimateOrderDetailsRow
+	stock      map[uuid.UUID]int
+}
+
+func queryName(sql string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(sql, "-- name: "), " ")
+	return name
+}
+
+// fields returns the fields of a generated row struct in scan order
+func fields(v any) []any {
+	rv := reflect.ValueOf(v)
+	values := make([]any, rv.NumField())
+	for i := range values {
+		values[i] = rv.Field(i).Interface()
+	}
+	return values
+}
+
+func (db *fakeOrderDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &fakeTx{db: db}, nil
+}
+
+func (db *fakeOrderDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	switch queryName(sql) {
+	case "AdjustItemStock":
+		itemIDs, deltas := args[0].([]uuid.UUID), args[1].([]int)
+		for i, id := range itemIDs {
+			db.stock[id] += deltas[i]
+		}
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case "CreateOrderStatusHistory", "CopyEstimateOrderItemOptions":
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	}
+	return pgconn.CommandTag{}, fmt.Errorf("unexpected exec %s", queryName(sql))
+}
+
+func (db *fakeOrderDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	switch queryName(sql) {
+	case "GetEstimateOrderIds":
+		return &fakeRows{values: [][]any{{uuid.New(), db.merchantID}}}, nil
+	case "ListMerchantTimezones":
+		return &fakeRows{values: [][]any{{db.merchantID, "Asia/Jakarta"}}}, nil
+	case "ListMerchantOpeningHours", "ListMerchantOpeningExceptions", "ListEstimateDiscounts":
+		return &fakeRows{}, nil
+	case "GetEstimateOrderDetails":
+		rows := &fakeRows{}
+		for _, d := range db.details[args[0].(uuid.UUID)] {
+			rows.values = append(rows.values, fields(d))
+		}
+		return rows, nil
+	case "LockItemsForUpdate":
+		rows := &fakeRows{}
+		for _, id := range args[0].([]uuid.UUID) {
+			stock := db.stock[id]
+			rows.values = append(rows.values, []any{id, &stock, true})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("unexpected query %s", queryName(sql))
+}
+
+func (db *fakeOrderDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	switch queryName(sql) {
+	case "GetEstimateById":
+		estimate, ok := db.estimates[args[0].(uuid.UUID)]
+		if !ok {
+			return &fakeRows{err: pgx.ErrNoRows}
+		}
+		return &fakeRows{values: [][]any{fields(estimate)}}
+	case "CreateOrderFromEstimate":
+		estimate := db.estimates[args[0].(uuid.UUID)]
+		return &fakeRows{values: [][]any{fields(database.CreateOrderFromEstimateRow{
+			ID:                             uuid.New(),
+			TotalPrice:                     estimate.TotalPrice,
+			EstimatedDeliveryTimeInMinutes: estimate.EstimatedDeliveryTimeInMinutes,
+		})}}
+	case "CreateOrderMerchant", "CreateOrderItem":
+		return &fakeRows{values: [][]any{{uuid.New()}}}
+	}
+	return &fakeRows{err: fmt.Errorf("unexpected query %s", queryName(sql))}
+}
+
+func (db *fakeOrderDB) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, fmt.Errorf("unexpected copy into %v", tableName)
+}
+
+// fakeTx runs its queries straight against the fake database
+type fakeTx struct {
+	pgx.Tx
+	db *fakeOrderDB
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tx.db.Exec(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.db.Query(ctx, sql, args...)
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return tx.db.QueryRow(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error   { return nil }
+func (tx *fakeTx) Rollback(ctx context.Context) error { return nil }
+
+// fakeRows serves both as pgx.Rows and, for single row queries, pgx.Row
+type fakeRows struct {
+	pgx.Rows
+	values [][]any
+	next   int
+	err    error
+}
+
+func (r *fakeRows) Next() bool {
+	r.next++
+	return r.err == nil && r.next <= len(r.values)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.next == 0 && !r.Next() {
+		return pgx.ErrNoRows
+	}
+	for i, v := range r.values[r.next-1] {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func (r *fakeRows) Close()     {}
+func (r *fakeRows) Err() error { return r.err }
+
+func TestCreateOrderLastUnitOrderedTwice(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	gin.SetMode(gin.TestMode)
+	redis := miniredis.RunT(t)
+	redisCache := cache.NewRedisCache(config.CacheConfig{RedisUrl: redis.Addr()})
+	t.Cleanup(func() { redisCache.Close() })
+
+	itemID := uuid.New()
+	db := &fakeOrderDB{
+		merchantID: uuid.New(),
+		estimates:  make(map[uuid.UUID]database.Estimates),
+		details:    make(map[uuid.UUID][]database.GetEstimateOrderDetailsRow),
+		stock:      map[uuid.UUID]int{itemID: 1},
+	}
+	// dua user mendapat estimate untuk unit terakhir yang sama
+	newEstimate := func(userID uuid.UUID) uuid.UUID {
+		estimate := database.Estimates{
+			ID:                             uuid.New(),
+			UserID:                         userID,
+			TotalPrice:                     25000,
+			EstimatedDeliveryTimeInMinutes: 30,
+			ExpiresAt:                      time.Now().Add(time.Hour),
+		}
+		db.estimates[estimate.ID] = estimate
+		db.details[estimate.ID] = []database.GetEstimateOrderDetailsRow{{
+			MerchantID:          db.merchantID,
+			IsStartingPoint:     true,
+			EstimateOrderItemID: uuid.New(),
+			ItemID:              itemID,
+			Quantity:            1,
+			UnitPrice:           20000,
+		}}
+		return estimate.ID
+	}
+
+	store := database.NewDB(db)
+	handler := NewPurchaseHandler(NewPurchaseService(store.Queries, store, redisCache, config.DeliveryConfig{}, order.NewEventPublisher(redisCache)), nil)
+	createOrder := func(userID, estimateID uuid.UUID) int {
+		router := gin.New()
+		router.POST("/users/orders", func(c *gin.Context) { c.Set("user_id", userID.String()) }, handler.CreateOrder)
+		w := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"calculatedEstimateId":%q}`, estimateID)
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/orders", strings.NewReader(body)))
+		return w.Code
+	}
+
+	first, second := uuid.New(), uuid.New()
+	firstEstimate, secondEstimate := newEstimate(first), newEstimate(second)
+	if code := createOrder(first, firstEstimate); code != http.StatusCreated {
+		t.Fatalf("first order status = %d, want %d", code, http.StatusCreated)
+	}
+	if code := createOrder(second, secondEstimate); code != http.StatusConflict {
+		t.Errorf("second order status = %d, want %d", code, http.StatusConflict)
+	}
+	if db.stock[itemID] != 0 {
+		t.Errorf("stock = %d, want 0", db.stock[itemID])
+	}
+	// hanya order pertama yang mengubah stok
+	if version, _ := redis.Get(fmt.Sprintf(cache.ItemsVersionKey, db.merchantID)); version != "1" {
+		t.Errorf("items cache version = %q, want 1", version)
+	}
+}
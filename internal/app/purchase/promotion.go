@@ -0,0 +1,212 @@
+package purchase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"belimang/internal/infrastructure/database"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrVoucherNotFound      = errors.New("voucher not found")
+	ErrVoucherNotApplicable = errors.New("voucher is not applicable to this order")
+	ErrVoucherUsedUp        = errors.New("voucher usage limit reached")
+)
+
+// basketLine is one ordered item as seen by the promotion rules
+type basketLine struct {
+	MerchantID      uuid.UUID
+	ProductCategory string
+	Amount          int64 // price * quantity
+}
+
+// appliedPromotion is a discount line together with the promotion it redeems
+type appliedPromotion struct {
+	PromotionID uuid.UUID
+	Line        DiscountLine
+}
+
+// applyPromotions picks the discounts for a basket: the voucher matching code
+// (if any) plus the single best automatic promotion. An unusable voucher is
+// an error, an unusable automatic promotion is simply skipped.
+//
+// Item discounts together never exceed the items total and free delivery
+// never exceeds the delivery fees, so stacking cannot make the total negative.
+func (s *PurchaseService) applyPromotions(ctx context.Context, userID uuid.UUID, code string, basket []basketLine, breakdown PriceBreakdown) ([]appliedPromotion, error) {
+	code = strings.TrimSpace(code)
+
+	promotions, err := s.queries.ListApplicablePromotions(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch promotions: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(promotions))
+	for i, p := range promotions {
+		ids[i] = p.ID
+	}
+	redemptions := make(map[uuid.UUID]int64)
+	if len(ids) > 0 {
+		rows, err := s.queries.CountUserPromotionRedemptions(ctx, database.CountUserPromotionRedemptionsParams{
+			UserID:       userID,
+			PromotionIds: ids,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count promotion redemptions: %w", err)
+		}
+		for _, row := range rows {
+			redemptions[row.PromotionID] = row.Redemptions
+		}
+	}
+
+	var voucher, bestAutomatic *appliedPromotion
+	for _, p := range promotions {
+		amount, err := promotionDiscount(p, basket, breakdown, redemptions[p.ID])
+
+		if p.Code != "" {
+			if err != nil {
+				return nil, err
+			}
+			voucher = newAppliedPromotion(p, amount)
+			continue
+		}
+		if err == nil && (bestAutomatic == nil || amount > bestAutomatic.Line.Amount) {
+			bestAutomatic = newAppliedPromotion(p, amount)
+		}
+	}
+	if code != "" && voucher == nil {
+		return nil, ErrVoucherNotFound
+	}
+
+	return stackPromotions(breakdown, voucher, bestAutomatic), nil
+}
+
+// repricePromotions recomputes the estimate's discounts for the basket at its
+// current prices. A promotion the basket no longer qualifies for is dropped;
+// one that ran out fails the order like it would when redeeming.
+func (s *PurchaseService) repricePromotions(ctx context.Context, estimateID uuid.UUID, basket []basketLine, breakdown PriceBreakdown) ([]appliedPromotion, error) {
+	discounts, err := s.queries.ListEstimateDiscounts(ctx, estimateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get estimate discounts: %w", err)
+	}
+	if len(discounts) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, len(discounts))
+	for i, d := range discounts {
+		ids[i] = d.PromotionID
+	}
+	rows, err := s.queries.ListPromotionsByIds(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch promotions: %w", err)
+	}
+	promotions := make(map[uuid.UUID]database.ListApplicablePromotionsRow, len(rows))
+	for _, row := range rows {
+		promotions[row.ID] = database.ListApplicablePromotionsRow(row)
+	}
+
+	// urutan estimate dipertahankan: voucher dulu, lalu promo otomatis
+	quoted := make([]*appliedPromotion, 0, len(discounts))
+	for _, d := range discounts {
+		p, ok := promotions[d.PromotionID]
+		if !ok {
+			return nil, ErrPromotionUnavailable
+		}
+		// batas per user dicek ulang saat redeem di dalam transaksi order
+		amount, err := promotionDiscount(p, basket, breakdown, 0)
+		if errors.Is(err, ErrVoucherUsedUp) {
+			return nil, ErrPromotionUnavailable
+		}
+		if err != nil {
+			continue
+		}
+		quoted = append(quoted, newAppliedPromotion(p, amount))
+	}
+	return stackPromotions(breakdown, quoted...), nil
+}
+
+// stackPromotions caps the discounts in the given order so item discounts
+// together never exceed the items total and free delivery never exceeds the
+// delivery fees. Nil entries and discounts capped to zero are left out.
+func stackPromotions(breakdown PriceBreakdown, promotions ...*appliedPromotion) []appliedPromotion {
+	remainingItems := breakdown.ItemsTotal
+	remainingDelivery := breakdown.DeliveryFees()
+	applied := make([]appliedPromotion, 0, len(promotions))
+	for _, a := range promotions {
+		if a == nil {
+			continue
+		}
+
+		remaining := &remainingItems
+		if a.Line.Type == string(database.PromotionTypeFreeDelivery) {
+			remaining = &remainingDelivery
+		}
+		a.Line.Amount = min(a.Line.Amount, *remaining)
+		*remaining -= a.Line.Amount
+
+		if a.Line.Amount > 0 {
+			applied = append(applied, *a)
+		}
+	}
+	return applied
+}
+
+// promotionDiscount returns how much p takes off the basket, or why it does
+// not apply. redeemed is how often the user already used p.
+func promotionDiscount(p database.ListApplicablePromotionsRow, basket []basketLine, breakdown PriceBreakdown, redeemed int64) (int64, error) {
+	if p.UsageLimit != nil && p.UsageCount >= *p.UsageLimit {
+		return 0, ErrVoucherUsedUp
+	}
+	if p.PerUserLimit != nil && redeemed >= int64(*p.PerUserLimit) {
+		return 0, ErrVoucherUsedUp
+	}
+
+	// promo per merchant / kategori hanya menghitung item yang cocok
+	eligible := int64(0)
+	for _, line := range basket {
+		if p.MerchantID != uuid.Nil && line.MerchantID != p.MerchantID {
+			continue
+		}
+		if p.ProductCategory != "" && line.ProductCategory != p.ProductCategory {
+			continue
+		}
+		eligible += line.Amount
+	}
+	if eligible == 0 || eligible < p.MinBasket {
+		return 0, ErrVoucherNotApplicable
+	}
+
+	var amount int64
+	switch p.PromotionType {
+	case database.PromotionTypePercentage:
+		amount = eligible * p.Value / 100
+	case database.PromotionTypeFlat:
+		amount = min(p.Value, eligible)
+	case database.PromotionTypeFreeDelivery:
+		amount = breakdown.DeliveryFees()
+	}
+	if p.MaxDiscount > 0 {
+		amount = min(amount, p.MaxDiscount)
+	}
+	if amount <= 0 {
+		return 0, ErrVoucherNotApplicable
+	}
+	return amount, nil
+}
+
+func newAppliedPromotion(p database.ListApplicablePromotionsRow, amount int64) *appliedPromotion {
+	return &appliedPromotion{
+		PromotionID: p.ID,
+		Line: DiscountLine{
+			PromotionID: p.ID.String(),
+			Code:        p.Code,
+			Name:        p.Name,
+			Type:        string(p.PromotionType),
+			Amount:      amount,
+		},
+	}
+}
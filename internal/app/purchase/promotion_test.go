@@ -0,0 +1,68 @@
+package purchase
+
+import (
+	"errors"
+	"testing"
+
+	"belimang/internal/infrastructure/database"
+
+	"github.com/google/uuid"
+)
+
+func TestPromotionDiscountFollowsCurrentPrices(t *testing.T) {
+	merchantID := uuid.New()
+	percent := database.ListApplicablePromotionsRow{
+		ID:            uuid.New(),
+		PromotionType: database.PromotionTypePercentage,
+		Value:         10,
+		MinBasket:     50000,
+	}
+	breakdown := PriceBreakdown{BaseFee: 5000, DistanceFee: 3000}
+
+	tests := []struct {
+		name    string
+		price   int64
+		want    int64
+		wantErr error
+	}{
+		{"quoted price", 60000, 6000, nil},
+		{"price went up", 80000, 8000, nil},
+		{"price dropped below min basket", 40000, 0, ErrVoucherNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			basket := []basketLine{{MerchantID: merchantID, ProductCategory: "Food", Amount: tt.price}}
+			got, err := promotionDiscount(percent, basket, breakdown, 0)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("promotionDiscount() = %d, %v, want %d, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStackPromotionsCapsDiscounts(t *testing.T) {
+	breakdown := PriceBreakdown{ItemsTotal: 10000, BaseFee: 5000}
+	flat := func(amount int64) *appliedPromotion {
+		return newAppliedPromotion(database.ListApplicablePromotionsRow{ID: uuid.New(), PromotionType: database.PromotionTypeFlat}, amount)
+	}
+	delivery := newAppliedPromotion(database.ListApplicablePromotionsRow{ID: uuid.New(), PromotionType: database.PromotionTypeFreeDelivery}, 8000)
+
+	applied := stackPromotions(breakdown, flat(7000), nil, flat(7000), delivery, flat(1000))
+	want := []int64{7000, 3000, 5000}
+	if len(applied) != len(want) {
+		t.Fatalf("got %d promotions, want %d", len(applied), len(want))
+	}
+	for i, a := range applied {
+		if a.Line.Amount != want[i] {
+			t.Errorf("promotion %d amount = %d, want %d", i, a.Line.Amount, want[i])
+		}
+	}
+}
+
+func TestPriceBreakdownTotalNeverNegative(t *testing.T) {
+	b := PriceBreakdown{ItemsTotal: 10000, BaseFee: 5000, Discount: 20000}
+	if got := b.Total(); got != 0 {
+		t.Errorf("Total() = %d, want 0", got)
+	}
+}
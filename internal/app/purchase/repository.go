@@ -3,11 +3,29 @@ package purchase
 import (
 	"belimang/internal/infrastructure/database"
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// ErrOrderAlreadyExists is returned when the estimate has already been turned into an order
+var ErrOrderAlreadyExists = errors.New("order already exists for estimate")
+
+// ErrPromotionUnavailable is returned when a promotion quoted on the estimate
+// ran out or ended before the order was placed
+var ErrPromotionUnavailable = errors.New("promotion is no longer available")
+
+// ErrItemOutOfStock is returned when an item ran out or was switched off
+// after the estimate was made
+var ErrItemOutOfStock = errors.New("item out of stock")
+
+// pgUniqueViolation is the Postgres SQLSTATE for a unique constraint violation
+const pgUniqueViolation = "23505"
+
 type PurchaseRepository struct {
 	db *database.DB
 }
@@ -20,18 +38,59 @@ type EstimateResult struct {
 	ID                             uuid.UUID
 	TotalPrice                     int64
 	EstimatedDeliveryTimeInMinutes int32
+	ExpiresAt                      time.Time
+	ScheduledDeliveryAt            *time.Time
+}
+
+// EstimateInput is everything persisted for one estimate. Stops are in route order.
+type EstimateInput struct {
+	UserLat, UserLng               float64
+	PriceBreakdown                 PriceBreakdown
+	EstimatedDeliveryTimeInMinutes int
+	FinalLeg                       RouteLeg
+	Stops                          []EstimateStop
+	ExpiresAt                      time.Time
+	ScheduledDeliveryAt            *time.Time
+	Promotions                     []appliedPromotion
+}
+
+// EstimateStop is one merchant in the route together with the items ordered from it
+type EstimateStop struct {
+	Order    Order
+	Items    []EstimateItem // priced lines of Order.Items, same order
+	Sequence int
+	Leg      RouteLeg
+	Subtotal int64
+}
+
+// EstimateItem is one ordered item together with its chosen options
+type EstimateItem struct {
+	ItemID       uuid.UUID
+	Quantity     int
+	Options      []SelectedOption
+	UnitPrice    int64
+	OptionsPrice int64 // per unit
+}
+
+// OrderPricing overrides the estimate's quoted prices when an order is repriced
+type OrderPricing struct {
+	PriceBreakdown PriceBreakdown
+	Subtotals      map[uuid.UUID]int64 // per merchant
+	Promotions     []appliedPromotion  // redeemed instead of the estimate's discounts
 }
 
 type OrderResult struct {
 	ID                             uuid.UUID
 	TotalPrice                     int64
 	EstimatedDeliveryTimeInMinutes int32
+	ScheduledDeliveryAt            *time.Time
+	ReleaseAt                      *time.Time
 }
 
-func (r *PurchaseRepository) CreateEstimateWithOrders(ctx context.Context, userID uuid.UUID, userLat, userLng, totalPrice float64, estimatedTime int, orders []Order) (EstimateResult, error) {
+func (r *PurchaseRepository) CreateEstimateWithOrders(ctx context.Context, userID uuid.UUID, input EstimateInput) (EstimateResult, error) {
 	var result EstimateResult
 
-	tx, err := r.db.Pool.Begin(ctx)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return result, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -41,26 +100,52 @@ func (r *PurchaseRepository) CreateEstimateWithOrders(ctx context.Context, userI
 
 	estimate, err := txQueries.CreateEstimate(ctx, database.CreateEstimateParams{
 		UserID:                         userID,
-		UserLat:                        userLat,
-		UserLng:                        userLng,
-		TotalPrice:                     int64(totalPrice),
-		EstimatedDeliveryTimeInMinutes: estimatedTime,
+		UserLat:                        input.UserLat,
+		UserLng:                        input.UserLng,
+		TotalPrice:                     input.PriceBreakdown.Total(),
+		EstimatedDeliveryTimeInMinutes: input.EstimatedDeliveryTimeInMinutes,
+		FinalLegDistanceMeters:         input.FinalLeg.DistanceInMeters,
+		FinalLegTimeInMinutes:          input.FinalLeg.EstimatedTimeInMinutes,
+		ItemsTotal:                     input.PriceBreakdown.ItemsTotal,
+		BaseFee:                        input.PriceBreakdown.BaseFee,
+		DistanceFee:                    input.PriceBreakdown.DistanceFee,
+		ExtraStopFee:                   input.PriceBreakdown.ExtraStopFee,
+		SmallOrderFee:                  input.PriceBreakdown.SmallOrderFee,
+		ServiceFee:                     input.PriceBreakdown.ServiceFee,
+		ExpiresAt:                      input.ExpiresAt,
+		ScheduledDeliveryAt:            input.ScheduledDeliveryAt,
+		DiscountTotal:                  input.PriceBreakdown.Discount,
 	})
 	if err != nil {
 		return result, fmt.Errorf("failed to save estimate: %w", err)
 	}
 
+	for _, p := range input.Promotions {
+		err = txQueries.CreateEstimateDiscount(ctx, database.CreateEstimateDiscountParams{
+			EstimateID:  estimate.ID,
+			PromotionID: p.PromotionID,
+			Amount:      p.Line.Amount,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to save estimate discount: %w", err)
+		}
+	}
+
 	// Create estimate orders in batch
-	for _, order := range orders {
-		parsedMerchantID, err := uuid.Parse(order.MerchantID)
+	for _, stop := range input.Stops {
+		parsedMerchantID, err := uuid.Parse(stop.Order.MerchantID)
 		if err != nil {
 			return result, fmt.Errorf("invalid merchant id: %w", err)
 		}
 
 		err = txQueries.CreateEstimateOrder(ctx, database.CreateEstimateOrderParams{
-			EstimateID:      estimate.ID,
-			MerchantID:      parsedMerchantID,
-			IsStartingPoint: order.IsStartingPoint,
+			EstimateID:        estimate.ID,
+			MerchantID:        parsedMerchantID,
+			IsStartingPoint:   stop.Order.IsStartingPoint,
+			StopSequence:      stop.Sequence,
+			LegDistanceMeters: stop.Leg.DistanceInMeters,
+			LegTimeInMinutes:  stop.Leg.EstimatedTimeInMinutes,
+			Subtotal:          stop.Subtotal,
 		})
 		if err != nil {
 			return result, fmt.Errorf("failed to save estimate order: %w", err)
@@ -79,7 +164,8 @@ func (r *PurchaseRepository) CreateEstimateWithOrders(ctx context.Context, userI
 	}
 
 	// Create all items in batch
-	for _, order := range orders {
+	for _, stop := range input.Stops {
+		order := stop.Order
 		parsedMerchantID, err := uuid.Parse(order.MerchantID)
 		if err != nil {
 			return result, fmt.Errorf("invalid merchant id: %w", err)
@@ -90,20 +176,31 @@ func (r *PurchaseRepository) CreateEstimateWithOrders(ctx context.Context, userI
 			return result, fmt.Errorf("estimate order not found for merchant: %s", order.MerchantID)
 		}
 
-		for _, item := range order.Items {
-			parsedItemID, err := uuid.Parse(item.ItemID)
-			if err != nil {
-				return result, fmt.Errorf("invalid item id: %w", err)
-			}
-
-			err = txQueries.CreateEstimateOrderItem(ctx, database.CreateEstimateOrderItemParams{
+		for _, item := range stop.Items {
+			estimateOrderItemID, err := txQueries.CreateEstimateOrderItem(ctx, database.CreateEstimateOrderItemParams{
 				EstimateOrderID: estimateOrderId,
-				ItemID:          parsedItemID,
+				ItemID:          item.ItemID,
 				Quantity:        item.Quantity,
+				UnitPrice:       item.UnitPrice,
+				OptionsPrice:    item.OptionsPrice,
 			})
 			if err != nil {
 				return result, fmt.Errorf("failed to save estimate order item: %w", err)
 			}
+
+			if len(item.Options) == 0 {
+				continue
+			}
+			params := database.AddEstimateOrderItemOptionsParams{EstimateOrderItemID: estimateOrderItemID}
+			for _, opt := range item.Options {
+				params.OptionIds = append(params.OptionIds, opt.OptionID)
+				params.GroupNames = append(params.GroupNames, opt.GroupName)
+				params.OptionNames = append(params.OptionNames, opt.OptionName)
+				params.PriceDeltas = append(params.PriceDeltas, opt.PriceDelta)
+			}
+			if err := txQueries.AddEstimateOrderItemOptions(ctx, params); err != nil {
+				return result, fmt.Errorf("failed to save estimate order item options: %w", err)
+			}
 		}
 	}
 
@@ -116,18 +213,21 @@ func (r *PurchaseRepository) CreateEstimateWithOrders(ctx context.Context, userI
 	result.ID = estimate.ID
 	result.TotalPrice = estimate.TotalPrice
 	result.EstimatedDeliveryTimeInMinutes = int32(estimate.EstimatedDeliveryTimeInMinutes)
+	result.ExpiresAt = input.ExpiresAt
+	result.ScheduledDeliveryAt = input.ScheduledDeliveryAt
 
 	return result, nil
 }
 
 // CreateOrderFromEstimate creates an order from an existing estimate for the specified user.
 // It accepts a userID parameter to ensure the estimate belongs to the authenticated user,
-// providing additional security and data validation.
-func (r *PurchaseRepository) CreateOrderFromEstimate(ctx context.Context, userID uuid.UUID, estimateID uuid.UUID) (OrderResult, error) {
+// providing additional security and data validation. A non-nil pricing replaces the
+// estimate's quoted prices.
+func (r *PurchaseRepository) CreateOrderFromEstimate(ctx context.Context, userID uuid.UUID, estimateID uuid.UUID, pricing *OrderPricing) (OrderResult, error) {
 	var result OrderResult
 
 	// Use transaction for performance and consistency
-	tx, err := r.db.Pool.Begin(ctx)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return result, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -150,15 +250,62 @@ func (r *PurchaseRepository) CreateOrderFromEstimate(ctx context.Context, userID
 	// Create the order from the estimate
 	order, err := txQueries.CreateOrderFromEstimate(ctx, estimateID)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return result, ErrOrderAlreadyExists
+		}
 		return result, fmt.Errorf("failed to create order from estimate: %w", err)
 	}
 
+	if pricing != nil {
+		err = txQueries.UpdateOrderPrice(ctx, database.UpdateOrderPriceParams{
+			ItemsTotal:    pricing.PriceBreakdown.ItemsTotal,
+			SmallOrderFee: pricing.PriceBreakdown.SmallOrderFee,
+			DiscountTotal: pricing.PriceBreakdown.Discount,
+			TotalPrice:    pricing.PriceBreakdown.Total(),
+			ID:            order.ID,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to reprice order: %w", err)
+		}
+		order.TotalPrice = pricing.PriceBreakdown.Total()
+	}
+
+	var discounts []database.ListEstimateDiscountsRow
+	if pricing != nil {
+		for _, p := range pricing.Promotions {
+			discounts = append(discounts, database.ListEstimateDiscountsRow{PromotionID: p.PromotionID, Amount: p.Line.Amount})
+		}
+	} else {
+		discounts, err = txQueries.ListEstimateDiscounts(ctx, estimateID)
+		if err != nil {
+			return result, fmt.Errorf("failed to get estimate discounts: %w", err)
+		}
+	}
+	if err := redeemPromotions(ctx, txQueries, userID, order.ID, discounts); err != nil {
+		return result, err
+	}
+
+	err = txQueries.CreateOrderStatusHistory(ctx, database.CreateOrderStatusHistoryParams{
+		OrderID:   order.ID,
+		ToStatus:  database.OrderStatusPlaced,
+		ActorID:   userID,
+		ActorRole: string(database.UserRoleUser),
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to save order status history: %w", err)
+	}
+
 	// Get the estimate order details to copy to the order
 	estimateDetails, err := txQueries.GetEstimateOrderDetails(ctx, estimateID)
 	if err != nil {
 		return result, fmt.Errorf("failed to get estimate details: %w", err)
 	}
 
+	if err := reserveStock(ctx, txQueries, estimateDetails); err != nil {
+		return result, err
+	}
+
 	// Group estimate details by merchant to create order merchants and items properly
 	merchantGroups := make(map[string][]database.GetEstimateOrderDetailsRow)
 	for _, detail := range estimateDetails {
@@ -166,31 +313,57 @@ func (r *PurchaseRepository) CreateOrderFromEstimate(ctx context.Context, userID
 		merchantGroups[merchantStr] = append(merchantGroups[merchantStr], detail)
 	}
 
-	// Create order merchants and their items
+	// Create order merchants and their items, keeping the estimate's stop
+	// sequence and leg metrics; the final leg to the user is copied with the order
 	for _, details := range merchantGroups {
 		// All items for the same merchant should belong to the same order merchant record
 		// Use the is_starting_point value from the first item for this merchant
 		firstDetail := details[0]
+		subtotal := firstDetail.Subtotal
+		if pricing != nil {
+			subtotal = pricing.Subtotals[firstDetail.MerchantID]
+		}
 
 		orderMerchantID, err := txQueries.CreateOrderMerchant(ctx, database.CreateOrderMerchantParams{
 			OrderID:         order.ID,
 			MerchantID:      firstDetail.MerchantID,
 			IsStartingPoint: firstDetail.IsStartingPoint,
+			StopSequence:    firstDetail.StopSequence,
+			Subtotal:        subtotal,
+
+			LegDistanceMeters: firstDetail.LegDistanceMeters,
+			LegTimeInMinutes:  firstDetail.LegTimeInMinutes,
 		})
 		if err != nil {
 			return result, fmt.Errorf("failed to create order merchant: %w", err)
 		}
 
-		// Create order items for this merchant
+		// Create order items for this merchant, priced like the order: as
+		// quoted, or at the current price when the order was repriced
 		for _, detail := range details {
-			err = txQueries.CreateOrderItem(ctx, database.CreateOrderItemParams{
+			unitPrice, optionsPrice := detail.UnitPrice, detail.OptionsPrice
+			if pricing != nil {
+				unitPrice, optionsPrice = detail.CurrentUnitPrice, detail.CurrentOptionsPrice
+			}
+			orderItemID, err := txQueries.CreateOrderItem(ctx, database.CreateOrderItemParams{
 				OrderMerchantID: orderMerchantID,
 				ItemID:          detail.ItemID,
 				Quantity:        detail.Quantity,
+				UnitPrice:       unitPrice,
+				OptionsPrice:    optionsPrice,
 			})
 			if err != nil {
 				return result, fmt.Errorf("failed to create order item: %w", err)
 			}
+
+			err = txQueries.CopyEstimateOrderItemOptions(ctx, database.CopyEstimateOrderItemOptionsParams{
+				OrderItemID:         orderItemID,
+				CurrentPrices:       pricing != nil,
+				EstimateOrderItemID: detail.EstimateOrderItemID,
+			})
+			if err != nil {
+				return result, fmt.Errorf("failed to copy order item options: %w", err)
+			}
 		}
 	}
 
@@ -203,10 +376,91 @@ func (r *PurchaseRepository) CreateOrderFromEstimate(ctx context.Context, userID
 	result.ID = order.ID
 	result.TotalPrice = order.TotalPrice
 	result.EstimatedDeliveryTimeInMinutes = int32(order.EstimatedDeliveryTimeInMinutes)
+	result.ScheduledDeliveryAt = order.ScheduledDeliveryAt
+	result.ReleaseAt = order.ReleaseAt
 
 	return result, nil
 }
 
+// redeemPromotions records the order's discounts against it. Usage limits are
+// checked again here, inside the order transaction, because the estimate may
+// have been quoted before other users used up the promotion.
+func redeemPromotions(ctx context.Context, txQueries *database.Queries, userID, orderID uuid.UUID, discounts []database.ListEstimateDiscountsRow) error {
+	for _, d := range discounts {
+		perUserLimit, err := txQueries.ClaimPromotion(ctx, d.PromotionID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrPromotionUnavailable
+			}
+			return fmt.Errorf("failed to claim promotion: %w", err)
+		}
+
+		if perUserLimit != nil {
+			used, err := txQueries.CountUserRedemptions(ctx, database.CountUserRedemptionsParams{
+				PromotionID: d.PromotionID,
+				UserID:      userID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to count promotion redemptions: %w", err)
+			}
+			if used >= int64(*perUserLimit) {
+				return ErrPromotionUnavailable
+			}
+		}
+
+		err = txQueries.CreatePromotionRedemption(ctx, database.CreatePromotionRedemptionParams{
+			PromotionID: d.PromotionID,
+			UserID:      userID,
+			OrderID:     orderID,
+			Amount:      d.Amount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save promotion redemption: %w", err)
+		}
+	}
+	return nil
+}
+
+// reserveStock takes the ordered quantities off the items' stock. The item
+// rows stay locked until the order transaction ends, so two orders for the
+// last units cannot both succeed.
+func reserveStock(ctx context.Context, txQueries *database.Queries, details []database.GetEstimateOrderDetailsRow) error {
+	quantities := make(map[uuid.UUID]int)
+	for _, d := range details {
+		quantities[d.ItemID] += d.Quantity
+	}
+	itemIDs := make([]uuid.UUID, 0, len(quantities))
+	for id := range quantities {
+		itemIDs = append(itemIDs, id)
+	}
+
+	items, err := txQueries.LockItemsForUpdate(ctx, itemIDs)
+	if err != nil {
+		return fmt.Errorf("failed to lock items: %w", err)
+	}
+	if len(items) != len(itemIDs) {
+		return ErrItemOutOfStock
+	}
+
+	deltas := make([]int, len(items))
+	for i, item := range items {
+		if !item.IsAvailable || (item.Stock != nil && *item.Stock < quantities[item.ID]) {
+			return ErrItemOutOfStock
+		}
+		itemIDs[i] = item.ID
+		deltas[i] = -quantities[item.ID]
+	}
+
+	err = txQueries.AdjustItemStock(ctx, database.AdjustItemStockParams{
+		ItemIds: itemIDs,
+		Deltas:  deltas,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reserve item stock: %w", err)
+	}
+	return nil
+}
+
 // Helper method to get an estimate by ID (for validation)
 func (r *PurchaseRepository) GetEstimateById(ctx context.Context, estimateID uuid.UUID) (database.Estimates, error) {
 	return r.db.Queries.GetEstimateById(ctx, estimateID)
@@ -0,0 +1,331 @@
+package purchase
+
+import (
+	"math"
+
+	"belimang/internal/pkg/utils"
+)
+
+// heldKarpMaxStops is the largest number of non-starting merchants the exact
+// solver handles; above this the DP table (2^n * n) grows too quickly.
+const heldKarpMaxStops = 10
+
+// RouteOptimizer orders the merchants of a cart into a delivery route.
+//
+// The courier always begins at start, visits every merchant in stops exactly
+// once and finishes at dest (the user). Optimize returns the merchant part of
+// the route, beginning with start; dest is not included.
+type RouteOptimizer interface {
+	Optimize(start merchantPoint, stops []merchantPoint, dest merchantPoint) []merchantPoint
+}
+
+// routeDistanceFunc returns the travel distance in meters between two points.
+type routeDistanceFunc func(a, b merchantPoint) float64
+
+// h3RouteDistance measures distance with the same H3 grid used by the
+// pre-filter, falling back to Haversine when the grid distance is undefined
+// (e.g. across pentagons or very far apart cells).
+func h3RouteDistance(a, b merchantPoint) float64 {
+	if d := utils.H3GridDistanceMeters(a.H3Cell, b.H3Cell); d >= 0 {
+		return d
+	}
+	return utils.HaversineDistance(a.Lat, a.Lng, b.Lat, b.Lng)
+}
+
+// newRouteLeg describes a leg of the given length, timed at the courier's speed.
+func newRouteLeg(distanceMeters float64) RouteLeg {
+	return RouteLeg{
+		DistanceInMeters:       distanceMeters,
+		EstimatedTimeInMinutes: utils.EstimateTimeMinutes(distanceMeters),
+	}
+}
+
+// routeTimeline walks the courier along route and on to dest. Every merchant
+// starts preparing when the order is placed; the courier is at the starting
+// merchant at minute zero and waits at each stop until its food is ready.
+// It returns the wait at every stop and the minutes until dest is reached.
+func routeTimeline(route []merchantPoint, dest merchantPoint, dist routeDistanceFunc) ([]float64, int) {
+	waits := make([]float64, len(route))
+	if len(route) == 0 {
+		return waits, 0
+	}
+
+	clock := 0.0
+	for i, p := range route {
+		if i > 0 {
+			clock += utils.TravelTimeMinutes(dist(route[i-1], p))
+		}
+		if ready := float64(p.PreparationMinutes); ready > clock {
+			waits[i] = ready - clock
+			clock = ready
+		}
+	}
+	clock += utils.TravelTimeMinutes(dist(route[len(route)-1], dest))
+
+	return waits, int(math.Round(clock))
+}
+
+// fastestStartingRoute tries every merchant as the starting point and keeps
+// the route that reaches dest soonest, preparation waits included. Ties go to
+// the shorter route, then to the earlier merchant in points. The chosen
+// starting merchant is marked as such in the returned route.
+func fastestStartingRoute(opt RouteOptimizer, points []merchantPoint, dest merchantPoint, dist routeDistanceFunc) []merchantPoint {
+	var best []merchantPoint
+	bestMinutes, bestDistance := 0, 0.0
+
+	for i := range points {
+		start := points[i]
+		start.IsStart = true
+		start.Order.IsStartingPoint = true
+
+		rest := make([]merchantPoint, 0, len(points)-1)
+		rest = append(rest, points[:i]...)
+		rest = append(rest, points[i+1:]...)
+
+		route := opt.Optimize(start, rest, dest)
+		_, minutes := routeTimeline(route, dest, dist)
+		distance := routeLength(route, dest, dist)
+		if best == nil || minutes < bestMinutes || (minutes == bestMinutes && distance < bestDistance) {
+			best, bestMinutes, bestDistance = route, minutes, distance
+		}
+	}
+	return best
+}
+
+// routeLength is the length of route plus the final leg to dest, in meters.
+func routeLength(route []merchantPoint, dest merchantPoint, dist routeDistanceFunc) float64 {
+	if len(route) == 0 {
+		return 0
+	}
+	total := dist(route[len(route)-1], dest)
+	for i := 1; i < len(route); i++ {
+		total += dist(route[i-1], route[i])
+	}
+	return total
+}
+
+// GreedyRouteOptimizer always walks to the nearest unvisited merchant.
+// It ignores where the user is, so routes degrade as carts grow.
+type GreedyRouteOptimizer struct {
+	dist routeDistanceFunc
+}
+
+func NewGreedyRouteOptimizer() *GreedyRouteOptimizer {
+	return &GreedyRouteOptimizer{dist: h3RouteDistance}
+}
+
+func (o *GreedyRouteOptimizer) Optimize(start merchantPoint, stops []merchantPoint, dest merchantPoint) []merchantPoint {
+	rest := append([]merchantPoint(nil), stops...)
+	route := []merchantPoint{start}
+	current := start
+
+	for len(rest) > 0 {
+		bestIdx := 0
+		bestDist := math.MaxFloat64
+		for i, p := range rest {
+			if d := o.dist(current, p); d < bestDist {
+				bestDist = d
+				bestIdx = i
+			}
+		}
+		route = append(route, rest[bestIdx])
+		current = rest[bestIdx]
+		rest = append(rest[:bestIdx], rest[bestIdx+1:]...)
+	}
+
+	return route
+}
+
+// HeldKarpRouteOptimizer finds the shortest route exactly using the
+// Held-Karp dynamic programme. Only suitable for small stop counts.
+type HeldKarpRouteOptimizer struct {
+	dist routeDistanceFunc
+}
+
+func NewHeldKarpRouteOptimizer() *HeldKarpRouteOptimizer {
+	return &HeldKarpRouteOptimizer{dist: h3RouteDistance}
+}
+
+func (o *HeldKarpRouteOptimizer) Optimize(start merchantPoint, stops []merchantPoint, dest merchantPoint) []merchantPoint {
+	n := len(stops)
+	if n == 0 {
+		return []merchantPoint{start}
+	}
+
+	full := 1 << n
+	// cost[mask][j]: shortest path from start visiting every stop in mask, ending at stop j
+	cost := make([][]float64, full)
+	parent := make([][]int, full)
+	for mask := range cost {
+		cost[mask] = make([]float64, n)
+		parent[mask] = make([]int, n)
+		for j := range cost[mask] {
+			cost[mask][j] = math.Inf(1)
+			parent[mask][j] = -1
+		}
+	}
+	for j := 0; j < n; j++ {
+		cost[1<<j][j] = o.dist(start, stops[j])
+	}
+
+	for mask := 1; mask < full; mask++ {
+		for j := 0; j < n; j++ {
+			if mask&(1<<j) == 0 || math.IsInf(cost[mask][j], 1) {
+				continue
+			}
+			for k := 0; k < n; k++ {
+				if mask&(1<<k) != 0 {
+					continue
+				}
+				next := mask | 1<<k
+				if c := cost[mask][j] + o.dist(stops[j], stops[k]); c < cost[next][k] {
+					cost[next][k] = c
+					parent[next][k] = j
+				}
+			}
+		}
+	}
+
+	last := 0
+	best := math.Inf(1)
+	for j := 0; j < n; j++ {
+		if c := cost[full-1][j] + o.dist(stops[j], dest); c < best {
+			best = c
+			last = j
+		}
+	}
+
+	order := make([]int, 0, n)
+	for mask, j := full-1, last; j != -1; {
+		order = append(order, j)
+		prev := parent[mask][j]
+		mask &^= 1 << j
+		j = prev
+	}
+
+	route := make([]merchantPoint, 0, n+1)
+	route = append(route, start)
+	for i := len(order) - 1; i >= 0; i-- {
+		route = append(route, stops[order[i]])
+	}
+	return route
+}
+
+// LocalSearchRouteOptimizer seeds a route with the greedy walk and improves it
+// with 2-opt segment reversals and or-opt segment moves until no move helps.
+type LocalSearchRouteOptimizer struct {
+	dist  routeDistanceFunc
+	greed *GreedyRouteOptimizer
+}
+
+func NewLocalSearchRouteOptimizer() *LocalSearchRouteOptimizer {
+	return &LocalSearchRouteOptimizer{
+		dist:  h3RouteDistance,
+		greed: NewGreedyRouteOptimizer(),
+	}
+}
+
+func (o *LocalSearchRouteOptimizer) Optimize(start merchantPoint, stops []merchantPoint, dest merchantPoint) []merchantPoint {
+	// path holds start, the stops and dest; only the stops in between may move
+	path := append(o.greed.Optimize(start, stops, dest), dest)
+	best := o.pathLength(path)
+
+	for improved := true; improved; {
+		improved = false
+
+		if next, length := o.twoOpt(path, best); length < best {
+			path, best, improved = next, length, true
+		}
+		if next, length := o.orOpt(path, best); length < best {
+			path, best, improved = next, length, true
+		}
+	}
+
+	return path[:len(path)-1]
+}
+
+// twoOpt returns the best path obtained by reversing one inner segment.
+func (o *LocalSearchRouteOptimizer) twoOpt(path []merchantPoint, length float64) ([]merchantPoint, float64) {
+	bestPath, bestLength := path, length
+	last := len(path) - 2
+
+	for i := 1; i < last; i++ {
+		for j := i + 1; j <= last; j++ {
+			delta := o.dist(path[i-1], path[j]) + o.dist(path[i], path[j+1]) -
+				o.dist(path[i-1], path[i]) - o.dist(path[j], path[j+1])
+			if length+delta < bestLength-1e-9 {
+				next := append([]merchantPoint(nil), path...)
+				for l, r := i, j; l < r; l, r = l+1, r-1 {
+					next[l], next[r] = next[r], next[l]
+				}
+				bestPath, bestLength = next, length+delta
+			}
+		}
+	}
+
+	return bestPath, bestLength
+}
+
+// orOpt returns the best path obtained by moving a segment of up to three
+// consecutive stops to another position.
+func (o *LocalSearchRouteOptimizer) orOpt(path []merchantPoint, length float64) ([]merchantPoint, float64) {
+	bestPath, bestLength := path, length
+	last := len(path) - 2
+
+	for segLen := 1; segLen <= 3; segLen++ {
+		for i := 1; i+segLen-1 <= last; i++ {
+			segment := path[i : i+segLen]
+			remaining := make([]merchantPoint, 0, len(path)-segLen)
+			remaining = append(remaining, path[:i]...)
+			remaining = append(remaining, path[i+segLen:]...)
+
+			// insert between remaining[pos-1] and remaining[pos]
+			for pos := 1; pos < len(remaining); pos++ {
+				if pos == i {
+					continue
+				}
+				next := make([]merchantPoint, 0, len(path))
+				next = append(next, remaining[:pos]...)
+				next = append(next, segment...)
+				next = append(next, remaining[pos:]...)
+
+				if l := o.pathLength(next); l < bestLength-1e-9 {
+					bestPath, bestLength = next, l
+				}
+			}
+		}
+	}
+
+	return bestPath, bestLength
+}
+
+func (o *LocalSearchRouteOptimizer) pathLength(path []merchantPoint) float64 {
+	total := 0.0
+	for i := 0; i < len(path)-1; i++ {
+		total += o.dist(path[i], path[i+1])
+	}
+	return total
+}
+
+// AutoRouteOptimizer solves small carts exactly and falls back to local
+// search once the number of stops makes Held-Karp too expensive.
+type AutoRouteOptimizer struct {
+	exact     RouteOptimizer
+	heuristic RouteOptimizer
+	maxExact  int
+}
+
+func NewAutoRouteOptimizer() *AutoRouteOptimizer {
+	return &AutoRouteOptimizer{
+		exact:     NewHeldKarpRouteOptimizer(),
+		heuristic: NewLocalSearchRouteOptimizer(),
+		maxExact:  heldKarpMaxStops,
+	}
+}
+
+func (o *AutoRouteOptimizer) Optimize(start merchantPoint, stops []merchantPoint, dest merchantPoint) []merchantPoint {
+	if len(stops) <= o.maxExact {
+		return o.exact.Optimize(start, stops, dest)
+	}
+	return o.heuristic.Optimize(start, stops, dest)
+}
@@ -0,0 +1,172 @@
+package purchase
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"belimang/internal/pkg/utils"
+)
+
+// testPoint places a point the given meters east and north of a fixed origin
+func testPoint(t *testing.T, id string, eastMeters, northMeters float64) merchantPoint {
+	t.Helper()
+	const originLat, originLng = -6.2, 106.8
+	lat := originLat + northMeters/111320
+	lng := originLng + eastMeters/110700 // 1 derajat bujur di lintang -6.2
+	cell, err := utils.LatLonToH3(lat, lng)
+	if err != nil {
+		t.Fatalf("h3 cell for %s: %v", id, err)
+	}
+	return merchantPoint{MerchantID: id, Lat: lat, Lng: lng, H3Cell: cell}
+}
+
+// randomStops returns n stops scattered within a 6km square, the same for every run
+func randomStops(t *testing.T, seed int64, n int) (merchantPoint, []merchantPoint, merchantPoint) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	at := func(id string) merchantPoint {
+		return testPoint(t, id, rng.Float64()*6000, rng.Float64()*6000)
+	}
+	start := at("start")
+	stops := make([]merchantPoint, n)
+	for i := range stops {
+		stops[i] = at(fmt.Sprintf("m%d", i))
+	}
+	return start, stops, at("user")
+}
+
+func checkRoute(t *testing.T, name string, route []merchantPoint, start merchantPoint, stops []merchantPoint) {
+	t.Helper()
+	if len(route) != len(stops)+1 || route[0].MerchantID != start.MerchantID {
+		t.Fatalf("%s: route must begin at start and hold every stop once, got %d points", name, len(route))
+	}
+	seen := make(map[string]bool)
+	for _, p := range route[1:] {
+		if seen[p.MerchantID] || p.MerchantID == start.MerchantID {
+			t.Fatalf("%s: merchant %s visited twice", name, p.MerchantID)
+		}
+		seen[p.MerchantID] = true
+	}
+}
+
+func TestRouteOptimizersCompare(t *testing.T) {
+	tests := []struct {
+		name  string
+		seed  int64
+		stops int
+	}{
+		{"two stops", 1, 2},
+		{"four stops", 2, 4},
+		{"five stops", 3, 5},
+		{"seven stops", 4, 7},
+		{"nine stops", 5, 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, stops, dest := randomStops(t, tt.seed, tt.stops)
+
+			greedy := NewGreedyRouteOptimizer().Optimize(start, stops, dest)
+			local := NewLocalSearchRouteOptimizer().Optimize(start, stops, dest)
+			exact := NewHeldKarpRouteOptimizer().Optimize(start, stops, dest)
+			checkRoute(t, "greedy", greedy, start, stops)
+			checkRoute(t, "local search", local, start, stops)
+			checkRoute(t, "held-karp", exact, start, stops)
+
+			greedyLen := routeLength(greedy, dest, h3RouteDistance)
+			localLen := routeLength(local, dest, h3RouteDistance)
+			exactLen := routeLength(exact, dest, h3RouteDistance)
+			if exactLen > localLen+1e-6 || localLen > greedyLen+1e-6 {
+				t.Errorf("want held-karp <= local search <= greedy, got %.0f, %.0f, %.0f", exactLen, localLen, greedyLen)
+			}
+
+			// tanpa waktu persiapan, ETA mengikuti panjang rute
+			_, greedyETA := routeTimeline(greedy, dest, h3RouteDistance)
+			_, localETA := routeTimeline(local, dest, h3RouteDistance)
+			_, exactETA := routeTimeline(exact, dest, h3RouteDistance)
+			if exactETA > localETA || localETA > greedyETA {
+				t.Errorf("want held-karp <= local search <= greedy ETA, got %d, %d, %d", exactETA, localETA, greedyETA)
+			}
+		})
+	}
+}
+
+func TestGreedyRouteIsBeatenOnALine(t *testing.T) {
+	// greedy takes the nearest merchant east first and has to double back
+	start := testPoint(t, "start", 0, 0)
+	stops := []merchantPoint{
+		testPoint(t, "east", 1000, 0),
+		testPoint(t, "west", -1500, 0),
+		testPoint(t, "far-east", 3000, 0),
+	}
+	dest := testPoint(t, "user", 10000, 0)
+
+	greedy := NewGreedyRouteOptimizer().Optimize(start, stops, dest)
+	exact := NewHeldKarpRouteOptimizer().Optimize(start, stops, dest)
+	local := NewLocalSearchRouteOptimizer().Optimize(start, stops, dest)
+
+	if got := greedy[1].MerchantID; got != "east" {
+		t.Fatalf("greedy should walk east first, went to %s", got)
+	}
+	wantOrder := []string{"start", "west", "east", "far-east"}
+	for i, id := range wantOrder {
+		if exact[i].MerchantID != id {
+			t.Fatalf("held-karp route = %v, want %v", routeIDs(exact), wantOrder)
+		}
+	}
+	exactLen := routeLength(exact, dest, h3RouteDistance)
+	if greedyLen := routeLength(greedy, dest, h3RouteDistance); exactLen >= greedyLen {
+		t.Errorf("held-karp %.0f m should beat greedy %.0f m", exactLen, greedyLen)
+	}
+	if localLen := routeLength(local, dest, h3RouteDistance); localLen > exactLen+1e-6 {
+		t.Errorf("local search %.0f m should find the optimum %.0f m here", localLen, exactLen)
+	}
+}
+
+// recordingOptimizer remembers that it was asked for a route
+type recordingOptimizer struct {
+	calls int
+}
+
+func (o *recordingOptimizer) Optimize(start merchantPoint, stops []merchantPoint, dest merchantPoint) []merchantPoint {
+	o.calls++
+	return append([]merchantPoint{start}, stops...)
+}
+
+func TestAutoRouteOptimizerThreshold(t *testing.T) {
+	tests := []struct {
+		stops     int
+		wantExact bool
+	}{
+		{0, true},
+		{1, true},
+		{heldKarpMaxStops - 1, true},
+		{heldKarpMaxStops, true},
+		{heldKarpMaxStops + 1, false},
+		{heldKarpMaxStops + 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d stops", tt.stops), func(t *testing.T) {
+			exact, heuristic := &recordingOptimizer{}, &recordingOptimizer{}
+			auto := NewAutoRouteOptimizer()
+			auto.exact, auto.heuristic = exact, heuristic
+
+			start, stops, dest := randomStops(t, int64(tt.stops), tt.stops)
+			auto.Optimize(start, stops, dest)
+
+			if gotExact := exact.calls == 1 && heuristic.calls == 0; gotExact != tt.wantExact {
+				t.Errorf("exact calls = %d, heuristic calls = %d, want exact = %v", exact.calls, heuristic.calls, tt.wantExact)
+			}
+		})
+	}
+}
+
+func routeIDs(route []merchantPoint) []string {
+	ids := make([]string, len(route))
+	for i, p := range route {
+		ids[i] = p.MerchantID
+	}
+	return ids
+}
@@ -1,55 +1,112 @@
 package purchase
 
 import (
+	"belimang/internal/app/items"
+	"belimang/internal/app/order"
+	"belimang/internal/config"
+	"belimang/internal/infrastructure/cache"
 	"belimang/internal/infrastructure/database"
+	logger "belimang/internal/pkg/logging"
 	"belimang/internal/pkg/utils"
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/uber/h3-go/v4"
 )
 
-var ErrNeedExactValidation = errors.New("ambiguous distance: need exact validation")
+var (
+	ErrNeedExactValidation = errors.New("ambiguous distance: need exact validation")
+	ErrCoordinatesTooFar   = errors.New("coordinates too far")
+	ErrEstimateNotFound    = errors.New("estimate not found")
+	ErrEstimateExpired     = errors.New("estimate expired")
+	ErrScheduleTooSoon     = errors.New("scheduled delivery time is too soon")
+	ErrScheduleTooFar      = errors.New("scheduled delivery time is too far ahead")
+	ErrItemUnavailable     = errors.New("item unavailable")
+	ErrInvalidItemOptions  = errors.New("invalid item options")
+)
 
 type PurchaseService struct {
-	queries *database.Queries
-	db      *database.DB
+	queries      *database.Queries
+	db           *database.DB
+	cache        *cache.RedisCache
+	optimizer    RouteOptimizer
+	fees         *FeeEngine
+	estimateTTL  time.Duration
+	nearbyRadius float64 // batas ekspansi pencarian nearby, dalam meter
+	schedule     config.ScheduleConfig
+	events       *order.EventPublisher
 }
 
-func NewPurchaseService(q *database.Queries, db *database.DB) *PurchaseService {
+func NewPurchaseService(q *database.Queries, db *database.DB, redisCache *cache.RedisCache, cfg config.DeliveryConfig, events *order.EventPublisher) *PurchaseService {
 	return &PurchaseService{
-		queries: q,
-		db:      db,
+		queries:      q,
+		db:           db,
+		cache:        redisCache,
+		optimizer:    NewAutoRouteOptimizer(),
+		fees:         NewFeeEngine(cfg.Fee),
+		estimateTTL:  cfg.EstimateTTL,
+		nearbyRadius: cfg.NearbyMaxRadiusMeters,
+		schedule:     cfg.Schedule,
+		events:       events,
 	}
 }
 
 // validateWithH3PreFilter returns:
-// - nil → all merchants ≤3000m
-// - error → any merchant >3000m
-func (s *PurchaseService) validateWithH3PreFilter(
-	userH3 h3.Cell,
-	merchantPoints []merchantPoint,
-) error {
+// - nil → merchant pasti dalam radius merchant
+// - ErrNeedExactValidation → merchant di ambiguous band, perlu cek jarak exact
+// - ErrCoordinatesTooFar → merchant pasti di luar radius merchant
+func (s *PurchaseService) validateWithH3PreFilter(userH3 h3.Cell, mp merchantPoint) error {
+	switch utils.ClassifyH3Distance(userH3, mp.H3Cell, mp.RadiusMeters) {
+	case utils.H3Accept:
+		return nil
+	case utils.H3Reject:
+		return ErrCoordinatesTooFar
+	default:
+		return ErrNeedExactValidation
+	}
+}
+
+// validateDeliveryDistance checks every merchant against its own delivery
+// radius and service zone, using the H3 pre-filter first and the exact
+// distance only for merchants in the ambiguous band. The exact check is the
+// same predicate as ListNearbyMerchants: Haversine distance within the radius,
+// and the user's res-10 cell inside the service zone, if the merchant has one.
+func (s *PurchaseService) validateDeliveryDistance(user merchantPoint, merchantPoints []merchantPoint) error {
 	for _, mp := range merchantPoints {
-		dist := utils.H3GridDistanceMeters(userH3, mp.H3Cell)
-		if dist < 0 {
-			return errors.New("coordinates too far")
+		// merchant tanpa service zone hanya dibatasi radius
+		if len(mp.ServiceZone) > 0 && !utils.ZoneContains(mp.ServiceZone, user.H3Cell) {
+			return ErrCoordinatesTooFar
 		}
-		if dist > 3000 {
-			return errors.New("coordinates too far")
+
+		err := s.validateWithH3PreFilter(user.H3Cell, mp)
+		if errors.Is(err, ErrNeedExactValidation) {
+			if utils.HaversineDistance(user.Lat, user.Lng, mp.Lat, mp.Lng) > mp.RadiusMeters {
+				return ErrCoordinatesTooFar
+			}
+			continue
+		}
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 type merchantPoint struct {
-	MerchantID string
-	Lat, Lng   float64
-	H3Cell     h3.Cell
-	IsStart    bool
-	Order      Order
+	MerchantID   string
+	Lat, Lng     float64
+	H3Cell       h3.Cell
+	RadiusMeters float64
+	ServiceZone  []h3.Cell
+	IsStart      bool
+	Order        Order
+
+	// menit sampai semua item order di merchant ini siap diambil
+	PreparationMinutes int
 }
 
 func (s *PurchaseService) ValidateAndEstimate(ctx context.Context, userID uuid.UUID, req EstimateRequest) (EstimateResponse, error) {
@@ -82,14 +139,28 @@ func (s *PurchaseService) ValidateAndEstimate(ctx context.Context, userID uuid.U
 		merchantMap[m.ID] = m
 	}
 
+	zoneRows, err := s.queries.GetMerchantServiceZones(ctx, merchantIDs)
+	if err != nil {
+		return EstimateResponse{}, errors.New("failed to fetch merchant service zones")
+	}
+	serviceZones := make(map[uuid.UUID][]h3.Cell)
+	for _, z := range zoneRows {
+		cell, err := utils.ParseZoneCell(z.H3Cell)
+		if err != nil {
+			return EstimateResponse{}, fmt.Errorf("invalid service zone for merchant %s: %w", z.MerchantID, err)
+		}
+		serviceZones[z.MerchantID] = append(serviceZones[z.MerchantID], cell)
+	}
+
 	startCount := 0
 	for _, o := range req.Orders {
 		if o.IsStartingPoint {
 			startCount++
 		}
 	}
-	if startCount != 1 {
-		return EstimateResponse{}, errors.New("exactly one order must have isStartingPoint=true")
+	// tanpa isStartingPoint, titik awal dipilih otomatis
+	if startCount > 1 {
+		return EstimateResponse{}, errors.New("at most one order can have isStartingPoint=true")
 	}
 
 	var points []merchantPoint
@@ -102,7 +173,7 @@ func (s *PurchaseService) ValidateAndEstimate(ctx context.Context, userID uuid.U
 
 	var itemIDs []uuid.UUID
 	var itemMerchantIDs []uuid.UUID
-	itemQuantities := make(map[string]int)
+	stockNeeded := make(map[uuid.UUID]int)
 
 	for _, o := range req.Orders {
 		parsedMerchantID := merchantIdMap[o.MerchantID]
@@ -113,9 +184,7 @@ func (s *PurchaseService) ValidateAndEstimate(ctx context.Context, userID uuid.U
 			}
 			itemIDs = append(itemIDs, parsedItemID)
 			itemMerchantIDs = append(itemMerchantIDs, parsedMerchantID)
-
-			key := parsedItemID.String() + "-" + parsedMerchantID.String()
-			itemQuantities[key] = item.Quantity
+			stockNeeded[parsedItemID] += item.Quantity
 		}
 	}
 
@@ -127,22 +196,65 @@ func (s *PurchaseService) ValidateAndEstimate(ctx context.Context, userID uuid.U
 		return EstimateResponse{}, errors.New("failed to fetch item prices")
 	}
 
-	if len(itemPrices) != len(itemIDs) {
-		return EstimateResponse{}, errors.New("item not found")
+	priceMap := make(map[string]database.GetItemPricesByIDsAndMerchantsRow, len(itemPrices))
+	for _, itemPrice := range itemPrices {
+		priceMap[itemPrice.ID.String()+"-"+itemPrice.MerchantID.String()] = itemPrice
 	}
 
-	foundItems := make(map[string]bool)
-	for _, itemPrice := range itemPrices {
-		key := itemPrice.ID.String() + "-" + itemPrice.MerchantID.String()
-		foundItems[key] = true
-		if quantity, exists := itemQuantities[key]; exists {
-			totalPrice += int(itemPrice.Price) * quantity
-		}
+	options, err := s.loadItemOptions(ctx, itemIDs)
+	if err != nil {
+		return EstimateResponse{}, err
 	}
 
-	for key := range itemQuantities {
-		if !foundItems[key] {
-			return EstimateResponse{}, errors.New("item not found")
+	// satu baris per item yang diminta; item yang sama boleh muncul lagi dengan opsi lain
+	basket := make([]basketLine, 0, len(itemIDs))
+	merchantItems := make(map[uuid.UUID][]EstimateItem)
+	merchantSubtotals := make(map[uuid.UUID]int64)
+	merchantPrepMinutes := make(map[uuid.UUID]int)
+	for _, o := range req.Orders {
+		parsedMerchantID := merchantIdMap[o.MerchantID]
+		for _, item := range o.Items {
+			parsedItemID, _ := uuid.Parse(item.ItemID) // sudah divalidasi di atas
+			itemPrice, exists := priceMap[parsedItemID.String()+"-"+parsedMerchantID.String()]
+			if !exists {
+				return EstimateResponse{}, errors.New("item not found")
+			}
+
+			// stok dicek lagi (dengan lock) saat order dibuat, ini hanya penolakan awal
+			if !itemPrice.IsAvailable || (itemPrice.Stock != nil && *itemPrice.Stock < stockNeeded[itemPrice.ID]) {
+				return EstimateResponse{}, ErrItemUnavailable
+			}
+
+			selected, optionsPrice, err := options.selectOptions(itemPrice.ID, item.OptionIDs)
+			if err != nil {
+				return EstimateResponse{}, err
+			}
+
+			amount := (itemPrice.Price + optionsPrice) * int64(item.Quantity)
+			totalPrice += int(amount)
+			merchantSubtotals[parsedMerchantID] += amount
+			basket = append(basket, basketLine{
+				MerchantID:      parsedMerchantID,
+				ProductCategory: itemPrice.ProductCategory,
+				Amount:          amount,
+			})
+			merchantItems[parsedMerchantID] = append(merchantItems[parsedMerchantID], EstimateItem{
+				ItemID:       itemPrice.ID,
+				Quantity:     item.Quantity,
+				Options:      selected,
+				UnitPrice:    itemPrice.Price,
+				OptionsPrice: optionsPrice,
+			})
+
+			// item tanpa waktu persiapan sendiri mengikuti merchant-nya
+			merchant := merchantMap[parsedMerchantID]
+			prep := utils.MerchantPreparationMinutes(merchant.MerchantCategory, merchant.PreparationTimeMinutes)
+			if itemPrice.PreparationTimeMinutes != nil {
+				prep = *itemPrice.PreparationTimeMinutes
+			}
+			if prep > merchantPrepMinutes[parsedMerchantID] {
+				merchantPrepMinutes[parsedMerchantID] = prep
+			}
 		}
 	}
 
@@ -159,99 +271,115 @@ func (s *PurchaseService) ValidateAndEstimate(ctx context.Context, userID uuid.U
 		}
 
 		points = append(points, merchantPoint{
-			MerchantID: o.MerchantID,
-			Lat:        merchant.Lat,
-			Lng:        merchant.Lng,
-			H3Cell:     h3Cell,
-			IsStart:    o.IsStartingPoint,
-			Order:      o,
+			MerchantID:   o.MerchantID,
+			Lat:          merchant.Lat,
+			Lng:          merchant.Lng,
+			H3Cell:       h3Cell,
+			RadiusMeters: float64(merchant.DeliveryRadiusMeters),
+			ServiceZone:  serviceZones[parsedMerchantID],
+			IsStart:      o.IsStartingPoint,
+			Order:        o,
+
+			PreparationMinutes: merchantPrepMinutes[parsedMerchantID],
 		})
 	}
 
-	err = s.validateWithH3PreFilter(userH3, points)
-	if err != nil {
-		return EstimateResponse{}, errors.New("coordinates too far")
+	dest := merchantPoint{
+		Lat:    req.UserLocation.Lat,
+		Lng:    req.UserLocation.Long,
+		H3Cell: userH3,
 	}
 
-	// Always use H3 for TSP since we've validated with H3
-	const useHaversineForTSP = false
-
-	var start *merchantPoint
-	rest := make([]merchantPoint, 0)
-	for i := range points {
-		if points[i].IsStart {
-			start = &points[i]
-		} else {
-			rest = append(rest, points[i])
-		}
-	}
-	if start == nil {
-		return EstimateResponse{}, errors.New("starting point not found")
+	err = s.validateDeliveryDistance(dest, points)
+	if err != nil {
+		return EstimateResponse{}, err
 	}
 
-	route := []merchantPoint{*start}
-	current := *start
-
-	for len(rest) > 0 {
-		bestIdx := -1
-		if useHaversineForTSP {
-			bestDist := 1e15
-			for i, p := range rest {
-				d := utils.HaversineDistance(current.Lat, current.Lng, p.Lat, p.Lng)
-				if d < bestDist {
-					bestDist = d
-					bestIdx = i
-				}
-			}
-		} else {
-			bestGridDist := int(^uint(0) >> 1)
-			for i, p := range rest {
-				if d, err := h3.GridDistance(current.H3Cell, p.H3Cell); err == nil && d < bestGridDist {
-					bestGridDist = d
-					bestIdx = i
-				}
+	var route []merchantPoint
+	if startCount == 1 {
+		var start *merchantPoint
+		rest := make([]merchantPoint, 0)
+		for i := range points {
+			if points[i].IsStart {
+				start = &points[i]
+			} else {
+				rest = append(rest, points[i])
 			}
 		}
-
-		if bestIdx == -1 {
-			break
+		if start == nil {
+			return EstimateResponse{}, errors.New("starting point not found")
 		}
-		route = append(route, rest[bestIdx])
-		current = rest[bestIdx]
-		rest = append(rest[:bestIdx], rest[bestIdx+1:]...)
+		route = s.optimizer.Optimize(*start, rest, dest)
+	} else {
+		route = fastestStartingRoute(s.optimizer, points, dest, h3RouteDistance)
 	}
+	waits, timeMinutes := routeTimeline(route, dest, h3RouteDistance)
 
-	// === Hitung total jarak ===
-	totalDist := 0.0
-	if useHaversineForTSP {
-		for i := 0; i < len(route)-1; i++ {
-			totalDist += utils.HaversineDistance(route[i].Lat, route[i].Lng, route[i+1].Lat, route[i+1].Lng)
+	deliveryRoute := DeliveryRoute{
+		Stops:    make([]RouteStop, len(route)),
+		FinalLeg: newRouteLeg(h3RouteDistance(route[len(route)-1], dest)),
+	}
+	stops := make([]EstimateStop, len(route))
+	routeDistance := deliveryRoute.FinalLeg.DistanceInMeters
+	for i, p := range route {
+		var leg RouteLeg
+		if i > 0 {
+			leg = newRouteLeg(h3RouteDistance(route[i-1], p))
 		}
-		last := route[len(route)-1]
-		totalDist += utils.HaversineDistance(last.Lat, last.Lng, req.UserLocation.Lat, req.UserLocation.Long)
-	} else {
-		totalGridDist := 0
-		for i := 0; i < len(route)-1; i++ {
-			if d, err := h3.GridDistance(route[i].H3Cell, route[i+1].H3Cell); err == nil {
-				totalGridDist += d
-			}
+		routeDistance += leg.DistanceInMeters
+		subtotal := merchantSubtotals[merchantIdMap[p.MerchantID]]
+
+		deliveryRoute.Stops[i] = RouteStop{
+			Sequence:        i + 1,
+			MerchantID:      p.MerchantID,
+			Location:        Location{Lat: p.Lat, Long: p.Lng},
+			IsStartingPoint: p.IsStart,
+			Subtotal:        subtotal,
+			Leg:             leg,
+
+			PreparationTimeInMinutes: p.PreparationMinutes,
+			WaitTimeInMinutes:        int(math.Round(waits[i])),
 		}
-		if d, err := h3.GridDistance(route[len(route)-1].H3Cell, userH3); err == nil {
-			totalGridDist += d
+		stops[i] = EstimateStop{
+			Order:    p.Order,
+			Items:    merchantItems[merchantIdMap[p.MerchantID]],
+			Sequence: i + 1,
+			Leg:      leg,
+			Subtotal: subtotal,
 		}
-		totalDist = float64(totalGridDist) * utils.EDGE_LENGTH_METERS
 	}
 
-	timeMinutes := utils.EstimateTimeMinutes(totalDist)
+	if err := s.validateSchedule(req.ScheduledDeliveryAt, timeMinutes); err != nil {
+		return EstimateResponse{}, err
+	}
+	if err := s.checkMerchantsOpen(ctx, merchantIDs, preparationStart(req.ScheduledDeliveryAt, timeMinutes)); err != nil {
+		return EstimateResponse{}, err
+	}
+
+	breakdown := s.fees.Calculate(int64(totalPrice), routeDistance, len(route))
+
+	promotions, err := s.applyPromotions(ctx, userID, req.VoucherCode, basket, breakdown)
+	if err != nil {
+		return EstimateResponse{}, err
+	}
+	discounts := make([]DiscountLine, len(promotions))
+	for i, p := range promotions {
+		discounts[i] = p.Line
+		breakdown.Discount += p.Line.Amount
+	}
 
 	repository := NewPurchaseRepository(s.db)
-	estimate, err := repository.CreateEstimateWithOrders(ctx,
-		userID,
-		req.UserLocation.Lat,
-		req.UserLocation.Long,
-		float64(totalPrice),
-		timeMinutes,
-		req.Orders)
+	estimate, err := repository.CreateEstimateWithOrders(ctx, userID, EstimateInput{
+		UserLat:                        req.UserLocation.Lat,
+		UserLng:                        req.UserLocation.Long,
+		PriceBreakdown:                 breakdown,
+		EstimatedDeliveryTimeInMinutes: timeMinutes,
+		FinalLeg:                       deliveryRoute.FinalLeg,
+		Stops:                          stops,
+		ExpiresAt:                      time.Now().Add(s.estimateTTL),
+		ScheduledDeliveryAt:            req.ScheduledDeliveryAt,
+		Promotions:                     promotions,
+	})
 	if err != nil {
 		return EstimateResponse{}, fmt.Errorf("failed to save estimate: %w", err)
 	}
@@ -260,79 +388,297 @@ func (s *PurchaseService) ValidateAndEstimate(ctx context.Context, userID uuid.U
 		TotalPrice:                     estimate.TotalPrice,
 		EstimatedDeliveryTimeInMinutes: int(estimate.EstimatedDeliveryTimeInMinutes),
 		CalculatedEstimateId:           estimate.ID.String(),
+		ExpiresAt:                      estimate.ExpiresAt.Format(time.RFC3339Nano),
+		ScheduledDeliveryAt:            formatOptionalTime(estimate.ScheduledDeliveryAt),
+		Route:                          deliveryRoute,
+		StartingPointMerchantID:        route[0].MerchantID,
+		StartingPointAutoSelected:      startCount == 0,
+		PriceBreakdown:                 breakdown,
+		Discounts:                      discounts,
 	}, nil
 }
 
-func (s *PurchaseService) CreateOrderByEstimateId(ctx context.Context, userID uuid.UUID, estimateID uuid.UUID) (CreateOrderResponse, error) {
+// validateSchedule checks a requested delivery time against the scheduling
+// window. The window never opens before the route's own ETA, since an order
+// cannot be delivered faster than it can be prepared and driven.
+func (s *PurchaseService) validateSchedule(deliverAt *time.Time, etaMinutes int) error {
+	if deliverAt == nil {
+		return nil
+	}
+
+	now := time.Now()
+	lead := max(s.schedule.MinLeadTime, time.Duration(etaMinutes)*time.Minute)
+	if deliverAt.Before(now.Add(lead)) {
+		return ErrScheduleTooSoon
+	}
+	if deliverAt.After(now.Add(s.schedule.MaxHorizon)) {
+		return ErrScheduleTooFar
+	}
+	return nil
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
+
+func (s *PurchaseService) CreateOrderByEstimateId(ctx context.Context, userID uuid.UUID, estimateID uuid.UUID, reprice bool) (CreateOrderResponse, error) {
 	repository := NewPurchaseRepository(s.db)
 
-	_, err := repository.GetEstimateById(ctx, estimateID)
+	estimate, err := repository.GetEstimateById(ctx, estimateID)
+	if err != nil {
+		return CreateOrderResponse{}, ErrEstimateNotFound
+	}
+
+	if time.Now().After(estimate.ExpiresAt) {
+		return CreateOrderResponse{}, ErrEstimateExpired
+	}
+
+	// estimate terjadwal bisa dipesan mendekati jadwalnya; ETA harus masih terkejar
+	if estimate.ScheduledDeliveryAt != nil {
+		eta := time.Duration(estimate.EstimatedDeliveryTimeInMinutes) * time.Minute
+		if time.Now().Add(eta).After(*estimate.ScheduledDeliveryAt) {
+			return CreateOrderResponse{}, ErrScheduleTooSoon
+		}
+	}
+
+	// merchant bisa saja tutup di antara estimate dan order
+	estimateOrders, err := s.queries.GetEstimateOrderIds(ctx, estimate.ID)
+	if err != nil {
+		return CreateOrderResponse{}, fmt.Errorf("failed to get estimate merchants: %w", err)
+	}
+	merchantIDs := make([]uuid.UUID, len(estimateOrders))
+	for i, eo := range estimateOrders {
+		merchantIDs[i] = eo.MerchantID
+	}
+	err = s.checkMerchantsOpen(ctx, merchantIDs, preparationStart(estimate.ScheduledDeliveryAt, estimate.EstimatedDeliveryTimeInMinutes))
 	if err != nil {
-		return CreateOrderResponse{}, errors.New("estimate not found")
+		return CreateOrderResponse{}, err
 	}
 
-	order, err := repository.CreateOrderFromEstimate(ctx, userID, estimateID)
+	var pricing *OrderPricing
+	var repricing *Repricing
+	if reprice {
+		pricing, err = s.repriceEstimate(ctx, estimate)
+		if err != nil {
+			return CreateOrderResponse{}, err
+		}
+		repricing = &Repricing{
+			EstimatedTotalPrice: estimate.TotalPrice,
+			TotalPrice:          pricing.PriceBreakdown.Total(),
+			Difference:          pricing.PriceBreakdown.Total() - estimate.TotalPrice,
+			PriceBreakdown:      pricing.PriceBreakdown,
+			Discounts:           make([]DiscountLine, len(pricing.Promotions)),
+		}
+		for i, p := range pricing.Promotions {
+			repricing.Discounts[i] = p.Line
+		}
+	}
+
+	created, err := repository.CreateOrderFromEstimate(ctx, userID, estimateID, pricing)
 	if err != nil {
+		if errors.Is(err, ErrOrderAlreadyExists) || errors.Is(err, ErrPromotionUnavailable) || errors.Is(err, ErrItemOutOfStock) {
+			return CreateOrderResponse{}, err
+		}
 		return CreateOrderResponse{}, fmt.Errorf("failed to create order from estimate: %w", err)
 	}
 
+	// stok item berubah, daftar item admin di cache harus ikut berubah
+	for _, merchantID := range merchantIDs {
+		if err := items.InvalidateMerchantItemsCache(ctx, s.cache, merchantID); err != nil {
+			logger.WarnCtx(ctx, "Failed to invalidate merchant items cache", "merchantId", merchantID, "error", err)
+		}
+	}
+
+	deliveryAt := time.Now().Add(time.Duration(created.EstimatedDeliveryTimeInMinutes) * time.Minute)
+	if created.ScheduledDeliveryAt != nil {
+		deliveryAt = *created.ScheduledDeliveryAt
+	}
+	s.events.Publish(ctx, created.ID, order.OrderEvent{
+		Type:                order.EventCreated,
+		Status:              string(database.OrderStatusPlaced),
+		EstimatedDeliveryAt: deliveryAt.Format(time.RFC3339),
+	})
+
 	return CreateOrderResponse{
-		OrderId: order.ID.String(),
+		OrderId:   created.ID.String(),
+		Repricing: repricing,
+		ReleaseAt: formatOptionalTime(created.ReleaseAt),
 	}, nil
 }
-func (s *PurchaseService) GetMerchantsNearby(ctx context.Context, lat float64, lng float64, name string) (GetMerchantsNearbyResponse, error) {
-	rows, err := s.queries.GetAllMerchantsWithItemsSortedByH3Distance(ctx, database.GetAllMerchantsWithItemsSortedByH3DistanceParams{Point: lat, Point_2: lng, Column3: name})
+
+// repriceEstimate prices the estimate's items at their current price. Route
+// fees stay as quoted since the route itself has not changed; the small order
+// fee and the promotion discounts are recomputed for the new items total.
+func (s *PurchaseService) repriceEstimate(ctx context.Context, estimate database.Estimates) (*OrderPricing, error) {
+	rows, err := s.queries.GetEstimateCurrentSubtotals(ctx, estimate.ID)
 	if err != nil {
-		return GetMerchantsNearbyResponse{}, fmt.Errorf("failed to fetch merchants with items: %w", err)
-	}
-
-	merchantMap := make(map[string]*MerchantWithItemsResponse)
-	for _, row := range rows {
-		merchantID := row.MerchantID.String()
-
-		if _, exists := merchantMap[merchantID]; !exists {
-			merchantMap[merchantID] = &MerchantWithItemsResponse{
-				Merchant: MerchantInfo{
-					MerchantID:       merchantID,
-					Name:             row.MerchantName,
-					MerchantCategory: row.MerchantCategory,
-					ImageUrl:         row.MerchantImageUrl,
-					Location: Location{
-						Lat:  row.Lat,
-						Long: row.Lng,
-					},
-					CreatedAt: row.MerchantCreatedAt.Format("2006-01-02T15:04:05.999999999Z07:00"),
-				},
-				Items: []ItemInfo{},
-			}
+		return nil, fmt.Errorf("failed to reprice estimate: %w", err)
+	}
+
+	pricing := &OrderPricing{Subtotals: make(map[uuid.UUID]int64, len(rows))}
+	basket := make([]basketLine, len(rows))
+	itemsTotal := int64(0)
+	for i, row := range rows {
+		pricing.Subtotals[row.MerchantID] += row.Subtotal
+		basket[i] = basketLine{
+			MerchantID:      row.MerchantID,
+			ProductCategory: row.ProductCategory,
+			Amount:          row.Subtotal,
 		}
+		itemsTotal += row.Subtotal
+	}
 
-		if row.ItemID != uuid.Nil {
-			merchantMap[merchantID].Items = append(merchantMap[merchantID].Items, ItemInfo{
-				ItemID:          row.ItemID.String(),
-				Name:            row.ItemName,
-				ProductCategory: row.ProductCategory,
-				Price:           row.Price,
-				ImageUrl:        row.ItemImageUrl,
-				CreatedAt:       row.ItemCreatedAt.Format("2006-01-02T15:04:05.999999999Z07:00"),
-			})
+	breakdown := s.fees.Reprice(PriceBreakdown{
+		ItemsTotal:    estimate.ItemsTotal,
+		BaseFee:       estimate.BaseFee,
+		DistanceFee:   estimate.DistanceFee,
+		ExtraStopFee:  estimate.ExtraStopFee,
+		SmallOrderFee: estimate.SmallOrderFee,
+		ServiceFee:    estimate.ServiceFee,
+	}, itemsTotal)
+
+	pricing.Promotions, err = s.repricePromotions(ctx, estimate.ID, basket, breakdown)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pricing.Promotions {
+		breakdown.Discount += p.Line.Amount
+	}
+	pricing.PriceBreakdown = breakdown
+
+	return pricing, nil
+}
+
+// GetMerchantsNearby pages merchants nearest first and attaches their items
+// without disturbing that order.
+//
+// Instead of measuring every merchant, the search starts with the H3 rings
+// covering the default delivery radius and doubles the radius until the page
+// is full or nearbyRadius is reached. A full page found within a radius is
+// final: every merchant not yet seen is farther away than all of it. The
+// total counts the merchants within that same radius, so the count does not
+// pay for the full nearbyRadius scan the ring search avoided.
+func (s *PurchaseService) GetMerchantsNearby(ctx context.Context, filter NearbyMerchantFilter) (GetMerchantsNearbyResponse, error) {
+	params := database.ListNearbyMerchantsParams{
+		Lat:              filter.Lat,
+		Lng:              filter.Lng,
+		Name:             filter.Name,
+		MerchantID:       filter.MerchantID,
+		MerchantCategory: filter.MerchantCategory,
+		LimitCount:       int32(filter.Limit),
+		OffsetCount:      int32(filter.Offset),
+	}
+
+	var merchants []database.ListNearbyMerchantsRow
+	radius := math.Min(utils.DEFAULT_DELIVERY_RADIUS_M, s.nearbyRadius)
+	for {
+		params.Rings = utils.H3SearchRings(radius)
+		params.MaxDistance = radius
+
+		var err error
+		merchants, err = s.queries.ListNearbyMerchants(ctx, params)
+		if err != nil {
+			return GetMerchantsNearbyResponse{}, fmt.Errorf("failed to fetch nearby merchants: %w", err)
+		}
+		if len(merchants) == filter.Limit || radius >= s.nearbyRadius {
+			break
+		}
+		radius = math.Min(radius*2, s.nearbyRadius)
+	}
+
+	total, err := s.queries.CountNearbyMerchants(ctx, database.CountNearbyMerchantsParams{
+		Lat:              filter.Lat,
+		Lng:              filter.Lng,
+		Rings:            params.Rings,
+		MaxDistance:      radius,
+		Name:             filter.Name,
+		MerchantID:       filter.MerchantID,
+		MerchantCategory: filter.MerchantCategory,
+	})
+	if err != nil {
+		return GetMerchantsNearbyResponse{}, fmt.Errorf("failed to count nearby merchants: %w", err)
+	}
+
+	merchantIDs := make([]uuid.UUID, 0, len(merchants))
+	for _, m := range merchants {
+		merchantIDs = append(merchantIDs, m.ID)
+	}
+
+	schedules := map[uuid.UUID]utils.OpeningSchedule{}
+	if len(merchantIDs) > 0 {
+		schedules, err = s.loadOpeningSchedules(ctx, merchantIDs)
+		if err != nil {
+			return GetMerchantsNearbyResponse{}, err
 		}
 	}
 
-	var data []MerchantWithItemsResponse
-	for _, m := range merchantMap {
-		data = append(data, *m)
+	now := time.Now()
+	data := make([]MerchantWithItemsResponse, 0, len(merchants))
+	// index ke data, supaya item bisa ditempel tanpa mengubah urutan jarak
+	position := make(map[uuid.UUID]int, len(merchants))
+	for _, m := range merchants {
+		position[m.ID] = len(data)
+		data = append(data, MerchantWithItemsResponse{
+			Merchant: MerchantInfo{
+				MerchantID:       m.ID.String(),
+				Name:             m.Name,
+				MerchantCategory: m.MerchantCategory,
+				ImageUrl:         m.ImageUrl,
+				Location: Location{
+					Lat:  m.Lat,
+					Long: m.Lng,
+				},
+				CreatedAt:        m.CreatedAt.Format("2006-01-02T15:04:05.999999999Z07:00"),
+				DistanceInMeters: m.DistanceMeters,
+				IsOpen:           schedules[m.ID].IsOpenAt(now),
+			},
+			Items: []ItemInfo{},
+		})
 	}
 
-	// TODO: Untuk production, pertimbangkan pagination di DB level (lebih kompleks karena grouping)
-	// Untuk sekarang, kita asumsikan jumlah merchant terbatas (<100)
+	if len(merchantIDs) > 0 {
+		merchantItems, err := s.queries.ListItemsByMerchantIds(ctx, merchantIDs)
+		if err != nil {
+			return GetMerchantsNearbyResponse{}, fmt.Errorf("failed to fetch merchant items: %w", err)
+		}
+
+		itemIDs := make([]uuid.UUID, len(merchantItems))
+		for i, item := range merchantItems {
+			itemIDs[i] = item.ID
+		}
+		optionGroups, err := items.ListOptionGroups(ctx, s.queries, itemIDs)
+		if err != nil {
+			return GetMerchantsNearbyResponse{}, err
+		}
+
+		for _, item := range merchantItems {
+			idx := position[item.MerchantID]
+			groups := optionGroups[item.ID]
+			if groups == nil {
+				groups = []OptionGroupInfo{}
+			}
+			data[idx].Items = append(data[idx].Items, ItemInfo{
+				ItemID:          item.ID.String(),
+				Name:            item.Name,
+				ProductCategory: item.ProductCategory,
+				Price:           item.Price,
+				ImageUrl:        item.ImageUrl,
+				CreatedAt:       item.CreatedAt.Format("2006-01-02T15:04:05.999999999Z07:00"),
+				OptionGroups:    groups,
+			})
+		}
+	}
 
 	return GetMerchantsNearbyResponse{
 		Data: data,
 		Meta: PaginationMeta{
-			Limit:  0, // bisa diisi jika ada pagination
-			Offset: 0,
-			Total:  len(data),
+			Limit:  filter.Limit,
+			Offset: filter.Offset,
+			Total:  int(total),
 		},
 	}, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,49 @@
+package purchase
+
+import (
+	"errors"
+	"testing"
+
+	"belimang/internal/pkg/utils"
+
+	"github.com/uber/h3-go/v4"
+)
+
+func TestValidateDeliveryDistance(t *testing.T) {
+	user := testPoint(t, "user", 0, 0)
+	userZone, _ := user.H3Cell.Parent(7)
+	otherZone, _ := testPoint(t, "other", 50000, 0).H3Cell.Parent(7)
+
+	tests := []struct {
+		name    string
+		east    float64
+		radius  float64 // relative to the exact distance, see below
+		zone    []h3.Cell
+		wantErr error
+	}{
+		{"just inside 3km", 2990, 1, nil, nil},
+		{"just outside 3km", 3010, -1, nil, ErrCoordinatesTooFar},
+		{"just inside 10km", 9990, 1, nil, nil},
+		{"just outside 10km", 10010, -1, nil, ErrCoordinatesTooFar},
+		{"far inside", 500, 2000, nil, nil},
+		{"far outside", 8000, -5000, nil, ErrCoordinatesTooFar},
+		{"inside radius and zone", 1000, 1, []h3.Cell{userZone}, nil},
+		{"inside radius, outside zone", 1000, 1, []h3.Cell{otherZone}, ErrCoordinatesTooFar},
+		{"inside zone, outside radius", 1000, -1, []h3.Cell{userZone}, ErrCoordinatesTooFar},
+	}
+
+	s := &PurchaseService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := testPoint(t, "merchant", tt.east, 0)
+			// radius sengaja diletakkan tepat di sekitar jarak Haversine, sama seperti query nearby
+			mp.RadiusMeters = utils.HaversineDistance(user.Lat, user.Lng, mp.Lat, mp.Lng) + tt.radius
+			mp.ServiceZone = tt.zone
+
+			err := s.validateDeliveryDistance(user, []merchantPoint{mp})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateDeliveryDistance() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
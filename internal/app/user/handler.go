@@ -41,7 +41,17 @@ func (h *UserHandler) LoginAdmin(c *gin.Context) {
 	h.login(c, UserRoleAdmin)
 }
 
-// register handles registration for both users and admins
+// RegisterCourier creates a new courier account
+func (h *UserHandler) RegisterCourier(c *gin.Context) {
+	h.register(c, UserRoleCourier)
+}
+
+// LoginCourier authenticates a courier
+func (h *UserHandler) LoginCourier(c *gin.Context) {
+	h.login(c, UserRoleCourier)
+}
+
+// register handles registration for every role
 func (h *UserHandler) register(c *gin.Context, role UserRole) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -82,7 +92,7 @@ func (h *UserHandler) register(c *gin.Context, role UserRole) {
 	c.JSON(http.StatusCreated, &AuthResponse{Token: token})
 }
 
-// login handles login for both users and admins
+// login handles login for every role
 func (h *UserHandler) login(c *gin.Context, role UserRole) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
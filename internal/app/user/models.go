@@ -8,8 +8,9 @@ import (
 type UserRole string
 
 const (
-	UserRoleUser  UserRole = "user"
-	UserRoleAdmin UserRole = "admin"
+	UserRoleUser    UserRole = "user"
+	UserRoleAdmin   UserRole = "admin"
+	UserRoleCourier UserRole = "courier"
 )
 
 // User represents the user entity in the domain
@@ -16,4 +16,10 @@ func RegisterRoutes(router *gin.Engine, handler *UserHandler) {
 		admin.POST("/register", handler.RegisterAdmin)
 		admin.POST("/login", handler.LoginAdmin)
 	}
+
+	couriers := router.Group("/couriers")
+	{
+		couriers.POST("/register", handler.RegisterCourier)
+		couriers.POST("/login", handler.LoginCourier)
+	}
 }
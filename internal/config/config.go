@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,12 +15,17 @@ type Config struct {
 	Cache    CacheConfig    `json:"cache"`
 	Logger   LoggerConfig   `json:"logger"`
 	JWT      JWTConfig      `json:"jwt"`
+	Delivery DeliveryConfig `json:"delivery"`
+	Dispatch DispatchConfig `json:"dispatch"`
+
+	MerchantImport MerchantImportConfig `json:"merchant_import"`
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Host string `json:"host"`
-	Port int    `json:"port"`
+	Host            string        `json:"host"`
+	Port            int           `json:"port"`
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"` // how long open requests and workers get to finish
 }
 
 // DatabaseConfig holds database configuration
@@ -54,6 +60,45 @@ type JWTConfig struct {
 	Issuer    string `json:"issuer"`
 }
 
+// DeliveryConfig holds delivery estimation configuration
+type DeliveryConfig struct {
+	Fee                   FeeConfig      `json:"fee"`
+	EstimateTTL           time.Duration  `json:"estimate_ttl"`             // how long an estimate can be ordered
+	NearbyMaxRadiusMeters float64        `json:"nearby_max_radius_meters"` // how far the nearby search expands from the user
+	Schedule              ScheduleConfig `json:"schedule"`
+}
+
+// ScheduleConfig holds the limits of deliver-later orders
+type ScheduleConfig struct {
+	MinLeadTime     time.Duration `json:"min_lead_time"`    // earliest scheduled delivery, counted from now
+	MaxHorizon      time.Duration `json:"max_horizon"`      // latest scheduled delivery, counted from now
+	ReleaseInterval time.Duration `json:"release_interval"` // how often due scheduled orders are released
+}
+
+// FeeConfig holds the delivery fee tiers, in the same currency unit as item prices
+type FeeConfig struct {
+	BaseFee             int64 `json:"base_fee"`              // flat fee per order
+	PerKmFee            int64 `json:"per_km_fee"`            // per started km of the route
+	PerExtraStopFee     int64 `json:"per_extra_stop_fee"`    // per merchant after the first
+	SmallOrderThreshold int64 `json:"small_order_threshold"` // baskets below this pay SmallOrderFee
+	SmallOrderFee       int64 `json:"small_order_fee"`
+	ServiceFee          int64 `json:"service_fee"` // flat platform fee per order
+}
+
+// DispatchConfig holds courier dispatch configuration
+type DispatchConfig struct {
+	Interval        time.Duration `json:"interval"`          // how often undispatched orders are scanned
+	OfferTimeout    time.Duration `json:"offer_timeout"`     // how long a courier has to accept an offer
+	MaxRadiusMeters float64       `json:"max_radius_meters"` // how far from the starting merchant couriers are searched
+	LocationTTL     time.Duration `json:"location_ttl"`      // couriers without a newer location are treated as offline
+}
+
+// MerchantImportConfig holds the background merchant import configuration
+type MerchantImportConfig struct {
+	Interval   time.Duration `json:"interval"`    // how often waiting imports are picked up
+	StaleAfter time.Duration `json:"stale_after"` // running imports without progress for this long are taken over
+}
+
 // LoadConfig loads configuration from .env file
 func LoadConfig(envPath string) (*Config, error) {
 	// Load .env file
@@ -88,8 +133,9 @@ func LoadConfig(envPath string) (*Config, error) {
 
 	config := &Config{
 		Server: ServerConfig{
-			Host: getEnv("SERVER_HOST", "localhost"),
-			Port: serverPort,
+			Host:            getEnv("SERVER_HOST", "localhost"),
+			Port:            serverPort,
+			ShutdownTimeout: time.Duration(getEnvInt64("SERVER_SHUTDOWN_TIMEOUT_SECONDS", 30)) * time.Second,
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -115,6 +161,34 @@ func LoadConfig(envPath string) (*Config, error) {
 			SecretKey: getEnv("JWT_SECRET_KEY", "your-secret-key"),
 			Issuer:    getEnv("JWT_ISSUER", "belimang-app"),
 		},
+		Delivery: DeliveryConfig{
+			EstimateTTL: time.Duration(getEnvInt64("ESTIMATE_TTL_MINUTES", 15)) * time.Minute,
+			// sama dengan radius pengiriman maksimum yang bisa diset merchant
+			NearbyMaxRadiusMeters: float64(getEnvInt64("NEARBY_MAX_RADIUS_METERS", 20000)),
+			Fee: FeeConfig{
+				BaseFee:             getEnvInt64("FEE_BASE", 0),
+				PerKmFee:            getEnvInt64("FEE_PER_KM", 0),
+				PerExtraStopFee:     getEnvInt64("FEE_PER_EXTRA_STOP", 0),
+				SmallOrderThreshold: getEnvInt64("FEE_SMALL_ORDER_THRESHOLD", 0),
+				SmallOrderFee:       getEnvInt64("FEE_SMALL_ORDER", 0),
+				ServiceFee:          getEnvInt64("FEE_SERVICE", 0),
+			},
+			Schedule: ScheduleConfig{
+				MinLeadTime:     time.Duration(getEnvInt64("SCHEDULE_MIN_LEAD_MINUTES", 30)) * time.Minute,
+				MaxHorizon:      time.Duration(getEnvInt64("SCHEDULE_MAX_HORIZON_HOURS", 168)) * time.Hour,
+				ReleaseInterval: time.Duration(getEnvInt64("SCHEDULE_RELEASE_INTERVAL_SECONDS", 30)) * time.Second,
+			},
+		},
+		Dispatch: DispatchConfig{
+			Interval:        time.Duration(getEnvInt64("DISPATCH_INTERVAL_SECONDS", 5)) * time.Second,
+			OfferTimeout:    time.Duration(getEnvInt64("DISPATCH_OFFER_TIMEOUT_SECONDS", 30)) * time.Second,
+			MaxRadiusMeters: float64(getEnvInt64("DISPATCH_MAX_RADIUS_METERS", 5000)),
+			LocationTTL:     time.Duration(getEnvInt64("COURIER_LOCATION_TTL_SECONDS", 120)) * time.Second,
+		},
+		MerchantImport: MerchantImportConfig{
+			Interval:   time.Duration(getEnvInt64("MERCHANT_IMPORT_INTERVAL_SECONDS", 5)) * time.Second,
+			StaleAfter: time.Duration(getEnvInt64("MERCHANT_IMPORT_STALE_MINUTES", 5)) * time.Minute,
+		},
 	}
 
 	return config, nil
@@ -127,3 +201,12 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt64 parses an integer environment variable, returning defaultValue when unset or invalid
+func getEnvInt64(key string, defaultValue int64) int64 {
+	value, err := strconv.ParseInt(getEnv(key, ""), 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -26,25 +26,38 @@ type CacheConfig struct {
 
 // Cache key constants for consistency
 const (
-	UserFileListKey   = "user:files:%s"      // user:files:{userID}
-	FileMetadataKey   = "file:metadata:%s"   // file:metadata:{fileID}
-	FileExistsKey     = "file:exists:%s"     // file:exists:{fileID}
-	ProductListKey    = "products:list:%s"   // products:list:{filters_hash}
-	ProductKey        = "product:%s"         // product:{productID}
-	UserProfileKey    = "user:profile:%s"    // user:profile:{userID}
-	MerchantKey       = "merchant:%s"        // merchant:{merchantID}
-	MerchantExistsKey = "merchant:exists:%s" // merchant:exists:{merchantID}
+	UserFileListKey    = "user:files:%s"       // user:files:{userID}
+	FileMetadataKey    = "file:metadata:%s"    // file:metadata:{fileID}
+	FileExistsKey      = "file:exists:%s"      // file:exists:{fileID}
+	ProductListKey     = "products:list:%s"    // products:list:{filters_hash}
+	ProductKey         = "product:%s"          // product:{productID}
+	UserProfileKey     = "user:profile:%s"     // user:profile:{userID}
+	MerchantKey        = "merchant:%s"         // merchant:{merchantID}
+	MerchantExistsKey  = "merchant:exists:%s"  // merchant:exists:{merchantID}
+	ItemsVersionKey    = "items_version:%s"    // items_version:{merchantID} -> bumped on every item write
+	IdempotencyKey     = "idempotency:%s"      // idempotency:{scope hash}
+	CourierLocationKey = "courier:location:%s" // courier:location:{courierID}
+	CourierCellKey     = "courier:cell:%s"     // courier:cell:{h3 cell} -> set of courier IDs
+	CourierOfferKey    = "courier:offer:%s"    // courier:offer:{courierID}
+	DispatchOfferKey   = "dispatch:offer:%s"   // dispatch:offer:{orderID}
+	DispatchOfferedKey = "dispatch:offered:%s" // dispatch:offered:{orderID} -> set of courier IDs
+	OrderEventsChannel = "order:events:%s"     // order:events:{orderID} pub/sub channel
+	OrderEventsLogKey  = "order:events:log:%s" // order:events:log:{orderID} -> recent events
+	OrderEventsSeqKey  = "order:events:seq:%s" // order:events:seq:{orderID} -> last event ID
 )
 
 // TTL constants for different data types
 const (
-	FileMetadataTTL = 1 * time.Hour    // File metadata rarely changes
-	FileListTTL     = 30 * time.Minute // User file lists change more often
-	FileExistsTTL   = 5 * time.Minute  // Quick existence checks
-	ProductListTTL  = 10 * time.Minute // Product search results
-	ProductTTL      = 30 * time.Minute // Individual products
-	UserProfileTTL  = 15 * time.Minute // User profiles
-	MerchantTTL     = 30 * time.Minute // merchant:{merchantID}
+	FileMetadataTTL    = 1 * time.Hour    // File metadata rarely changes
+	FileListTTL        = 30 * time.Minute // User file lists change more often
+	FileExistsTTL      = 5 * time.Minute  // Quick existence checks
+	ProductListTTL     = 10 * time.Minute // Product search results
+	ProductTTL         = 30 * time.Minute // Individual products
+	UserProfileTTL     = 15 * time.Minute // User profiles
+	MerchantTTL        = 30 * time.Minute // merchant:{merchantID}
+	IdempotencyTTL     = 24 * time.Hour   // replayed responses for retried requests
+	DispatchOfferedTTL = 6 * time.Hour    // couriers already asked about an order
+	OrderEventsTTL     = 24 * time.Hour   // replayable order events for reconnecting clients
 )
 
 func NewRedisCache(config config.CacheConfig) *RedisCache {
@@ -118,6 +131,91 @@ func (c *RedisCache) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+// SetNX stores value only if key does not exist yet, reporting whether it was stored
+func (c *RedisCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Redis SETNX marshal failed", "key", key, "error", err)
+		return false, err
+	}
+
+	ok, err := c.client.SetNX(ctx, key, jsonData, expiration).Result()
+	if err != nil {
+		logger.ErrorCtx(ctx, "Redis SETNX failed", "key", key, "error", err)
+		return false, err
+	}
+	logger.DebugCtx(ctx, "Redis SETNX", "key", key, "stored", ok)
+	return ok, nil
+}
+
+// deleteIfEqualsScript deletes KEYS[1] only while it holds ARGV[1]
+var deleteIfEqualsScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// DeleteIfEquals deletes key only while it still holds value, so a lock that
+// expired and was taken by someone else is left alone. It reports whether
+// the key was deleted.
+func (c *RedisCache) DeleteIfEquals(ctx context.Context, key string, value interface{}) (bool, error) {
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Redis DELETE IF EQUALS marshal failed", "key", key, "error", err)
+		return false, err
+	}
+
+	deleted, err := deleteIfEqualsScript.Run(ctx, c.client, []string{key}, jsonData).Int()
+	if err != nil {
+		logger.ErrorCtx(ctx, "Redis DELETE IF EQUALS failed", "key", key, "error", err)
+		return false, err
+	}
+	logger.DebugCtx(ctx, "Redis DELETE IF EQUALS", "key", key, "deleted", deleted == 1)
+	return deleted == 1, nil
+}
+
+// SetAdd adds members to a Redis set and refreshes the set's expiry
+func (c *RedisCache) SetAdd(ctx context.Context, key string, expiration time.Duration, members ...string) error {
+	pipe := c.client.TxPipeline()
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	pipe.SAdd(ctx, key, args...)
+	pipe.Expire(ctx, key, expiration)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.ErrorCtx(ctx, "Redis SADD failed", "key", key, "error", err)
+		return err
+	}
+	logger.DebugCtx(ctx, "Redis SADD success", "key", key, "count", len(members), "ttl", expiration)
+	return nil
+}
+
+// SetRemove removes members from a Redis set
+func (c *RedisCache) SetRemove(ctx context.Context, key string, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	err := c.client.SRem(ctx, key, args...).Err()
+	if err != nil {
+		logger.ErrorCtx(ctx, "Redis SREM failed", "key", key, "error", err)
+	}
+	return err
+}
+
+// SetMembers returns every member of a Redis set; a missing set is empty
+func (c *RedisCache) SetMembers(ctx context.Context, key string) ([]string, error) {
+	members, err := c.client.SMembers(ctx, key).Result()
+	if err != nil {
+		logger.ErrorCtx(ctx, "Redis SMEMBERS failed", "key", key, "error", err)
+		return nil, err
+	}
+	return members, nil
+}
+
 func (c *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
 	result, err := c.client.Exists(ctx, key).Result()
 	if err != nil {
@@ -12,21 +12,115 @@ import (
 	"github.com/google/uuid"
 )
 
+const addEstimateOrderItemOptions = `-- name: AddEstimateOrderItemOptions :exec
+INSERT INTO estimate_order_item_options (
+    estimate_order_item_id, option_id, group_name, option_name, price_delta
+)
+SELECT
+    $1,
+    UNNEST($2::uuid[]),
+    UNNEST($3::text[]),
+    UNNEST($4::text[]),
+    UNNEST($5::bigint[])
+`
+
+type AddEstimateOrderItemOptionsParams struct {
+	EstimateOrderItemID uuid.UUID   `json:"estimate_order_item_id"`
+	OptionIds           []uuid.UUID `json:"option_ids"`
+	GroupNames          []string    `json:"group_names"`
+	OptionNames         []string    `json:"option_names"`
+	PriceDeltas         []int64     `json:"price_deltas"`
+}
+
+func (q *Queries) AddEstimateOrderItemOptions(ctx context.Context, arg AddEstimateOrderItemOptionsParams) error {
+	_, err := q.db.Exec(ctx, addEstimateOrderItemOptions,
+		arg.EstimateOrderItemID,
+		arg.OptionIds,
+		arg.GroupNames,
+		arg.OptionNames,
+		arg.PriceDeltas,
+	)
+	return err
+}
+
+const countNearbyMerchants = `-- name: CountNearbyMerchants :one
+SELECT COUNT(*)
+FROM merchants m
+WHERE m.deleted_at IS NULL
+    AND h3_cell_to_parent(m.h3_index, 8) IN (
+        SELECT h3_grid_disk(h3_latlng_to_cell(Point($1::float8, $2::float8), 8), $3::int)
+    )
+    AND haversine_meters($1::float8, $2::float8, m.lat, m.lng) <= LEAST(m.delivery_radius_meters, $4::float8)
+    AND ($5::text = '' OR m.name ILIKE '%' || $5::text || '%')
+    AND ($6::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR m.id = $6::uuid)
+    AND ($7::text = '' OR m.merchant_category = $7::text)
+    AND (
+        NOT EXISTS (SELECT 1 FROM merchant_service_zones z WHERE z.merchant_id = m.id)
+        OR EXISTS (
+            SELECT 1 FROM merchant_service_zones z
+            WHERE z.merchant_id = m.id
+                AND h3_cell_to_parent(h3_latlng_to_cell(Point($1::float8, $2::float8), 10), h3_get_resolution(z.h3_cell)) = z.h3_cell
+        )
+    )
+    AND EXISTS (SELECT 1 FROM items i WHERE i.merchant_id = m.id AND i.deleted_at IS NULL)
+`
+
+type CountNearbyMerchantsParams struct {
+	Lat              float64   `json:"lat"`
+	Lng              float64   `json:"lng"`
+	Rings            int       `json:"rings"`
+	MaxDistance      float64   `json:"max_distance"`
+	Name             string    `json:"name"`
+	MerchantID       uuid.UUID `json:"merchant_id"`
+	MerchantCategory string    `json:"merchant_category"`
+}
+
+func (q *Queries) CountNearbyMerchants(ctx context.Context, arg CountNearbyMerchantsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countNearbyMerchants,
+		arg.Lat,
+		arg.Lng,
+		arg.Rings,
+		arg.MaxDistance,
+		arg.Name,
+		arg.MerchantID,
+		arg.MerchantCategory,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createEstimate = `-- name: CreateEstimate :one
 INSERT INTO estimates (
-    user_id, user_lat, user_lng, total_price, estimated_delivery_time_in_minutes
+    user_id, user_lat, user_lng, total_price, estimated_delivery_time_in_minutes,
+    final_leg_distance_meters, final_leg_time_in_minutes,
+    items_total, base_fee, distance_fee, extra_stop_fee, small_order_fee, service_fee,
+    expires_at, scheduled_delivery_at, discount_total
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7,
+    $8, $9, $10, $11, $12, $13,
+    $14, $15, $16
 )
 RETURNING id, total_price, estimated_delivery_time_in_minutes
 `
 
 type CreateEstimateParams struct {
-	UserID                         uuid.UUID `json:"user_id"`
-	UserLat                        float64   `json:"user_lat"`
-	UserLng                        float64   `json:"user_lng"`
-	TotalPrice                     int64     `json:"total_price"`
-	EstimatedDeliveryTimeInMinutes int       `json:"estimated_delivery_time_in_minutes"`
+	UserID                         uuid.UUID  `json:"user_id"`
+	UserLat                        float64    `json:"user_lat"`
+	UserLng                        float64    `json:"user_lng"`
+	TotalPrice                     int64      `json:"total_price"`
+	EstimatedDeliveryTimeInMinutes int        `json:"estimated_delivery_time_in_minutes"`
+	FinalLegDistanceMeters         float64    `json:"final_leg_distance_meters"`
+	FinalLegTimeInMinutes          int        `json:"final_leg_time_in_minutes"`
+	ItemsTotal                     int64      `json:"items_total"`
+	BaseFee                        int64      `json:"base_fee"`
+	DistanceFee                    int64      `json:"distance_fee"`
+	ExtraStopFee                   int64      `json:"extra_stop_fee"`
+	SmallOrderFee                  int64      `json:"small_order_fee"`
+	ServiceFee                     int64      `json:"service_fee"`
+	ExpiresAt                      time.Time  `json:"expires_at"`
+	ScheduledDeliveryAt            *time.Time `json:"scheduled_delivery_at"`
+	DiscountTotal                  int64      `json:"discount_total"`
 }
 
 type CreateEstimateRow struct {
@@ -42,6 +136,17 @@ func (q *Queries) CreateEstimate(ctx context.Context, arg CreateEstimateParams)
 		arg.UserLng,
 		arg.TotalPrice,
 		arg.EstimatedDeliveryTimeInMinutes,
+		arg.FinalLegDistanceMeters,
+		arg.FinalLegTimeInMinutes,
+		arg.ItemsTotal,
+		arg.BaseFee,
+		arg.DistanceFee,
+		arg.ExtraStopFee,
+		arg.SmallOrderFee,
+		arg.ServiceFee,
+		arg.ExpiresAt,
+		arg.ScheduledDeliveryAt,
+		arg.DiscountTotal,
 	)
 	var i CreateEstimateRow
 	err := row.Scan(&i.ID, &i.TotalPrice, &i.EstimatedDeliveryTimeInMinutes)
@@ -50,127 +155,72 @@ func (q *Queries) CreateEstimate(ctx context.Context, arg CreateEstimateParams)
 
 const createEstimateOrder = `-- name: CreateEstimateOrder :exec
 INSERT INTO estimate_orders (
-    estimate_id, merchant_id, is_starting_point
+    estimate_id, merchant_id, is_starting_point,
+    stop_sequence, leg_distance_meters, leg_time_in_minutes, subtotal
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3,
+    $4, $5, $6, $7
 )
 `
 
 type CreateEstimateOrderParams struct {
-	EstimateID      uuid.UUID `json:"estimate_id"`
-	MerchantID      uuid.UUID `json:"merchant_id"`
-	IsStartingPoint bool      `json:"is_starting_point"`
+	EstimateID        uuid.UUID `json:"estimate_id"`
+	MerchantID        uuid.UUID `json:"merchant_id"`
+	IsStartingPoint   bool      `json:"is_starting_point"`
+	StopSequence      int       `json:"stop_sequence"`
+	LegDistanceMeters float64   `json:"leg_distance_meters"`
+	LegTimeInMinutes  int       `json:"leg_time_in_minutes"`
+	Subtotal          int64     `json:"subtotal"`
 }
 
 func (q *Queries) CreateEstimateOrder(ctx context.Context, arg CreateEstimateOrderParams) error {
-	_, err := q.db.Exec(ctx, createEstimateOrder, arg.EstimateID, arg.MerchantID, arg.IsStartingPoint)
+	_, err := q.db.Exec(ctx, createEstimateOrder,
+		arg.EstimateID,
+		arg.MerchantID,
+		arg.IsStartingPoint,
+		arg.StopSequence,
+		arg.LegDistanceMeters,
+		arg.LegTimeInMinutes,
+		arg.Subtotal,
+	)
 	return err
 }
 
-const createEstimateOrderItem = `-- name: CreateEstimateOrderItem :exec
+const createEstimateOrderItem = `-- name: CreateEstimateOrderItem :one
 INSERT INTO estimate_order_items (
-    estimate_order_id, item_id, quantity
+    estimate_order_id, item_id, quantity, unit_price, options_price
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 )
+RETURNING id
 `
 
 type CreateEstimateOrderItemParams struct {
 	EstimateOrderID uuid.UUID `json:"estimate_order_id"`
 	ItemID          uuid.UUID `json:"item_id"`
 	Quantity        int       `json:"quantity"`
+	UnitPrice       int64     `json:"unit_price"`
+	OptionsPrice    int64     `json:"options_price"`
 }
 
-func (q *Queries) CreateEstimateOrderItem(ctx context.Context, arg CreateEstimateOrderItemParams) error {
-	_, err := q.db.Exec(ctx, createEstimateOrderItem, arg.EstimateOrderID, arg.ItemID, arg.Quantity)
-	return err
-}
-
-const getAllMerchantsWithItemsSortedByH3Distance = `-- name: GetAllMerchantsWithItemsSortedByH3Distance :many
-SELECT
-    m.id AS merchant_id,
-    m.name AS merchant_name,
-    m.merchant_category,
-    m.image_url AS merchant_image_url,
-    m.lat,
-    m.lng,
-    m.created_at AS merchant_created_at,
-    i.id AS item_id,
-    i.name AS item_name,
-    i.product_category,
-    i.price,
-    i.image_url AS item_image_url,
-    i.created_at AS item_created_at,
-    h3_grid_distance(
-        h3_latlng_to_cell(Point($1, $2), 10),
-        m.h3_index
-    ) AS h3_distance
-FROM merchants m
-JOIN items i ON m.id = i.merchant_id
-WHERE ($3 = '' OR m.name ILIKE '%' || $3 || '%')
-ORDER BY h3_distance ASC, m.created_at DESC, i.created_at ASC
-`
-
-type GetAllMerchantsWithItemsSortedByH3DistanceParams struct {
-	Point   float64     `json:"point"`
-	Point_2 float64     `json:"point_2"`
-	Column3 interface{} `json:"column_3"`
-}
-
-type GetAllMerchantsWithItemsSortedByH3DistanceRow struct {
-	MerchantID        uuid.UUID   `json:"merchant_id"`
-	MerchantName      string      `json:"merchant_name"`
-	MerchantCategory  string      `json:"merchant_category"`
-	MerchantImageUrl  string      `json:"merchant_image_url"`
-	Lat               float64     `json:"lat"`
-	Lng               float64     `json:"lng"`
-	MerchantCreatedAt time.Time   `json:"merchant_created_at"`
-	ItemID            uuid.UUID   `json:"item_id"`
-	ItemName          string      `json:"item_name"`
-	ProductCategory   string      `json:"product_category"`
-	Price             int64       `json:"price"`
-	ItemImageUrl      string      `json:"item_image_url"`
-	ItemCreatedAt     time.Time   `json:"item_created_at"`
-	H3Distance        interface{} `json:"h3_distance"`
-}
-
-func (q *Queries) GetAllMerchantsWithItemsSortedByH3Distance(ctx context.Context, arg GetAllMerchantsWithItemsSortedByH3DistanceParams) ([]GetAllMerchantsWithItemsSortedByH3DistanceRow, error) {
-	rows, err := q.db.Query(ctx, getAllMerchantsWithItemsSortedByH3Distance, arg.Point, arg.Point_2, arg.Column3)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []GetAllMerchantsWithItemsSortedByH3DistanceRow{}
-	for rows.Next() {
-		var i GetAllMerchantsWithItemsSortedByH3DistanceRow
-		if err := rows.Scan(
-			&i.MerchantID,
-			&i.MerchantName,
-			&i.MerchantCategory,
-			&i.MerchantImageUrl,
-			&i.Lat,
-			&i.Lng,
-			&i.MerchantCreatedAt,
-			&i.ItemID,
-			&i.ItemName,
-			&i.ProductCategory,
-			&i.Price,
-			&i.ItemImageUrl,
-			&i.ItemCreatedAt,
-			&i.H3Distance,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) CreateEstimateOrderItem(ctx context.Context, arg CreateEstimateOrderItemParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, createEstimateOrderItem,
+		arg.EstimateOrderID,
+		arg.ItemID,
+		arg.Quantity,
+		arg.UnitPrice,
+		arg.OptionsPrice,
+	)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
 }
 
 const getEstimateById = `-- name: GetEstimateById :one
-SELECT id, user_id, user_lat, user_lng, total_price, estimated_delivery_time_in_minutes, created_at
+SELECT id, user_id, user_lat, user_lng, total_price, estimated_delivery_time_in_minutes, created_at,
+    final_leg_distance_meters, final_leg_time_in_minutes,
+    items_total, base_fee, distance_fee, extra_stop_fee, small_order_fee, service_fee,
+    expires_at, scheduled_delivery_at, discount_total
 FROM estimates
 WHERE id = $1::uuid
 `
@@ -186,10 +236,65 @@ func (q *Queries) GetEstimateById(ctx context.Context, dollar_1 uuid.UUID) (Esti
 		&i.TotalPrice,
 		&i.EstimatedDeliveryTimeInMinutes,
 		&i.CreatedAt,
+		&i.FinalLegDistanceMeters,
+		&i.FinalLegTimeInMinutes,
+		&i.ItemsTotal,
+		&i.BaseFee,
+		&i.DistanceFee,
+		&i.ExtraStopFee,
+		&i.SmallOrderFee,
+		&i.ServiceFee,
+		&i.ExpiresAt,
+		&i.ScheduledDeliveryAt,
+		&i.DiscountTotal,
 	)
 	return i, err
 }
 
+const getEstimateCurrentSubtotals = `-- name: GetEstimateCurrentSubtotals :many
+SELECT eo.merchant_id, i.product_category, SUM((i.price + COALESCE(opt.price_delta, 0)) * eoi.quantity)::bigint AS subtotal
+FROM estimate_orders eo
+JOIN estimate_order_items eoi ON eo.id = eoi.estimate_order_id
+JOIN items i ON i.id = eoi.item_id
+LEFT JOIN LATERAL (
+    SELECT SUM(COALESCE(io.price_delta, eoio.price_delta)) AS price_delta
+    FROM estimate_order_item_options eoio
+    LEFT JOIN item_options io ON io.id = eoio.option_id
+    WHERE eoio.estimate_order_item_id = eoi.id
+) AS opt ON TRUE
+WHERE eo.estimate_id = $1
+GROUP BY eo.merchant_id, i.product_category
+`
+
+type GetEstimateCurrentSubtotalsRow struct {
+	MerchantID      uuid.UUID `json:"merchant_id"`
+	ProductCategory string    `json:"product_category"`
+	Subtotal        int64     `json:"subtotal"`
+}
+
+// One row per merchant and product category, so promotions limited to either
+// can be recomputed. Options the merchant removed since the estimate keep
+// their quoted price.
+func (q *Queries) GetEstimateCurrentSubtotals(ctx context.Context, estimateID uuid.UUID) ([]GetEstimateCurrentSubtotalsRow, error) {
+	rows, err := q.db.Query(ctx, getEstimateCurrentSubtotals, estimateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetEstimateCurrentSubtotalsRow{}
+	for rows.Next() {
+		var i GetEstimateCurrentSubtotalsRow
+		if err := rows.Scan(&i.MerchantID, &i.ProductCategory, &i.Subtotal); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getEstimateOrderIds = `-- name: GetEstimateOrderIds :many
 SELECT id, merchant_id
 FROM estimate_orders
@@ -225,7 +330,7 @@ func (q *Queries) GetEstimateOrderIds(ctx context.Context, estimateID uuid.UUID)
 const getItemPrice = `-- name: GetItemPrice :one
 SELECT price
 FROM items
-WHERE id = $1::uuid AND merchant_id = $2::uuid
+WHERE id = $1::uuid AND merchant_id = $2::uuid AND deleted_at IS NULL
 `
 
 type GetItemPriceParams struct {
@@ -241,13 +346,14 @@ func (q *Queries) GetItemPrice(ctx context.Context, arg GetItemPriceParams) (int
 }
 
 const getItemPricesByIDsAndMerchants = `-- name: GetItemPricesByIDsAndMerchants :many
-SELECT i.id, i.merchant_id, i.price
+SELECT i.id, i.merchant_id, i.price, i.preparation_time_minutes, i.product_category, i.stock, i.is_available
 FROM items i
 JOIN (
     SELECT 
         UNNEST($1::uuid[]) AS item_id,
         UNNEST($2::uuid[]) AS merchant_id
 ) AS pairs ON i.id = pairs.item_id AND i.merchant_id = pairs.merchant_id
+WHERE i.deleted_at IS NULL
 `
 
 type GetItemPricesByIDsAndMerchantsParams struct {
@@ -256,9 +362,13 @@ type GetItemPricesByIDsAndMerchantsParams struct {
 }
 
 type GetItemPricesByIDsAndMerchantsRow struct {
-	ID         uuid.UUID `json:"id"`
-	MerchantID uuid.UUID `json:"merchant_id"`
-	Price      int64     `json:"price"`
+	ID                     uuid.UUID `json:"id"`
+	MerchantID             uuid.UUID `json:"merchant_id"`
+	Price                  int64     `json:"price"`
+	PreparationTimeMinutes *int      `json:"preparation_time_minutes"`
+	ProductCategory        string    `json:"product_category"`
+	Stock                  *int      `json:"stock"`
+	IsAvailable            bool      `json:"is_available"`
 }
 
 func (q *Queries) GetItemPricesByIDsAndMerchants(ctx context.Context, arg GetItemPricesByIDsAndMerchantsParams) ([]GetItemPricesByIDsAndMerchantsRow, error) {
@@ -270,7 +380,15 @@ func (q *Queries) GetItemPricesByIDsAndMerchants(ctx context.Context, arg GetIte
 	items := []GetItemPricesByIDsAndMerchantsRow{}
 	for rows.Next() {
 		var i GetItemPricesByIDsAndMerchantsRow
-		if err := rows.Scan(&i.ID, &i.MerchantID, &i.Price); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchantID,
+			&i.Price,
+			&i.PreparationTimeMinutes,
+			&i.ProductCategory,
+			&i.Stock,
+			&i.IsAvailable,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -284,7 +402,7 @@ func (q *Queries) GetItemPricesByIDsAndMerchants(ctx context.Context, arg GetIte
 const getMerchantLatLong = `-- name: GetMerchantLatLong :one
 SELECT id, lat, lng
 FROM merchants
-WHERE id = $1::uuid
+WHERE id = $1::uuid AND deleted_at IS NULL
 `
 
 type GetMerchantLatLongRow struct {
@@ -300,16 +418,50 @@ func (q *Queries) GetMerchantLatLong(ctx context.Context, merchantID uuid.UUID)
 	return i, err
 }
 
+const getMerchantServiceZones = `-- name: GetMerchantServiceZones :many
+SELECT merchant_id, h3_cell::text AS h3_cell
+FROM merchant_service_zones
+WHERE merchant_id = ANY($1::uuid[])
+`
+
+type GetMerchantServiceZonesRow struct {
+	MerchantID uuid.UUID `json:"merchant_id"`
+	H3Cell     string    `json:"h3_cell"`
+}
+
+func (q *Queries) GetMerchantServiceZones(ctx context.Context, merchantID []uuid.UUID) ([]GetMerchantServiceZonesRow, error) {
+	rows, err := q.db.Query(ctx, getMerchantServiceZones, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetMerchantServiceZonesRow{}
+	for rows.Next() {
+		var i GetMerchantServiceZonesRow
+		if err := rows.Scan(&i.MerchantID, &i.H3Cell); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMerchantsLatLong = `-- name: GetMerchantsLatLong :many
-SELECT id, lat, lng
+SELECT id, lat, lng, delivery_radius_meters, merchant_category, preparation_time_minutes
 FROM merchants
-WHERE id = ANY($1::uuid[])
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
 `
 
 type GetMerchantsLatLongRow struct {
-	ID  uuid.UUID `json:"id"`
-	Lat float64   `json:"lat"`
-	Lng float64   `json:"lng"`
+	ID                     uuid.UUID `json:"id"`
+	Lat                    float64   `json:"lat"`
+	Lng                    float64   `json:"lng"`
+	DeliveryRadiusMeters   int       `json:"delivery_radius_meters"`
+	MerchantCategory       string    `json:"merchant_category"`
+	PreparationTimeMinutes *int      `json:"preparation_time_minutes"`
 }
 
 func (q *Queries) GetMerchantsLatLong(ctx context.Context, merchantID []uuid.UUID) ([]GetMerchantsLatLongRow, error) {
@@ -321,7 +473,155 @@ func (q *Queries) GetMerchantsLatLong(ctx context.Context, merchantID []uuid.UUI
 	items := []GetMerchantsLatLongRow{}
 	for rows.Next() {
 		var i GetMerchantsLatLongRow
-		if err := rows.Scan(&i.ID, &i.Lat, &i.Lng); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Lat,
+			&i.Lng,
+			&i.DeliveryRadiusMeters,
+			&i.MerchantCategory,
+			&i.PreparationTimeMinutes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemsByMerchantIds = `-- name: ListItemsByMerchantIds :many
+SELECT id, merchant_id, name, product_category, price, image_url, created_at
+FROM items
+WHERE merchant_id = ANY($1::uuid[]) AND deleted_at IS NULL
+ORDER BY merchant_id, created_at ASC, id ASC
+`
+
+type ListItemsByMerchantIdsRow struct {
+	ID              uuid.UUID `json:"id"`
+	MerchantID      uuid.UUID `json:"merchant_id"`
+	Name            string    `json:"name"`
+	ProductCategory string    `json:"product_category"`
+	Price           int64     `json:"price"`
+	ImageUrl        string    `json:"image_url"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListItemsByMerchantIds(ctx context.Context, merchantIds []uuid.UUID) ([]ListItemsByMerchantIdsRow, error) {
+	rows, err := q.db.Query(ctx, listItemsByMerchantIds, merchantIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListItemsByMerchantIdsRow{}
+	for rows.Next() {
+		var i ListItemsByMerchantIdsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchantID,
+			&i.Name,
+			&i.ProductCategory,
+			&i.Price,
+			&i.ImageUrl,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNearbyMerchants = `-- name: ListNearbyMerchants :many
+SELECT
+    m.id,
+    m.name,
+    m.merchant_category,
+    m.image_url,
+    m.lat,
+    m.lng,
+    m.created_at,
+    haversine_meters($1::float8, $2::float8, m.lat, m.lng) AS distance_meters
+FROM merchants m
+WHERE m.deleted_at IS NULL
+    AND h3_cell_to_parent(m.h3_index, 8) IN (
+        SELECT h3_grid_disk(h3_latlng_to_cell(Point($1::float8, $2::float8), 8), $3::int)
+    )
+    AND haversine_meters($1::float8, $2::float8, m.lat, m.lng) <= LEAST(m.delivery_radius_meters, $4::float8)
+    AND ($5::text = '' OR m.name ILIKE '%' || $5::text || '%')
+    AND ($6::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR m.id = $6::uuid)
+    AND ($7::text = '' OR m.merchant_category = $7::text)
+    AND (
+        NOT EXISTS (SELECT 1 FROM merchant_service_zones z WHERE z.merchant_id = m.id)
+        OR EXISTS (
+            SELECT 1 FROM merchant_service_zones z
+            WHERE z.merchant_id = m.id
+                AND h3_cell_to_parent(h3_latlng_to_cell(Point($1::float8, $2::float8), 10), h3_get_resolution(z.h3_cell)) = z.h3_cell
+        )
+    )
+    AND EXISTS (SELECT 1 FROM items i WHERE i.merchant_id = m.id AND i.deleted_at IS NULL)
+ORDER BY distance_meters ASC, m.created_at DESC, m.id ASC
+LIMIT $8 OFFSET $9
+`
+
+type ListNearbyMerchantsParams struct {
+	Lat              float64   `json:"lat"`
+	Lng              float64   `json:"lng"`
+	Rings            int       `json:"rings"`
+	MaxDistance      float64   `json:"max_distance"`
+	Name             string    `json:"name"`
+	MerchantID       uuid.UUID `json:"merchant_id"`
+	MerchantCategory string    `json:"merchant_category"`
+	LimitCount       int32     `json:"limit_count"`
+	OffsetCount      int32     `json:"offset_count"`
+}
+
+type ListNearbyMerchantsRow struct {
+	ID               uuid.UUID `json:"id"`
+	Name             string    `json:"name"`
+	MerchantCategory string    `json:"merchant_category"`
+	ImageUrl         string    `json:"image_url"`
+	Lat              float64   `json:"lat"`
+	Lng              float64   `json:"lng"`
+	CreatedAt        time.Time `json:"created_at"`
+	DistanceMeters   float64   `json:"distance_meters"`
+}
+
+// Reads only merchants whose res-8 cell is within the given number of rings
+// of the user's cell (idx_merchants_h3_search_cell); m.id keeps the order stable.
+func (q *Queries) ListNearbyMerchants(ctx context.Context, arg ListNearbyMerchantsParams) ([]ListNearbyMerchantsRow, error) {
+	rows, err := q.db.Query(ctx, listNearbyMerchants,
+		arg.Lat,
+		arg.Lng,
+		arg.Rings,
+		arg.MaxDistance,
+		arg.Name,
+		arg.MerchantID,
+		arg.MerchantCategory,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListNearbyMerchantsRow{}
+	for rows.Next() {
+		var i ListNearbyMerchantsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.MerchantCategory,
+			&i.ImageUrl,
+			&i.Lat,
+			&i.Lng,
+			&i.CreatedAt,
+			&i.DistanceMeters,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -7,14 +7,36 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const adjustItemStock = `-- name: AdjustItemStock :exec
+UPDATE items i
+SET stock = i.stock + d.delta
+FROM (
+    SELECT UNNEST($1::uuid[]) AS item_id, UNNEST($2::int[]) AS delta
+) AS d
+WHERE i.id = d.item_id AND i.stock IS NOT NULL
+`
+
+type AdjustItemStockParams struct {
+	ItemIds []uuid.UUID `json:"item_ids"`
+	Deltas  []int       `json:"deltas"`
+}
+
+// Untracked stock (NULL) stays untracked.
+func (q *Queries) AdjustItemStock(ctx context.Context, arg AdjustItemStockParams) error {
+	_, err := q.db.Exec(ctx, adjustItemStock, arg.ItemIds, arg.Deltas)
+	return err
+}
+
 const countItemsByMerchant = `-- name: CountItemsByMerchant :one
 SELECT COUNT(*)
 FROM items
 WHERE merchant_id = $1
+    AND deleted_at IS NULL
   AND ($2::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR id = $2::uuid)
   AND ($3::text = '' OR name ILIKE '%' || $3::text || '%')
   AND ($4::text = '' OR product_category = $4::text)
@@ -39,29 +61,51 @@ func (q *Queries) CountItemsByMerchant(ctx context.Context, arg CountItemsByMerc
 	return count, err
 }
 
+type CopyItemsParams struct {
+	ID                     uuid.UUID `json:"id"`
+	MerchantID             uuid.UUID `json:"merchant_id"`
+	Name                   string    `json:"name"`
+	ProductCategory        string    `json:"product_category"`
+	Price                  int64     `json:"price"`
+	ImageUrl               string    `json:"image_url"`
+	PreparationTimeMinutes *int      `json:"preparation_time_minutes"`
+	Stock                  *int      `json:"stock"`
+	IsAvailable            bool      `json:"is_available"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
 const createItem = `-- name: CreateItem :one
 INSERT INTO items (
     merchant_id,
     name,
     product_category,
     price,
-    image_url
+    image_url,
+    preparation_time_minutes,
+    stock,
+    is_available
 ) VALUES (
     $1::uuid,
     $2::text,
     $3::text,
     $4::bigint,
-    $5::text
+    $5::text,
+    $6::int,
+    $7::int,
+    $8::bool
 )
 RETURNING id
 `
 
 type CreateItemParams struct {
-	Merchantid      uuid.UUID `json:"merchantid"`
-	Name            string    `json:"name"`
-	Productcategory string    `json:"productcategory"`
-	Price           int64     `json:"price"`
-	Imageurl        string    `json:"imageurl"`
+	Merchantid             uuid.UUID `json:"merchantid"`
+	Name                   string    `json:"name"`
+	Productcategory        string    `json:"productcategory"`
+	Price                  int64     `json:"price"`
+	Imageurl               string    `json:"imageurl"`
+	Preparationtimeminutes *int      `json:"preparationtimeminutes"`
+	Stock                  *int      `json:"stock"`
+	Isavailable            bool      `json:"isavailable"`
 }
 
 func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (uuid.UUID, error) {
@@ -71,16 +115,74 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (uuid.UU
 		arg.Productcategory,
 		arg.Price,
 		arg.Imageurl,
+		arg.Preparationtimeminutes,
+		arg.Stock,
+		arg.Isavailable,
 	)
 	var id uuid.UUID
 	err := row.Scan(&id)
 	return id, err
 }
 
+const deleteItem = `-- name: DeleteItem :execrows
+UPDATE items
+SET deleted_at = NOW()
+WHERE id = $1 AND merchant_id = $2 AND deleted_at IS NULL
+`
+
+type DeleteItemParams struct {
+	ItemID     uuid.UUID `json:"item_id"`
+	MerchantID uuid.UUID `json:"merchant_id"`
+}
+
+// Soft delete: order history keeps pointing at the item.
+func (q *Queries) DeleteItem(ctx context.Context, arg DeleteItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteItem, arg.ItemID, arg.MerchantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const hasActiveItemOrders = `-- name: HasActiveItemOrders :one
+SELECT EXISTS(
+    SELECT 1
+    FROM order_items oi
+    JOIN order_merchants om ON om.id = oi.order_merchant_id
+    JOIN orders o ON o.id = om.order_id
+    WHERE oi.item_id = $1
+      AND o.status NOT IN ('delivered', 'cancelled')
+)
+`
+
+func (q *Queries) HasActiveItemOrders(ctx context.Context, itemID uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, hasActiveItemOrders, itemID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const itemExists = `-- name: ItemExists :one
+SELECT EXISTS(SELECT 1 FROM items WHERE id = $1 AND merchant_id = $2 AND deleted_at IS NULL)
+`
+
+type ItemExistsParams struct {
+	ItemID     uuid.UUID `json:"item_id"`
+	MerchantID uuid.UUID `json:"merchant_id"`
+}
+
+func (q *Queries) ItemExists(ctx context.Context, arg ItemExistsParams) (bool, error) {
+	row := q.db.QueryRow(ctx, itemExists, arg.ItemID, arg.MerchantID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const listItemsByMerchant = `-- name: ListItemsByMerchant :many
-SELECT id, merchant_id, name, product_category, price, image_url, created_at
+SELECT id, merchant_id, name, product_category, price, image_url, created_at, preparation_time_minutes, stock, is_available
 FROM items
 WHERE merchant_id = $1
+    AND deleted_at IS NULL
     AND ($2::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR id = $2::uuid)
     AND ($3::text IS NULL OR $3::text = '' OR name ILIKE '%' || $3::text || '%')
     AND ($4::text IS NULL OR $4::text = '' OR product_category = $4)
@@ -101,7 +203,20 @@ type ListItemsByMerchantParams struct {
 	Limitpage       int32       `json:"limitpage"`
 }
 
-func (q *Queries) ListItemsByMerchant(ctx context.Context, arg ListItemsByMerchantParams) ([]Items, error) {
+type ListItemsByMerchantRow struct {
+	ID                     uuid.UUID `json:"id"`
+	MerchantID             uuid.UUID `json:"merchant_id"`
+	Name                   string    `json:"name"`
+	ProductCategory        string    `json:"product_category"`
+	Price                  int64     `json:"price"`
+	ImageUrl               string    `json:"image_url"`
+	CreatedAt              time.Time `json:"created_at"`
+	PreparationTimeMinutes *int      `json:"preparation_time_minutes"`
+	Stock                  *int      `json:"stock"`
+	IsAvailable            bool      `json:"is_available"`
+}
+
+func (q *Queries) ListItemsByMerchant(ctx context.Context, arg ListItemsByMerchantParams) ([]ListItemsByMerchantRow, error) {
 	rows, err := q.db.Query(ctx, listItemsByMerchant,
 		arg.MerchantID,
 		arg.ItemID,
@@ -115,9 +230,9 @@ func (q *Queries) ListItemsByMerchant(ctx context.Context, arg ListItemsByMercha
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Items{}
+	items := []ListItemsByMerchantRow{}
 	for rows.Next() {
-		var i Items
+		var i ListItemsByMerchantRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.MerchantID,
@@ -126,6 +241,9 @@ func (q *Queries) ListItemsByMerchant(ctx context.Context, arg ListItemsByMercha
 			&i.Price,
 			&i.ImageUrl,
 			&i.CreatedAt,
+			&i.PreparationTimeMinutes,
+			&i.Stock,
+			&i.IsAvailable,
 		); err != nil {
 			return nil, err
 		}
@@ -137,8 +255,79 @@ func (q *Queries) ListItemsByMerchant(ctx context.Context, arg ListItemsByMercha
 	return items, nil
 }
 
+const listOrderItemQuantities = `-- name: ListOrderItemQuantities :many
+SELECT oi.item_id, om.merchant_id, SUM(oi.quantity)::int AS quantity
+FROM order_items oi
+JOIN order_merchants om ON om.id = oi.order_merchant_id
+WHERE om.order_id = $1
+GROUP BY oi.item_id, om.merchant_id
+ORDER BY oi.item_id
+`
+
+type ListOrderItemQuantitiesRow struct {
+	ItemID     uuid.UUID `json:"item_id"`
+	MerchantID uuid.UUID `json:"merchant_id"`
+	Quantity   int       `json:"quantity"`
+}
+
+func (q *Queries) ListOrderItemQuantities(ctx context.Context, orderID uuid.UUID) ([]ListOrderItemQuantitiesRow, error) {
+	rows, err := q.db.Query(ctx, listOrderItemQuantities, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrderItemQuantitiesRow{}
+	for rows.Next() {
+		var i ListOrderItemQuantitiesRow
+		if err := rows.Scan(&i.ItemID, &i.MerchantID, &i.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockItemsForUpdate = `-- name: LockItemsForUpdate :many
+SELECT id, stock, is_available
+FROM items
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+ORDER BY id
+FOR UPDATE
+`
+
+type LockItemsForUpdateRow struct {
+	ID          uuid.UUID `json:"id"`
+	Stock       *int      `json:"stock"`
+	IsAvailable bool      `json:"is_available"`
+}
+
+// Rows are locked in id order so concurrent orders on the same items wait
+// for each other instead of deadlocking. Deleted items are left out.
+func (q *Queries) LockItemsForUpdate(ctx context.Context, itemIds []uuid.UUID) ([]LockItemsForUpdateRow, error) {
+	rows, err := q.db.Query(ctx, lockItemsForUpdate, itemIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LockItemsForUpdateRow{}
+	for rows.Next() {
+		var i LockItemsForUpdateRow
+		if err := rows.Scan(&i.ID, &i.Stock, &i.IsAvailable); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const merchantExists = `-- name: MerchantExists :one
-SELECT EXISTS(SELECT 1 FROM merchants WHERE id = $1)
+SELECT EXISTS(SELECT 1 FROM merchants WHERE id = $1 AND deleted_at IS NULL)
 `
 
 func (q *Queries) MerchantExists(ctx context.Context, id uuid.UUID) (bool, error) {
@@ -147,3 +336,72 @@ func (q *Queries) MerchantExists(ctx context.Context, id uuid.UUID) (bool, error
 	err := row.Scan(&exists)
 	return exists, err
 }
+
+const updateItem = `-- name: UpdateItem :execrows
+UPDATE items
+SET
+    name = COALESCE($1, name),
+    product_category = COALESCE($2, product_category),
+    price = COALESCE($3, price),
+    image_url = COALESCE($4, image_url),
+    preparation_time_minutes = COALESCE($5, preparation_time_minutes),
+    stock = COALESCE($6, stock),
+    is_available = COALESCE($7, is_available)
+WHERE id = $8 AND merchant_id = $9 AND deleted_at IS NULL
+`
+
+type UpdateItemParams struct {
+	Name                   *string   `json:"name"`
+	ProductCategory        *string   `json:"product_category"`
+	Price                  *int64    `json:"price"`
+	ImageUrl               *string   `json:"image_url"`
+	PreparationTimeMinutes *int      `json:"preparation_time_minutes"`
+	Stock                  *int      `json:"stock"`
+	IsAvailable            *bool     `json:"is_available"`
+	ItemID                 uuid.UUID `json:"item_id"`
+	MerchantID             uuid.UUID `json:"merchant_id"`
+}
+
+func (q *Queries) UpdateItem(ctx context.Context, arg UpdateItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateItem,
+		arg.Name,
+		arg.ProductCategory,
+		arg.Price,
+		arg.ImageUrl,
+		arg.PreparationTimeMinutes,
+		arg.Stock,
+		arg.IsAvailable,
+		arg.ItemID,
+		arg.MerchantID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateItemStock = `-- name: UpdateItemStock :execrows
+UPDATE items
+SET stock = $1::int, is_available = $2
+WHERE id = $3 AND merchant_id = $4 AND deleted_at IS NULL
+`
+
+type UpdateItemStockParams struct {
+	Stock       *int      `json:"stock"`
+	IsAvailable bool      `json:"is_available"`
+	ItemID      uuid.UUID `json:"item_id"`
+	MerchantID  uuid.UUID `json:"merchant_id"`
+}
+
+func (q *Queries) UpdateItemStock(ctx context.Context, arg UpdateItemStockParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateItemStock,
+		arg.Stock,
+		arg.IsAvailable,
+		arg.ItemID,
+		arg.MerchantID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
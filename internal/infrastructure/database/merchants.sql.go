@@ -12,6 +12,22 @@ import (
 	"github.com/google/uuid"
 )
 
+const addMerchantServiceZoneCells = `-- name: AddMerchantServiceZoneCells :exec
+INSERT INTO merchant_service_zones (merchant_id, h3_cell)
+SELECT $1, UNNEST($2::text[])::h3index
+ON CONFLICT DO NOTHING
+`
+
+type AddMerchantServiceZoneCellsParams struct {
+	MerchantID uuid.UUID `json:"merchant_id"`
+	H3Cells    []string  `json:"h3_cells"`
+}
+
+func (q *Queries) AddMerchantServiceZoneCells(ctx context.Context, arg AddMerchantServiceZoneCellsParams) error {
+	_, err := q.db.Exec(ctx, addMerchantServiceZoneCells, arg.MerchantID, arg.H3Cells)
+	return err
+}
+
 const countSearchMerchants = `-- name: CountSearchMerchants :one
 SELECT COUNT(id)
 FROM merchants
@@ -19,34 +35,44 @@ WHERE
     ($1::uuid IS NULL OR $1 = '00000000-0000-0000-0000-000000000000'::uuid OR id = $1)
     AND ($2::text IS NULL OR $2 = '' OR name ILIKE '%' || $2 || '%')
     AND ($3::text IS NULL OR $3 = '' OR merchant_category = $3)
+    AND ($4::uuid IS NULL OR $4 = '00000000-0000-0000-0000-000000000000'::uuid OR admin_id = $4)
+    AND deleted_at IS NULL
 `
 
 type CountSearchMerchantsParams struct {
 	Column1 uuid.UUID `json:"column_1"`
 	Column2 string    `json:"column_2"`
 	Column3 string    `json:"column_3"`
+	Column4 uuid.UUID `json:"column_4"`
 }
 
 func (q *Queries) CountSearchMerchants(ctx context.Context, arg CountSearchMerchantsParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countSearchMerchants, arg.Column1, arg.Column2, arg.Column3)
+	row := q.db.QueryRow(ctx, countSearchMerchants,
+		arg.Column1,
+		arg.Column2,
+		arg.Column3,
+		arg.Column4,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
 const createMerchant = `-- name: CreateMerchant :one
-INSERT INTO merchants (admin_id, name, merchant_category, image_url, lat, lng, created_at)
-VALUES ($1, $2, $3, $4, $5, $6, NOW())
+INSERT INTO merchants (admin_id, name, merchant_category, image_url, lat, lng, delivery_radius_meters, preparation_time_minutes, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
 RETURNING id, admin_id, name, merchant_category, image_url, lat, lng, created_at
 `
 
 type CreateMerchantParams struct {
-	AdminID          uuid.UUID `json:"admin_id"`
-	Name             string    `json:"name"`
-	MerchantCategory string    `json:"merchant_category"`
-	ImageUrl         string    `json:"image_url"`
-	Lat              float64   `json:"lat"`
-	Lng              float64   `json:"lng"`
+	AdminID                uuid.UUID `json:"admin_id"`
+	Name                   string    `json:"name"`
+	MerchantCategory       string    `json:"merchant_category"`
+	ImageUrl               string    `json:"image_url"`
+	Lat                    float64   `json:"lat"`
+	Lng                    float64   `json:"lng"`
+	DeliveryRadiusMeters   int       `json:"delivery_radius_meters"`
+	PreparationTimeMinutes *int      `json:"preparation_time_minutes"`
 }
 
 type CreateMerchantRow struct {
@@ -68,6 +94,8 @@ func (q *Queries) CreateMerchant(ctx context.Context, arg CreateMerchantParams)
 		arg.ImageUrl,
 		arg.Lat,
 		arg.Lng,
+		arg.DeliveryRadiusMeters,
+		arg.PreparationTimeMinutes,
 	)
 	var i CreateMerchantRow
 	err := row.Scan(
@@ -83,6 +111,178 @@ func (q *Queries) CreateMerchant(ctx context.Context, arg CreateMerchantParams)
 	return i, err
 }
 
+const deleteMerchant = `-- name: DeleteMerchant :execrows
+UPDATE merchants
+SET deleted_at = NOW()
+WHERE id = $1 AND admin_id = $2 AND deleted_at IS NULL
+`
+
+type DeleteMerchantParams struct {
+	MerchantID uuid.UUID `json:"merchant_id"`
+	AdminID    uuid.UUID `json:"admin_id"`
+}
+
+// Soft delete: order history keeps pointing at the merchant.
+func (q *Queries) DeleteMerchant(ctx context.Context, arg DeleteMerchantParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteMerchant, arg.MerchantID, arg.AdminID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteMerchantServiceZone = `-- name: DeleteMerchantServiceZone :exec
+DELETE FROM merchant_service_zones
+WHERE merchant_id = $1
+`
+
+func (q *Queries) DeleteMerchantServiceZone(ctx context.Context, merchantID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteMerchantServiceZone, merchantID)
+	return err
+}
+
+const getAdminMerchant = `-- name: GetAdminMerchant :one
+SELECT
+    id,
+    name,
+    merchant_category,
+    image_url,
+    lat,
+    lng,
+    h3_index::text AS h3_index,
+    delivery_radius_meters,
+    preparation_time_minutes,
+    created_at
+FROM merchants
+WHERE id = $1 AND admin_id = $2 AND deleted_at IS NULL
+`
+
+type GetAdminMerchantParams struct {
+	MerchantID uuid.UUID `json:"merchant_id"`
+	AdminID    uuid.UUID `json:"admin_id"`
+}
+
+type GetAdminMerchantRow struct {
+	ID                     uuid.UUID `json:"id"`
+	Name                   string    `json:"name"`
+	MerchantCategory       string    `json:"merchant_category"`
+	ImageUrl               string    `json:"image_url"`
+	Lat                    float64   `json:"lat"`
+	Lng                    float64   `json:"lng"`
+	H3Index                string    `json:"h3_index"`
+	DeliveryRadiusMeters   int       `json:"delivery_radius_meters"`
+	PreparationTimeMinutes *int      `json:"preparation_time_minutes"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetAdminMerchant(ctx context.Context, arg GetAdminMerchantParams) (GetAdminMerchantRow, error) {
+	row := q.db.QueryRow(ctx, getAdminMerchant, arg.MerchantID, arg.AdminID)
+	var i GetAdminMerchantRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.MerchantCategory,
+		&i.ImageUrl,
+		&i.Lat,
+		&i.Lng,
+		&i.H3Index,
+		&i.DeliveryRadiusMeters,
+		&i.PreparationTimeMinutes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getMerchantAccess = `-- name: GetMerchantAccess :one
+SELECT
+    m.admin_id,
+    (m.admin_id = $1 OR EXISTS(
+        SELECT 1 FROM users u WHERE u.id = $1 AND u.is_platform_admin
+    ))::boolean AS allowed
+FROM merchants m
+WHERE m.id = $2 AND m.deleted_at IS NULL
+`
+
+type GetMerchantAccessParams struct {
+	AdminID    uuid.UUID `json:"admin_id"`
+	MerchantID uuid.UUID `json:"merchant_id"`
+}
+
+type GetMerchantAccessRow struct {
+	AdminID uuid.UUID `json:"admin_id"`
+	Allowed bool      `json:"allowed"`
+}
+
+// An admin may manage its own merchants; platform admins may manage all.
+func (q *Queries) GetMerchantAccess(ctx context.Context, arg GetMerchantAccessParams) (GetMerchantAccessRow, error) {
+	row := q.db.QueryRow(ctx, getMerchantAccess, arg.AdminID, arg.MerchantID)
+	var i GetMerchantAccessRow
+	err := row.Scan(&i.AdminID, &i.Allowed)
+	return i, err
+}
+
+const getMerchantDeliveryRadius = `-- name: GetMerchantDeliveryRadius :one
+SELECT delivery_radius_meters
+FROM merchants
+WHERE id = $1 AND admin_id = $2 AND deleted_at IS NULL
+`
+
+type GetMerchantDeliveryRadiusParams struct {
+	MerchantID uuid.UUID `json:"merchant_id"`
+	AdminID    uuid.UUID `json:"admin_id"`
+}
+
+func (q *Queries) GetMerchantDeliveryRadius(ctx context.Context, arg GetMerchantDeliveryRadiusParams) (int, error) {
+	row := q.db.QueryRow(ctx, getMerchantDeliveryRadius, arg.MerchantID, arg.AdminID)
+	var delivery_radius_meters int
+	err := row.Scan(&delivery_radius_meters)
+	return delivery_radius_meters, err
+}
+
+const hasActiveMerchantOrders = `-- name: HasActiveMerchantOrders :one
+SELECT EXISTS(
+    SELECT 1
+    FROM order_merchants om
+    JOIN orders o ON o.id = om.order_id
+    WHERE om.merchant_id = $1
+      AND o.status NOT IN ('delivered', 'cancelled')
+)
+`
+
+func (q *Queries) HasActiveMerchantOrders(ctx context.Context, merchantID uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, hasActiveMerchantOrders, merchantID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listMerchantServiceZone = `-- name: ListMerchantServiceZone :many
+SELECT h3_cell::text AS h3_cell
+FROM merchant_service_zones
+WHERE merchant_id = $1
+ORDER BY h3_cell
+`
+
+func (q *Queries) ListMerchantServiceZone(ctx context.Context, merchantID uuid.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, listMerchantServiceZone, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var h3_cell string
+		if err := rows.Scan(&h3_cell); err != nil {
+			return nil, err
+		}
+		items = append(items, h3_cell)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchMerchantsAsc = `-- name: SearchMerchantsAsc :many
 SELECT 
     id,
@@ -97,6 +297,8 @@ WHERE
     ($1::uuid IS NULL OR $1 = '00000000-0000-0000-0000-000000000000'::uuid OR id = $1)
     AND ($2::text IS NULL OR $2 = '' OR name ILIKE '%' || $2 || '%')
     AND ($3::text IS NULL OR $3 = '' OR merchant_category = $3)
+    AND ($6::uuid IS NULL OR $6 = '00000000-0000-0000-0000-000000000000'::uuid OR admin_id = $6)
+    AND deleted_at IS NULL
 ORDER BY 
     created_at ASC
 LIMIT $4 OFFSET $5
@@ -108,6 +310,7 @@ type SearchMerchantsAscParams struct {
 	Column3 string    `json:"column_3"`
 	Limit   int32     `json:"limit"`
 	Offset  int32     `json:"offset"`
+	Column6 uuid.UUID `json:"column_6"`
 }
 
 type SearchMerchantsAscRow struct {
@@ -127,6 +330,7 @@ func (q *Queries) SearchMerchantsAsc(ctx context.Context, arg SearchMerchantsAsc
 		arg.Column3,
 		arg.Limit,
 		arg.Offset,
+		arg.Column6,
 	)
 	if err != nil {
 		return nil, err
@@ -168,6 +372,8 @@ WHERE
     ($1::uuid IS NULL OR $1 = '00000000-0000-0000-0000-000000000000'::uuid OR id = $1)
     AND ($2::text IS NULL OR $2 = '' OR name ILIKE '%' || $2 || '%')
     AND ($3::text IS NULL OR $3 = '' OR merchant_category = $3)
+    AND ($6::uuid IS NULL OR $6 = '00000000-0000-0000-0000-000000000000'::uuid OR admin_id = $6)
+    AND deleted_at IS NULL
 ORDER BY 
     created_at DESC
 LIMIT $4
@@ -180,6 +386,7 @@ type SearchMerchantsDescParams struct {
 	Column3 string    `json:"column_3"`
 	Limit   int32     `json:"limit"`
 	Offset  int32     `json:"offset"`
+	Column6 uuid.UUID `json:"column_6"`
 }
 
 type SearchMerchantsDescRow struct {
@@ -199,6 +406,7 @@ func (q *Queries) SearchMerchantsDesc(ctx context.Context, arg SearchMerchantsDe
 		arg.Column3,
 		arg.Limit,
 		arg.Offset,
+		arg.Column6,
 	)
 	if err != nil {
 		return nil, err
@@ -225,3 +433,101 @@ func (q *Queries) SearchMerchantsDesc(ctx context.Context, arg SearchMerchantsDe
 	}
 	return items, nil
 }
+
+const updateMerchant = `-- name: UpdateMerchant :one
+UPDATE merchants
+SET
+    name = COALESCE($1, name),
+    merchant_category = COALESCE($2, merchant_category),
+    image_url = COALESCE($3, image_url),
+    lat = COALESCE($4, lat),
+    lng = COALESCE($5, lng),
+    delivery_radius_meters = COALESCE($6, delivery_radius_meters),
+    preparation_time_minutes = COALESCE($7, preparation_time_minutes)
+WHERE id = $8 AND admin_id = $9 AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    merchant_category,
+    image_url,
+    lat,
+    lng,
+    h3_index::text AS h3_index,
+    delivery_radius_meters,
+    preparation_time_minutes,
+    created_at
+`
+
+type UpdateMerchantParams struct {
+	Name                   *string   `json:"name"`
+	MerchantCategory       *string   `json:"merchant_category"`
+	ImageUrl               *string   `json:"image_url"`
+	Lat                    *float64  `json:"lat"`
+	Lng                    *float64  `json:"lng"`
+	DeliveryRadiusMeters   *int      `json:"delivery_radius_meters"`
+	PreparationTimeMinutes *int      `json:"preparation_time_minutes"`
+	MerchantID             uuid.UUID `json:"merchant_id"`
+	AdminID                uuid.UUID `json:"admin_id"`
+}
+
+type UpdateMerchantRow struct {
+	ID                     uuid.UUID `json:"id"`
+	Name                   string    `json:"name"`
+	MerchantCategory       string    `json:"merchant_category"`
+	ImageUrl               string    `json:"image_url"`
+	Lat                    float64   `json:"lat"`
+	Lng                    float64   `json:"lng"`
+	H3Index                string    `json:"h3_index"`
+	DeliveryRadiusMeters   int       `json:"delivery_radius_meters"`
+	PreparationTimeMinutes *int      `json:"preparation_time_minutes"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+// h3_index is a generated column, so it follows lat/lng automatically.
+func (q *Queries) UpdateMerchant(ctx context.Context, arg UpdateMerchantParams) (UpdateMerchantRow, error) {
+	row := q.db.QueryRow(ctx, updateMerchant,
+		arg.Name,
+		arg.MerchantCategory,
+		arg.ImageUrl,
+		arg.Lat,
+		arg.Lng,
+		arg.DeliveryRadiusMeters,
+		arg.PreparationTimeMinutes,
+		arg.MerchantID,
+		arg.AdminID,
+	)
+	var i UpdateMerchantRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.MerchantCategory,
+		&i.ImageUrl,
+		&i.Lat,
+		&i.Lng,
+		&i.H3Index,
+		&i.DeliveryRadiusMeters,
+		&i.PreparationTimeMinutes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateMerchantDeliveryRadius = `-- name: UpdateMerchantDeliveryRadius :execrows
+UPDATE merchants
+SET delivery_radius_meters = $1
+WHERE id = $2 AND admin_id = $3 AND deleted_at IS NULL
+`
+
+type UpdateMerchantDeliveryRadiusParams struct {
+	DeliveryRadiusMeters int       `json:"delivery_radius_meters"`
+	MerchantID           uuid.UUID `json:"merchant_id"`
+	AdminID              uuid.UUID `json:"admin_id"`
+}
+
+func (q *Queries) UpdateMerchantDeliveryRadius(ctx context.Context, arg UpdateMerchantDeliveryRadiusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateMerchantDeliveryRadius, arg.DeliveryRadiusMeters, arg.MerchantID, arg.AdminID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
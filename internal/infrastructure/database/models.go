@@ -15,8 +15,9 @@ import (
 type UserRole string
 
 const (
-	UserRoleUser  UserRole = "user"
-	UserRoleAdmin UserRole = "admin"
+	UserRoleUser    UserRole = "user"
+	UserRoleAdmin   UserRole = "admin"
+	UserRoleCourier UserRole = "courier"
 )
 
 func (e *UserRole) Scan(src interface{}) error {
@@ -54,52 +55,348 @@ func (ns NullUserRole) Value() (driver.Value, error) {
 	return string(ns.UserRole), nil
 }
 
+type OrderStatus string
+
+const (
+	OrderStatusPlaced    OrderStatus = "placed"
+	OrderStatusAccepted  OrderStatus = "accepted"
+	OrderStatusPreparing OrderStatus = "preparing"
+	OrderStatusPickedUp  OrderStatus = "picked_up"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+func (e *OrderStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderStatus(s)
+	case string:
+		*e = OrderStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrderStatus struct {
+	OrderStatus OrderStatus `json:"order_status"`
+	Valid       bool        `json:"valid"` // Valid is true if OrderStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderStatus), nil
+}
+
+type PromotionType string
+
+const (
+	PromotionTypePercentage   PromotionType = "percentage"
+	PromotionTypeFlat         PromotionType = "flat"
+	PromotionTypeFreeDelivery PromotionType = "free_delivery"
+)
+
+func (e *PromotionType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PromotionType(s)
+	case string:
+		*e = PromotionType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PromotionType: %T", src)
+	}
+	return nil
+}
+
+type NullPromotionType struct {
+	PromotionType PromotionType `json:"promotion_type"`
+	Valid         bool          `json:"valid"` // Valid is true if PromotionType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPromotionType) Scan(value interface{}) error {
+	if value == nil {
+		ns.PromotionType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PromotionType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPromotionType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PromotionType), nil
+}
+
+type MerchantImportStatus string
+
+const (
+	MerchantImportStatusPending   MerchantImportStatus = "pending"
+	MerchantImportStatusRunning   MerchantImportStatus = "running"
+	MerchantImportStatusCompleted MerchantImportStatus = "completed"
+	MerchantImportStatusFailed    MerchantImportStatus = "failed"
+)
+
+func (e *MerchantImportStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MerchantImportStatus(s)
+	case string:
+		*e = MerchantImportStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MerchantImportStatus: %T", src)
+	}
+	return nil
+}
+
+type NullMerchantImportStatus struct {
+	MerchantImportStatus MerchantImportStatus `json:"merchant_import_status"`
+	Valid                bool                 `json:"valid"` // Valid is true if MerchantImportStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMerchantImportStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.MerchantImportStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MerchantImportStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMerchantImportStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MerchantImportStatus), nil
+}
+
+type MerchantImportRowStatus string
+
+const (
+	MerchantImportRowStatusPending   MerchantImportRowStatus = "pending"
+	MerchantImportRowStatusImported  MerchantImportRowStatus = "imported"
+	MerchantImportRowStatusDuplicate MerchantImportRowStatus = "duplicate"
+	MerchantImportRowStatusInvalid   MerchantImportRowStatus = "invalid"
+	MerchantImportRowStatusFailed    MerchantImportRowStatus = "failed"
+)
+
+func (e *MerchantImportRowStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MerchantImportRowStatus(s)
+	case string:
+		*e = MerchantImportRowStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MerchantImportRowStatus: %T", src)
+	}
+	return nil
+}
+
+type NullMerchantImportRowStatus struct {
+	MerchantImportRowStatus MerchantImportRowStatus `json:"merchant_import_row_status"`
+	Valid                   bool                    `json:"valid"` // Valid is true if MerchantImportRowStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMerchantImportRowStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.MerchantImportRowStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MerchantImportRowStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMerchantImportRowStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MerchantImportRowStatus), nil
+}
+
+type EstimateDiscounts struct {
+	ID          uuid.UUID `json:"id"`
+	EstimateID  uuid.UUID `json:"estimate_id"`
+	PromotionID uuid.UUID `json:"promotion_id"`
+	Amount      int64     `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type EstimateOrderItemOptions struct {
+	EstimateOrderItemID uuid.UUID `json:"estimate_order_item_id"`
+	OptionID            uuid.UUID `json:"option_id"`
+	GroupName           string    `json:"group_name"`
+	OptionName          string    `json:"option_name"`
+	PriceDelta          int64     `json:"price_delta"`
+}
+
 type EstimateOrderItems struct {
 	ID              uuid.UUID `json:"id"`
 	EstimateOrderID uuid.UUID `json:"estimate_order_id"`
 	ItemID          uuid.UUID `json:"item_id"`
 	Quantity        int       `json:"quantity"`
 	CreatedAt       time.Time `json:"created_at"`
+	OptionsPrice    int64     `json:"options_price"`
 }
 
 type EstimateOrders struct {
-	ID              uuid.UUID `json:"id"`
-	EstimateID      uuid.UUID `json:"estimate_id"`
-	MerchantID      uuid.UUID `json:"merchant_id"`
-	IsStartingPoint bool      `json:"is_starting_point"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID                uuid.UUID `json:"id"`
+	EstimateID        uuid.UUID `json:"estimate_id"`
+	MerchantID        uuid.UUID `json:"merchant_id"`
+	IsStartingPoint   bool      `json:"is_starting_point"`
+	CreatedAt         time.Time `json:"created_at"`
+	StopSequence      int       `json:"stop_sequence"`
+	LegDistanceMeters float64   `json:"leg_distance_meters"`
+	LegTimeInMinutes  int       `json:"leg_time_in_minutes"`
+	Subtotal          int64     `json:"subtotal"`
 }
 
 type Estimates struct {
-	ID                             uuid.UUID `json:"id"`
-	UserID                         uuid.UUID `json:"user_id"`
-	UserLat                        float64   `json:"user_lat"`
-	UserLng                        float64   `json:"user_lng"`
-	TotalPrice                     int64     `json:"total_price"`
-	EstimatedDeliveryTimeInMinutes int       `json:"estimated_delivery_time_in_minutes"`
-	CreatedAt                      time.Time `json:"created_at"`
+	ID                             uuid.UUID  `json:"id"`
+	UserID                         uuid.UUID  `json:"user_id"`
+	UserLat                        float64    `json:"user_lat"`
+	UserLng                        float64    `json:"user_lng"`
+	TotalPrice                     int64      `json:"total_price"`
+	EstimatedDeliveryTimeInMinutes int        `json:"estimated_delivery_time_in_minutes"`
+	CreatedAt                      time.Time  `json:"created_at"`
+	FinalLegDistanceMeters         float64    `json:"final_leg_distance_meters"`
+	FinalLegTimeInMinutes          int        `json:"final_leg_time_in_minutes"`
+	ItemsTotal                     int64      `json:"items_total"`
+	BaseFee                        int64      `json:"base_fee"`
+	DistanceFee                    int64      `json:"distance_fee"`
+	ExtraStopFee                   int64      `json:"extra_stop_fee"`
+	SmallOrderFee                  int64      `json:"small_order_fee"`
+	ServiceFee                     int64      `json:"service_fee"`
+	ExpiresAt                      time.Time  `json:"expires_at"`
+	ScheduledDeliveryAt            *time.Time `json:"scheduled_delivery_at"`
+	DiscountTotal                  int64      `json:"discount_total"`
+}
+
+type ItemOptionGroups struct {
+	ID            uuid.UUID `json:"id"`
+	ItemID        uuid.UUID `json:"item_id"`
+	Name          string    `json:"name"`
+	MinSelections int       `json:"min_selections"`
+	MaxSelections int       `json:"max_selections"`
+	SortOrder     int       `json:"sort_order"`
+}
+
+type ItemOptions struct {
+	ID         uuid.UUID `json:"id"`
+	GroupID    uuid.UUID `json:"group_id"`
+	Name       string    `json:"name"`
+	PriceDelta int64     `json:"price_delta"`
+	SortOrder  int       `json:"sort_order"`
 }
 
 type Items struct {
-	ID              uuid.UUID `json:"id"`
-	MerchantID      uuid.UUID `json:"merchant_id"`
-	Name            string    `json:"name"`
-	ProductCategory string    `json:"product_category"`
-	Price           int64     `json:"price"`
-	ImageUrl        string    `json:"image_url"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID                     uuid.UUID  `json:"id"`
+	MerchantID             uuid.UUID  `json:"merchant_id"`
+	Name                   string     `json:"name"`
+	ProductCategory        string     `json:"product_category"`
+	Price                  int64      `json:"price"`
+	ImageUrl               string     `json:"image_url"`
+	CreatedAt              time.Time  `json:"created_at"`
+	PreparationTimeMinutes *int       `json:"preparation_time_minutes"`
+	Stock                  *int       `json:"stock"`
+	IsAvailable            bool       `json:"is_available"`
+	DeletedAt              *time.Time `json:"deleted_at"`
 }
 
 type Merchants struct {
-	ID               uuid.UUID   `json:"id"`
-	AdminID          uuid.UUID   `json:"admin_id"`
-	Name             string      `json:"name"`
-	MerchantCategory string      `json:"merchant_category"`
-	ImageUrl         string      `json:"image_url"`
-	Lat              float64     `json:"lat"`
-	Lng              float64     `json:"lng"`
-	H3Index          interface{} `json:"h3_index"`
-	CreatedAt        time.Time   `json:"created_at"`
+	ID                     uuid.UUID   `json:"id"`
+	AdminID                uuid.UUID   `json:"admin_id"`
+	Name                   string      `json:"name"`
+	MerchantCategory       string      `json:"merchant_category"`
+	ImageUrl               string      `json:"image_url"`
+	Lat                    float64     `json:"lat"`
+	Lng                    float64     `json:"lng"`
+	H3Index                interface{} `json:"h3_index"`
+	CreatedAt              time.Time   `json:"created_at"`
+	DeliveryRadiusMeters   int         `json:"delivery_radius_meters"`
+	PreparationTimeMinutes *int        `json:"preparation_time_minutes"`
+	Timezone               string      `json:"timezone"`
+	DeletedAt              *time.Time  `json:"deleted_at"`
+}
+
+type MerchantOpeningExceptions struct {
+	MerchantID    uuid.UUID `json:"merchant_id"`
+	ExceptionDate time.Time `json:"exception_date"`
+	IsClosed      bool      `json:"is_closed"`
+	OpenMinute    int       `json:"open_minute"`
+	CloseMinute   int       `json:"close_minute"`
+	Note          string    `json:"note"`
+}
+
+type MerchantOpeningHours struct {
+	MerchantID  uuid.UUID `json:"merchant_id"`
+	DayOfWeek   int       `json:"day_of_week"`
+	OpenMinute  int       `json:"open_minute"`
+	CloseMinute int       `json:"close_minute"`
+}
+
+type MerchantServiceZones struct {
+	MerchantID uuid.UUID   `json:"merchant_id"`
+	H3Cell     interface{} `json:"h3_cell"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type MerchantImports struct {
+	ID                    uuid.UUID            `json:"id"`
+	AdminID               uuid.UUID            `json:"admin_id"`
+	FileName              string               `json:"file_name"`
+	Status                MerchantImportStatus `json:"status"`
+	DuplicateRadiusMeters int                  `json:"duplicate_radius_meters"`
+	ImportDuplicates      bool                 `json:"import_duplicates"`
+	Error                 string               `json:"error"`
+	CreatedAt             time.Time            `json:"created_at"`
+	StartedAt             *time.Time           `json:"started_at"`
+	FinishedAt            *time.Time           `json:"finished_at"`
+	UpdatedAt             time.Time            `json:"updated_at"`
+}
+
+type MerchantImportRows struct {
+	ImportID                uuid.UUID               `json:"import_id"`
+	RowNumber               int                     `json:"row_number"`
+	Name                    string                  `json:"name"`
+	MerchantCategory        string                  `json:"merchant_category"`
+	ImageUrl                string                  `json:"image_url"`
+	Lat                     float64                 `json:"lat"`
+	Lng                     float64                 `json:"lng"`
+	DeliveryRadiusMeters    int                     `json:"delivery_radius_meters"`
+	PreparationTimeMinutes  *int                    `json:"preparation_time_minutes"`
+	Status                  MerchantImportRowStatus `json:"status"`
+	Errors                  string                  `json:"errors"`
+	MerchantID              uuid.UUID               `json:"merchant_id"`
+	DuplicateOf             uuid.UUID               `json:"duplicate_of"`
+	DuplicateDistanceMeters *float64                `json:"duplicate_distance_meters"`
+}
+
+type OrderItemOptions struct {
+	OrderItemID uuid.UUID `json:"order_item_id"`
+	OptionID    uuid.UUID `json:"option_id"`
+	GroupName   string    `json:"group_name"`
+	OptionName  string    `json:"option_name"`
+	PriceDelta  int64     `json:"price_delta"`
 }
 
 type OrderItems struct {
@@ -108,30 +405,89 @@ type OrderItems struct {
 	ItemID          uuid.UUID `json:"item_id"`
 	Quantity        int       `json:"quantity"`
 	CreatedAt       time.Time `json:"created_at"`
+	OptionsPrice    int64     `json:"options_price"`
 }
 
 type OrderMerchants struct {
-	ID              uuid.UUID `json:"id"`
-	OrderID         uuid.UUID `json:"order_id"`
-	MerchantID      uuid.UUID `json:"merchant_id"`
-	IsStartingPoint bool      `json:"is_starting_point"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID                uuid.UUID `json:"id"`
+	OrderID           uuid.UUID `json:"order_id"`
+	MerchantID        uuid.UUID `json:"merchant_id"`
+	IsStartingPoint   bool      `json:"is_starting_point"`
+	CreatedAt         time.Time `json:"created_at"`
+	StopSequence      int       `json:"stop_sequence"`
+	Subtotal          int64     `json:"subtotal"`
+	LegDistanceMeters float64   `json:"leg_distance_meters"`
+	LegTimeInMinutes  int       `json:"leg_time_in_minutes"`
+}
+
+type OrderStatusHistory struct {
+	ID         uuid.UUID       `json:"id"`
+	OrderID    uuid.UUID       `json:"order_id"`
+	FromStatus NullOrderStatus `json:"from_status"`
+	ToStatus   OrderStatus     `json:"to_status"`
+	ActorID    uuid.UUID       `json:"actor_id"`
+	ActorRole  string          `json:"actor_role"`
+	CreatedAt  time.Time       `json:"created_at"`
 }
 
 type Orders struct {
-	ID                             uuid.UUID `json:"id"`
-	UserID                         uuid.UUID `json:"user_id"`
-	EstimateID                     uuid.UUID `json:"estimate_id"`
-	TotalPrice                     int64     `json:"total_price"`
-	EstimatedDeliveryTimeInMinutes int       `json:"estimated_delivery_time_in_minutes"`
-	CreatedAt                      time.Time `json:"created_at"`
+	ID                             uuid.UUID   `json:"id"`
+	UserID                         uuid.UUID   `json:"user_id"`
+	EstimateID                     uuid.UUID   `json:"estimate_id"`
+	TotalPrice                     int64       `json:"total_price"`
+	EstimatedDeliveryTimeInMinutes int         `json:"estimated_delivery_time_in_minutes"`
+	CreatedAt                      time.Time   `json:"created_at"`
+	ItemsTotal                     int64       `json:"items_total"`
+	BaseFee                        int64       `json:"base_fee"`
+	DistanceFee                    int64       `json:"distance_fee"`
+	ExtraStopFee                   int64       `json:"extra_stop_fee"`
+	SmallOrderFee                  int64       `json:"small_order_fee"`
+	ServiceFee                     int64       `json:"service_fee"`
+	Status                         OrderStatus `json:"status"`
+	UpdatedAt                      time.Time   `json:"updated_at"`
+	CourierID                      uuid.UUID   `json:"courier_id"`
+	ScheduledDeliveryAt            *time.Time  `json:"scheduled_delivery_at"`
+	ReleaseAt                      *time.Time  `json:"release_at"`
+	ReleasedAt                     *time.Time  `json:"released_at"`
+	DiscountTotal                  int64       `json:"discount_total"`
+	FinalLegDistanceMeters         float64     `json:"final_leg_distance_meters"`
+	FinalLegTimeInMinutes          int         `json:"final_leg_time_in_minutes"`
+}
+
+type Promotions struct {
+	ID              uuid.UUID     `json:"id"`
+	Code            string        `json:"code"`
+	Name            string        `json:"name"`
+	PromotionType   PromotionType `json:"promotion_type"`
+	Value           int64         `json:"value"`
+	MaxDiscount     int64         `json:"max_discount"`
+	MinBasket       int64         `json:"min_basket"`
+	MerchantID      uuid.UUID     `json:"merchant_id"`
+	ProductCategory string        `json:"product_category"`
+	UsageLimit      *int          `json:"usage_limit"`
+	PerUserLimit    *int          `json:"per_user_limit"`
+	UsageCount      int           `json:"usage_count"`
+	IsActive        bool          `json:"is_active"`
+	StartsAt        time.Time     `json:"starts_at"`
+	EndsAt          *time.Time    `json:"ends_at"`
+	CreatedAt       time.Time     `json:"created_at"`
+}
+
+type PromotionRedemptions struct {
+	ID          uuid.UUID `json:"id"`
+	PromotionID uuid.UUID `json:"promotion_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	OrderID     uuid.UUID `json:"order_id"`
+	Amount      int64     `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Users struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	PasswordHash string    `json:"password_hash"`
-	Email        string    `json:"email"`
-	Role         UserRole  `json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID              uuid.UUID `json:"id"`
+	Username        string    `json:"username"`
+	PasswordHash    string    `json:"password_hash"`
+	Email           string    `json:"email"`
+	Role            UserRole  `json:"role"`
+	CreatedAt       time.Time `json:"created_at"`
+	IsPlatformAdmin bool      `json:"is_platform_admin"`
 }
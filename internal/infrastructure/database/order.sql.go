@@ -12,64 +12,181 @@ import (
 	"github.com/google/uuid"
 )
 
+const copyEstimateOrderItemOptions = `-- name: CopyEstimateOrderItemOptions :exec
+INSERT INTO order_item_options (order_item_id, option_id, group_name, option_name, price_delta)
+SELECT
+    $1,
+    eoio.option_id,
+    eoio.group_name,
+    eoio.option_name,
+    CASE WHEN $2::bool THEN COALESCE(io.price_delta, eoio.price_delta) ELSE eoio.price_delta END
+FROM estimate_order_item_options eoio
+LEFT JOIN item_options io ON io.id = eoio.option_id
+WHERE eoio.estimate_order_item_id = $3
+`
+
+type CopyEstimateOrderItemOptionsParams struct {
+	OrderItemID         uuid.UUID `json:"order_item_id"`
+	CurrentPrices       bool      `json:"current_prices"`
+	EstimateOrderItemID uuid.UUID `json:"estimate_order_item_id"`
+}
+
+// With current_prices the options are copied at their current price, as in
+// GetEstimateOrderDetails.
+func (q *Queries) CopyEstimateOrderItemOptions(ctx context.Context, arg CopyEstimateOrderItemOptionsParams) error {
+	_, err := q.db.Exec(ctx, copyEstimateOrderItemOptions, arg.OrderItemID, arg.CurrentPrices, arg.EstimateOrderItemID)
+	return err
+}
+
+const countUserOrders = `-- name: CountUserOrders :one
+SELECT COUNT(*)
+FROM orders o
+WHERE o.user_id = $1
+    AND o.created_at >= $2::timestamptz
+    AND ($3::timestamptz = '0001-01-01 00:00:00+00'::timestamptz OR o.created_at <= $3::timestamptz)
+    AND EXISTS (
+        SELECT 1
+        FROM order_merchants om
+        JOIN merchants m ON m.id = om.merchant_id
+        WHERE om.order_id = o.id
+            AND ($4::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR m.id = $4::uuid)
+            AND ($5::text = '' OR m.merchant_category = $5::text)
+            AND (
+                $6::text = ''
+                OR m.name ILIKE '%' || $6::text || '%'
+                OR EXISTS (
+                    SELECT 1
+                    FROM order_items oi
+                    JOIN items i ON i.id = oi.item_id
+                    WHERE oi.order_merchant_id = om.id AND i.name ILIKE '%' || $6::text || '%'
+                )
+            )
+    )
+`
+
+type CountUserOrdersParams struct {
+	UserID           uuid.UUID `json:"user_id"`
+	CreatedFrom      time.Time `json:"created_from"`
+	CreatedTo        time.Time `json:"created_to"`
+	MerchantID       uuid.UUID `json:"merchant_id"`
+	MerchantCategory string    `json:"merchant_category"`
+	Name             string    `json:"name"`
+}
+
+func (q *Queries) CountUserOrders(ctx context.Context, arg CountUserOrdersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserOrders,
+		arg.UserID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MerchantID,
+		arg.MerchantCategory,
+		arg.Name,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createOrderFromEstimate = `-- name: CreateOrderFromEstimate :one
 INSERT INTO orders (
-    user_id, estimate_id, total_price, estimated_delivery_time_in_minutes
+    user_id, estimate_id, total_price, estimated_delivery_time_in_minutes,
+    items_total, base_fee, distance_fee, extra_stop_fee, small_order_fee, service_fee,
+    discount_total, scheduled_delivery_at, release_at, released_at,
+    final_leg_distance_meters, final_leg_time_in_minutes
 ) 
 SELECT 
-    user_id, id, total_price, estimated_delivery_time_in_minutes
+    user_id, id, total_price, estimated_delivery_time_in_minutes,
+    items_total, base_fee, distance_fee, extra_stop_fee, small_order_fee, service_fee,
+    discount_total, scheduled_delivery_at,
+    scheduled_delivery_at - make_interval(mins => estimated_delivery_time_in_minutes),
+    CASE WHEN scheduled_delivery_at IS NULL THEN NOW() END,
+    final_leg_distance_meters, final_leg_time_in_minutes
 FROM estimates
 WHERE id = $1::uuid
-RETURNING id, total_price, estimated_delivery_time_in_minutes
+RETURNING id, total_price, estimated_delivery_time_in_minutes, scheduled_delivery_at, release_at
 `
 
 type CreateOrderFromEstimateRow struct {
-	ID                             uuid.UUID `json:"id"`
-	TotalPrice                     int64     `json:"total_price"`
-	EstimatedDeliveryTimeInMinutes int       `json:"estimated_delivery_time_in_minutes"`
+	ID                             uuid.UUID  `json:"id"`
+	TotalPrice                     int64      `json:"total_price"`
+	EstimatedDeliveryTimeInMinutes int        `json:"estimated_delivery_time_in_minutes"`
+	ScheduledDeliveryAt            *time.Time `json:"scheduled_delivery_at"`
+	ReleaseAt                      *time.Time `json:"release_at"`
 }
 
+// A scheduled order is released to its merchants once the estimate's
+// delivery time (preparation plus route) before the scheduled time is reached.
 func (q *Queries) CreateOrderFromEstimate(ctx context.Context, dollar_1 uuid.UUID) (CreateOrderFromEstimateRow, error) {
 	row := q.db.QueryRow(ctx, createOrderFromEstimate, dollar_1)
 	var i CreateOrderFromEstimateRow
-	err := row.Scan(&i.ID, &i.TotalPrice, &i.EstimatedDeliveryTimeInMinutes)
+	err := row.Scan(
+		&i.ID,
+		&i.TotalPrice,
+		&i.EstimatedDeliveryTimeInMinutes,
+		&i.ScheduledDeliveryAt,
+		&i.ReleaseAt,
+	)
 	return i, err
 }
 
-const createOrderItem = `-- name: CreateOrderItem :exec
+const createOrderItem = `-- name: CreateOrderItem :one
 INSERT INTO order_items (
-    order_merchant_id, item_id, quantity
+    order_merchant_id, item_id, quantity, unit_price, options_price
 )
-VALUES ($1, $2, $3)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id
 `
 
 type CreateOrderItemParams struct {
 	OrderMerchantID uuid.UUID `json:"order_merchant_id"`
 	ItemID          uuid.UUID `json:"item_id"`
 	Quantity        int       `json:"quantity"`
+	UnitPrice       int64     `json:"unit_price"`
+	OptionsPrice    int64     `json:"options_price"`
 }
 
-func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) error {
-	_, err := q.db.Exec(ctx, createOrderItem, arg.OrderMerchantID, arg.ItemID, arg.Quantity)
-	return err
+func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, createOrderItem,
+		arg.OrderMerchantID,
+		arg.ItemID,
+		arg.Quantity,
+		arg.UnitPrice,
+		arg.OptionsPrice,
+	)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
 }
 
 const createOrderMerchant = `-- name: CreateOrderMerchant :one
 INSERT INTO order_merchants (
-    order_id, merchant_id, is_starting_point
+    order_id, merchant_id, is_starting_point, stop_sequence, subtotal,
+    leg_distance_meters, leg_time_in_minutes
 )
-VALUES ($1, $2, $3)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING id
 `
 
 type CreateOrderMerchantParams struct {
-	OrderID         uuid.UUID `json:"order_id"`
-	MerchantID      uuid.UUID `json:"merchant_id"`
-	IsStartingPoint bool      `json:"is_starting_point"`
+	OrderID           uuid.UUID `json:"order_id"`
+	MerchantID        uuid.UUID `json:"merchant_id"`
+	IsStartingPoint   bool      `json:"is_starting_point"`
+	StopSequence      int       `json:"stop_sequence"`
+	Subtotal          int64     `json:"subtotal"`
+	LegDistanceMeters float64   `json:"leg_distance_meters"`
+	LegTimeInMinutes  int       `json:"leg_time_in_minutes"`
 }
 
 func (q *Queries) CreateOrderMerchant(ctx context.Context, arg CreateOrderMerchantParams) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, createOrderMerchant, arg.OrderID, arg.MerchantID, arg.IsStartingPoint)
+	row := q.db.QueryRow(ctx, createOrderMerchant,
+		arg.OrderID,
+		arg.MerchantID,
+		arg.IsStartingPoint,
+		arg.StopSequence,
+		arg.Subtotal,
+		arg.LegDistanceMeters,
+		arg.LegTimeInMinutes,
+	)
 	var id uuid.UUID
 	err := row.Scan(&id)
 	return id, err
@@ -79,21 +196,49 @@ const getEstimateOrderDetails = `-- name: GetEstimateOrderDetails :many
 SELECT 
     eo.merchant_id,
     eo.is_starting_point,
+    eo.stop_sequence,
+    eo.subtotal,
+    eo.leg_distance_meters,
+    eo.leg_time_in_minutes,
+    eoi.id AS estimate_order_item_id,
     eoi.item_id,
-    eoi.quantity
+    eoi.quantity,
+    eoi.unit_price,
+    eoi.options_price,
+    i.price AS current_unit_price,
+    COALESCE(opt.price_delta, 0)::bigint AS current_options_price
 FROM estimate_orders eo
 JOIN estimate_order_items eoi ON eo.id = eoi.estimate_order_id
+JOIN items i ON i.id = eoi.item_id
+LEFT JOIN LATERAL (
+    SELECT SUM(COALESCE(io.price_delta, eoio.price_delta)) AS price_delta
+    FROM estimate_order_item_options eoio
+    LEFT JOIN item_options io ON io.id = eoio.option_id
+    WHERE eoio.estimate_order_item_id = eoi.id
+) AS opt ON TRUE
 WHERE eo.estimate_id = $1::uuid
-ORDER BY eo.id
+ORDER BY eo.stop_sequence, eo.id
 `
 
 type GetEstimateOrderDetailsRow struct {
-	MerchantID      uuid.UUID `json:"merchant_id"`
-	IsStartingPoint bool      `json:"is_starting_point"`
-	ItemID          uuid.UUID `json:"item_id"`
-	Quantity        int       `json:"quantity"`
+	MerchantID          uuid.UUID `json:"merchant_id"`
+	IsStartingPoint     bool      `json:"is_starting_point"`
+	StopSequence        int       `json:"stop_sequence"`
+	Subtotal            int64     `json:"subtotal"`
+	LegDistanceMeters   float64   `json:"leg_distance_meters"`
+	LegTimeInMinutes    int       `json:"leg_time_in_minutes"`
+	EstimateOrderItemID uuid.UUID `json:"estimate_order_item_id"`
+	ItemID              uuid.UUID `json:"item_id"`
+	Quantity            int       `json:"quantity"`
+	UnitPrice           int64     `json:"unit_price"`
+	OptionsPrice        int64     `json:"options_price"`
+	CurrentUnitPrice    int64     `json:"current_unit_price"`
+	CurrentOptionsPrice int64     `json:"current_options_price"`
 }
 
+// current_unit_price and current_options_price are what the line costs now,
+// used when the order is repriced. Options the merchant removed since the
+// estimate keep their quoted price.
 func (q *Queries) GetEstimateOrderDetails(ctx context.Context, dollar_1 uuid.UUID) ([]GetEstimateOrderDetailsRow, error) {
 	rows, err := q.db.Query(ctx, getEstimateOrderDetails, dollar_1)
 	if err != nil {
@@ -106,8 +251,17 @@ func (q *Queries) GetEstimateOrderDetails(ctx context.Context, dollar_1 uuid.UUI
 		if err := rows.Scan(
 			&i.MerchantID,
 			&i.IsStartingPoint,
+			&i.StopSequence,
+			&i.Subtotal,
+			&i.LegDistanceMeters,
+			&i.LegTimeInMinutes,
+			&i.EstimateOrderItemID,
 			&i.ItemID,
 			&i.Quantity,
+			&i.UnitPrice,
+			&i.OptionsPrice,
+			&i.CurrentUnitPrice,
+			&i.CurrentOptionsPrice,
 		); err != nil {
 			return nil, err
 		}
@@ -145,3 +299,268 @@ func (q *Queries) GetOrderById(ctx context.Context, dollar_1 uuid.UUID) (GetOrde
 	)
 	return i, err
 }
+
+const getOrderDetailsByIds = `-- name: GetOrderDetailsByIds :many
+SELECT
+    om.order_id,
+    om.id AS order_merchant_id,
+    m.id AS merchant_id,
+    m.name AS merchant_name,
+    m.merchant_category,
+    m.image_url AS merchant_image_url,
+    m.lat,
+    m.lng,
+    m.created_at AS merchant_created_at,
+    i.id AS item_id,
+    i.name AS item_name,
+    i.product_category,
+    oi.unit_price,
+    i.image_url AS item_image_url,
+    i.created_at AS item_created_at,
+    oi.id AS order_item_id,
+    oi.quantity,
+    oi.options_price
+FROM order_merchants om
+JOIN merchants m ON m.id = om.merchant_id
+JOIN order_items oi ON oi.order_merchant_id = om.id
+JOIN items i ON i.id = oi.item_id
+WHERE om.order_id = ANY($1::uuid[])
+ORDER BY om.order_id, om.stop_sequence, om.id, oi.id
+`
+
+type GetOrderDetailsByIdsRow struct {
+	OrderID           uuid.UUID `json:"order_id"`
+	OrderMerchantID   uuid.UUID `json:"order_merchant_id"`
+	MerchantID        uuid.UUID `json:"merchant_id"`
+	MerchantName      string    `json:"merchant_name"`
+	MerchantCategory  string    `json:"merchant_category"`
+	MerchantImageUrl  string    `json:"merchant_image_url"`
+	Lat               float64   `json:"lat"`
+	Lng               float64   `json:"lng"`
+	MerchantCreatedAt time.Time `json:"merchant_created_at"`
+	ItemID            uuid.UUID `json:"item_id"`
+	ItemName          string    `json:"item_name"`
+	ProductCategory   string    `json:"product_category"`
+	UnitPrice         int64     `json:"unit_price"`
+	ItemImageUrl      string    `json:"item_image_url"`
+	ItemCreatedAt     time.Time `json:"item_created_at"`
+	OrderItemID       uuid.UUID `json:"order_item_id"`
+	Quantity          int       `json:"quantity"`
+	OptionsPrice      int64     `json:"options_price"`
+}
+
+func (q *Queries) GetOrderDetailsByIds(ctx context.Context, orderIds []uuid.UUID) ([]GetOrderDetailsByIdsRow, error) {
+	rows, err := q.db.Query(ctx, getOrderDetailsByIds, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetOrderDetailsByIdsRow{}
+	for rows.Next() {
+		var i GetOrderDetailsByIdsRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.OrderMerchantID,
+			&i.MerchantID,
+			&i.MerchantName,
+			&i.MerchantCategory,
+			&i.MerchantImageUrl,
+			&i.Lat,
+			&i.Lng,
+			&i.MerchantCreatedAt,
+			&i.ItemID,
+			&i.ItemName,
+			&i.ProductCategory,
+			&i.UnitPrice,
+			&i.ItemImageUrl,
+			&i.ItemCreatedAt,
+			&i.OrderItemID,
+			&i.Quantity,
+			&i.OptionsPrice,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserOrder = `-- name: GetUserOrder :one
+SELECT id, status, total_price, estimated_delivery_time_in_minutes, created_at
+FROM orders
+WHERE id = $1 AND user_id = $2
+`
+
+type GetUserOrderParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type GetUserOrderRow struct {
+	ID                             uuid.UUID   `json:"id"`
+	Status                         OrderStatus `json:"status"`
+	TotalPrice                     int64       `json:"total_price"`
+	EstimatedDeliveryTimeInMinutes int         `json:"estimated_delivery_time_in_minutes"`
+	CreatedAt                      time.Time   `json:"created_at"`
+}
+
+func (q *Queries) GetUserOrder(ctx context.Context, arg GetUserOrderParams) (GetUserOrderRow, error) {
+	row := q.db.QueryRow(ctx, getUserOrder, arg.ID, arg.UserID)
+	var i GetUserOrderRow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.TotalPrice,
+		&i.EstimatedDeliveryTimeInMinutes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listOrderItemOptions = `-- name: ListOrderItemOptions :many
+SELECT oio.order_item_id, oio.option_id, oio.group_name, oio.option_name, oio.price_delta
+FROM order_item_options oio
+JOIN order_items oi ON oi.id = oio.order_item_id
+JOIN order_merchants om ON om.id = oi.order_merchant_id
+WHERE om.order_id = ANY($1::uuid[])
+ORDER BY oio.order_item_id, oio.group_name, oio.option_name
+`
+
+func (q *Queries) ListOrderItemOptions(ctx context.Context, orderIds []uuid.UUID) ([]OrderItemOptions, error) {
+	rows, err := q.db.Query(ctx, listOrderItemOptions, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OrderItemOptions{}
+	for rows.Next() {
+		var i OrderItemOptions
+		if err := rows.Scan(
+			&i.OrderItemID,
+			&i.OptionID,
+			&i.GroupName,
+			&i.OptionName,
+			&i.PriceDelta,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserOrders = `-- name: ListUserOrders :many
+SELECT o.id, o.status, o.total_price, o.estimated_delivery_time_in_minutes, o.created_at
+FROM orders o
+WHERE o.user_id = $1
+    AND o.created_at >= $2::timestamptz
+    AND ($3::timestamptz = '0001-01-01 00:00:00+00'::timestamptz OR o.created_at <= $3::timestamptz)
+    AND EXISTS (
+        SELECT 1
+        FROM order_merchants om
+        JOIN merchants m ON m.id = om.merchant_id
+        WHERE om.order_id = o.id
+            AND ($4::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR m.id = $4::uuid)
+            AND ($5::text = '' OR m.merchant_category = $5::text)
+            AND (
+                $6::text = ''
+                OR m.name ILIKE '%' || $6::text || '%'
+                OR EXISTS (
+                    SELECT 1
+                    FROM order_items oi
+                    JOIN items i ON i.id = oi.item_id
+                    WHERE oi.order_merchant_id = om.id AND i.name ILIKE '%' || $6::text || '%'
+                )
+            )
+    )
+ORDER BY o.created_at DESC, o.id DESC
+LIMIT $7 OFFSET $8
+`
+
+type ListUserOrdersParams struct {
+	UserID           uuid.UUID `json:"user_id"`
+	CreatedFrom      time.Time `json:"created_from"`
+	CreatedTo        time.Time `json:"created_to"`
+	MerchantID       uuid.UUID `json:"merchant_id"`
+	MerchantCategory string    `json:"merchant_category"`
+	Name             string    `json:"name"`
+	LimitCount       int32     `json:"limit_count"`
+	OffsetCount      int32     `json:"offset_count"`
+}
+
+type ListUserOrdersRow struct {
+	ID                             uuid.UUID   `json:"id"`
+	Status                         OrderStatus `json:"status"`
+	TotalPrice                     int64       `json:"total_price"`
+	EstimatedDeliveryTimeInMinutes int         `json:"estimated_delivery_time_in_minutes"`
+	CreatedAt                      time.Time   `json:"created_at"`
+}
+
+func (q *Queries) ListUserOrders(ctx context.Context, arg ListUserOrdersParams) ([]ListUserOrdersRow, error) {
+	rows, err := q.db.Query(ctx, listUserOrders,
+		arg.UserID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MerchantID,
+		arg.MerchantCategory,
+		arg.Name,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserOrdersRow{}
+	for rows.Next() {
+		var i ListUserOrdersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.TotalPrice,
+			&i.EstimatedDeliveryTimeInMinutes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateOrderPrice = `-- name: UpdateOrderPrice :exec
+UPDATE orders
+SET items_total = $1,
+    small_order_fee = $2,
+    discount_total = $3,
+    total_price = $4
+WHERE id = $5
+`
+
+type UpdateOrderPriceParams struct {
+	ItemsTotal    int64     `json:"items_total"`
+	SmallOrderFee int64     `json:"small_order_fee"`
+	DiscountTotal int64     `json:"discount_total"`
+	TotalPrice    int64     `json:"total_price"`
+	ID            uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateOrderPrice(ctx context.Context, arg UpdateOrderPriceParams) error {
+	_, err := q.db.Exec(ctx, updateOrderPrice,
+		arg.ItemsTotal,
+		arg.SmallOrderFee,
+		arg.DiscountTotal,
+		arg.TotalPrice,
+		arg.ID,
+	)
+	return err
+}
@@ -6,12 +6,29 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Conn is what DB needs from a connection: queries and transactions.
+// *pgxpool.Pool implements it.
+type Conn interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 type DB struct {
 	Queries *Queries
 	Pool    *pgxpool.Pool
+	conn    Conn
+}
+
+// NewDB wraps a connection that is not a pool, e.g. a fake in tests
+func NewDB(conn Conn) *DB {
+	return &DB{
+		Queries: New(conn),
+		conn:    conn,
+	}
 }
 
 func NewDatabase(ctx context.Context, cfg string) (*DB, error) {
@@ -46,6 +63,7 @@ func NewDatabase(ctx context.Context, cfg string) (*DB, error) {
 	db := &DB{
 		Queries: New(pool),
 		Pool:    pool,
+		conn:    pool,
 	}
 
 	return db, nil
@@ -55,6 +73,30 @@ func (db *DB) Close() {
 	db.Pool.Close()
 }
 
+// WithTx runs fn inside a transaction, committing if it returns nil and
+// rolling back otherwise.
+func (db *DB) WithTx(ctx context.Context, fn func(q *Queries) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(db.Queries.WithTx(tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Begin starts a transaction the caller commits or rolls back
+func (db *DB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return db.conn.Begin(ctx)
+}
+
 func (db *DB) HealthCheck(ctx context.Context) error {
 	// Basic ping
 	if err := db.Pool.Ping(ctx); err != nil {
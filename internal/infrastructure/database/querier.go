@@ -6,40 +6,116 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddEstimateOrderItemOptions(ctx context.Context, arg AddEstimateOrderItemOptionsParams) error
+	AddMerchantImportRows(ctx context.Context, arg AddMerchantImportRowsParams) error
+	AddMerchantOpeningExceptions(ctx context.Context, arg AddMerchantOpeningExceptionsParams) error
+	AddMerchantOpeningHours(ctx context.Context, arg AddMerchantOpeningHoursParams) error
+	AddMerchantServiceZoneCells(ctx context.Context, arg AddMerchantServiceZoneCellsParams) error
+	AdjustItemStock(ctx context.Context, arg AdjustItemStockParams) error
+	AssignOrderCourier(ctx context.Context, arg AssignOrderCourierParams) (int64, error)
 	CheckEmailExistsForRole(ctx context.Context, arg CheckEmailExistsForRoleParams) (bool, error)
 	CheckUsernameExists(ctx context.Context, username string) (bool, error)
+	ClaimMerchantImport(ctx context.Context, staleBefore time.Time) (ClaimMerchantImportRow, error)
+	ClaimPromotion(ctx context.Context, id uuid.UUID) (*int, error)
+	CopyEstimateOrderItemOptions(ctx context.Context, arg CopyEstimateOrderItemOptionsParams) error
+	CopyItems(ctx context.Context, arg []CopyItemsParams) (int64, error)
 	CountItemsByMerchant(ctx context.Context, arg CountItemsByMerchantParams) (int64, error)
+	CountNearbyMerchants(ctx context.Context, arg CountNearbyMerchantsParams) (int64, error)
 	CountSearchMerchants(ctx context.Context, arg CountSearchMerchantsParams) (int64, error)
+	CountUserOrders(ctx context.Context, arg CountUserOrdersParams) (int64, error)
+	CountUserPromotionRedemptions(ctx context.Context, arg CountUserPromotionRedemptionsParams) ([]CountUserPromotionRedemptionsRow, error)
+	CountUserRedemptions(ctx context.Context, arg CountUserRedemptionsParams) (int64, error)
 	CreateEstimate(ctx context.Context, arg CreateEstimateParams) (CreateEstimateRow, error)
+	CreateEstimateDiscount(ctx context.Context, arg CreateEstimateDiscountParams) error
 	CreateEstimateOrder(ctx context.Context, arg CreateEstimateOrderParams) error
-	CreateEstimateOrderItem(ctx context.Context, arg CreateEstimateOrderItemParams) error
+	CreateEstimateOrderItem(ctx context.Context, arg CreateEstimateOrderItemParams) (uuid.UUID, error)
 	CreateItem(ctx context.Context, arg CreateItemParams) (uuid.UUID, error)
 	CreateMerchant(ctx context.Context, arg CreateMerchantParams) (CreateMerchantRow, error)
+	CreateMerchantImport(ctx context.Context, arg CreateMerchantImportParams) (uuid.UUID, error)
 	CreateOrderFromEstimate(ctx context.Context, dollar_1 uuid.UUID) (CreateOrderFromEstimateRow, error)
-	CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) error
+	CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) (uuid.UUID, error)
 	CreateOrderMerchant(ctx context.Context, arg CreateOrderMerchantParams) (uuid.UUID, error)
+	CreateOrderStatusHistory(ctx context.Context, arg CreateOrderStatusHistoryParams) error
+	CreatePromotionRedemption(ctx context.Context, arg CreatePromotionRedemptionParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (Users, error)
-	GetAllMerchantsWithItemsSortedByH3Distance(ctx context.Context, arg GetAllMerchantsWithItemsSortedByH3DistanceParams) ([]GetAllMerchantsWithItemsSortedByH3DistanceRow, error)
+	DeleteItem(ctx context.Context, arg DeleteItemParams) (int64, error)
+	DeleteItemOptionGroupsExcept(ctx context.Context, arg DeleteItemOptionGroupsExceptParams) error
+	DeleteItemOptionsExcept(ctx context.Context, arg DeleteItemOptionsExceptParams) error
+	DeleteMerchant(ctx context.Context, arg DeleteMerchantParams) (int64, error)
+	DeleteMerchantOpeningExceptions(ctx context.Context, merchantID uuid.UUID) error
+	DeleteMerchantOpeningHours(ctx context.Context, merchantID uuid.UUID) error
+	DeleteMerchantServiceZone(ctx context.Context, merchantID uuid.UUID) error
+	FindDuplicateMerchant(ctx context.Context, arg FindDuplicateMerchantParams) (FindDuplicateMerchantRow, error)
+	FinishMerchantImport(ctx context.Context, arg FinishMerchantImportParams) error
+	GetAdminMerchant(ctx context.Context, arg GetAdminMerchantParams) (GetAdminMerchantRow, error)
 	GetEstimateById(ctx context.Context, dollar_1 uuid.UUID) (Estimates, error)
+	GetEstimateCurrentSubtotals(ctx context.Context, estimateID uuid.UUID) ([]GetEstimateCurrentSubtotalsRow, error)
 	GetEstimateOrderDetails(ctx context.Context, dollar_1 uuid.UUID) ([]GetEstimateOrderDetailsRow, error)
 	GetEstimateOrderIds(ctx context.Context, estimateID uuid.UUID) ([]GetEstimateOrderIdsRow, error)
 	GetItemPrice(ctx context.Context, arg GetItemPriceParams) (int64, error)
 	GetItemPricesByIDsAndMerchants(ctx context.Context, arg GetItemPricesByIDsAndMerchantsParams) ([]GetItemPricesByIDsAndMerchantsRow, error)
+	GetMerchantAccess(ctx context.Context, arg GetMerchantAccessParams) (GetMerchantAccessRow, error)
+	GetMerchantDeliveryRadius(ctx context.Context, arg GetMerchantDeliveryRadiusParams) (int, error)
+	GetMerchantImport(ctx context.Context, arg GetMerchantImportParams) (GetMerchantImportRow, error)
 	GetMerchantLatLong(ctx context.Context, merchantID uuid.UUID) (GetMerchantLatLongRow, error)
+	GetMerchantServiceZones(ctx context.Context, merchantID []uuid.UUID) ([]GetMerchantServiceZonesRow, error)
+	GetMerchantTimezone(ctx context.Context, arg GetMerchantTimezoneParams) (string, error)
 	GetMerchantsLatLong(ctx context.Context, merchantID []uuid.UUID) ([]GetMerchantsLatLongRow, error)
 	GetOrderById(ctx context.Context, dollar_1 uuid.UUID) (GetOrderByIdRow, error)
+	GetOrderDetailsByIds(ctx context.Context, orderIds []uuid.UUID) ([]GetOrderDetailsByIdsRow, error)
+	GetOrderStatus(ctx context.Context, id uuid.UUID) (GetOrderStatusRow, error)
+	GetOrderTracking(ctx context.Context, id uuid.UUID) (GetOrderTrackingRow, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow, error)
 	GetUserByUsernameAndRole(ctx context.Context, arg GetUserByUsernameAndRoleParams) (Users, error)
+	GetUserOrder(ctx context.Context, arg GetUserOrderParams) (GetUserOrderRow, error)
 	GetUsersByRole(ctx context.Context, arg GetUsersByRoleParams) ([]GetUsersByRoleRow, error)
-	ListItemsByMerchant(ctx context.Context, arg ListItemsByMerchantParams) ([]Items, error)
+	HasActiveItemOrders(ctx context.Context, itemID uuid.UUID) (bool, error)
+	HasActiveMerchantOrders(ctx context.Context, merchantID uuid.UUID) (bool, error)
+	IsOrderMerchantAdmin(ctx context.Context, arg IsOrderMerchantAdminParams) (bool, error)
+	IsPlatformAdmin(ctx context.Context, id uuid.UUID) (bool, error)
+	ItemExists(ctx context.Context, arg ItemExistsParams) (bool, error)
+	ListApplicablePromotions(ctx context.Context, code string) ([]ListApplicablePromotionsRow, error)
+	ListBusyCouriers(ctx context.Context, courierIds []uuid.UUID) ([]uuid.UUID, error)
+	ListEstimateDiscounts(ctx context.Context, estimateID uuid.UUID) ([]ListEstimateDiscountsRow, error)
+	ListItemOptions(ctx context.Context, itemIds []uuid.UUID) ([]ListItemOptionsRow, error)
+	ListItemsByMerchant(ctx context.Context, arg ListItemsByMerchantParams) ([]ListItemsByMerchantRow, error)
+	ListItemsByMerchantIds(ctx context.Context, merchantIds []uuid.UUID) ([]ListItemsByMerchantIdsRow, error)
+	ListMerchantImportRows(ctx context.Context, importID uuid.UUID) ([]ListMerchantImportRowsRow, error)
+	ListMerchantOpeningExceptions(ctx context.Context, arg ListMerchantOpeningExceptionsParams) ([]ListMerchantOpeningExceptionsRow, error)
+	ListMerchantOpeningHours(ctx context.Context, merchantIds []uuid.UUID) ([]ListMerchantOpeningHoursRow, error)
+	ListMerchantServiceZone(ctx context.Context, merchantID uuid.UUID) ([]string, error)
+	ListMerchantTimezones(ctx context.Context, merchantIds []uuid.UUID) ([]ListMerchantTimezonesRow, error)
+	ListNearbyMerchants(ctx context.Context, arg ListNearbyMerchantsParams) ([]ListNearbyMerchantsRow, error)
+	ListOrderItemOptions(ctx context.Context, orderIds []uuid.UUID) ([]OrderItemOptions, error)
+	ListOrderItemQuantities(ctx context.Context, orderID uuid.UUID) ([]ListOrderItemQuantitiesRow, error)
+	ListOrderStatusHistory(ctx context.Context, orderID uuid.UUID) ([]OrderStatusHistory, error)
+	ListPendingMerchantImportRows(ctx context.Context, arg ListPendingMerchantImportRowsParams) ([]ListPendingMerchantImportRowsRow, error)
+	ListPromotionsByIds(ctx context.Context, ids []uuid.UUID) ([]ListPromotionsByIdsRow, error)
+	ListUndispatchedOrders(ctx context.Context, limitCount int32) ([]ListUndispatchedOrdersRow, error)
+	ListUserOrders(ctx context.Context, arg ListUserOrdersParams) ([]ListUserOrdersRow, error)
+	LockItemsForUpdate(ctx context.Context, itemIds []uuid.UUID) ([]LockItemsForUpdateRow, error)
 	MerchantExists(ctx context.Context, id uuid.UUID) (bool, error)
+	ReleaseDueOrders(ctx context.Context, limitCount int32) ([]ReleaseDueOrdersRow, error)
+	ReleaseOrderPromotions(ctx context.Context, orderID uuid.UUID) error
 	SearchMerchantsAsc(ctx context.Context, arg SearchMerchantsAscParams) ([]SearchMerchantsAscRow, error)
 	SearchMerchantsDesc(ctx context.Context, arg SearchMerchantsDescParams) ([]SearchMerchantsDescRow, error)
+	TouchMerchantImport(ctx context.Context, importID uuid.UUID) error
+	UpdateItem(ctx context.Context, arg UpdateItemParams) (int64, error)
+	UpdateItemStock(ctx context.Context, arg UpdateItemStockParams) (int64, error)
+	UpdateMerchant(ctx context.Context, arg UpdateMerchantParams) (UpdateMerchantRow, error)
+	UpdateMerchantDeliveryRadius(ctx context.Context, arg UpdateMerchantDeliveryRadiusParams) (int64, error)
+	UpdateMerchantImportRow(ctx context.Context, arg UpdateMerchantImportRowParams) (int64, error)
+	UpdateMerchantTimezone(ctx context.Context, arg UpdateMerchantTimezoneParams) (int64, error)
+	UpdateOrderPrice(ctx context.Context, arg UpdateOrderPriceParams) error
+	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (int64, error)
+	UpsertItemOptionGroup(ctx context.Context, arg UpsertItemOptionGroupParams) (uuid.UUID, error)
+	UpsertItemOptions(ctx context.Context, arg UpsertItemOptionsParams) error
 	VerifyAdminByID(ctx context.Context, id uuid.UUID) (VerifyAdminByIDRow, error)
 	VerifyUserByID(ctx context.Context, id uuid.UUID) (VerifyUserByIDRow, error)
 }
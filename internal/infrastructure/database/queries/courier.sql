@@ -0,0 +1,24 @@
+-- name: ListUndispatchedOrders :many
+SELECT o.id, m.id AS merchant_id, m.lat, m.lng
+FROM orders o
+JOIN order_merchants om ON om.order_id = o.id AND om.is_starting_point
+JOIN merchants m ON m.id = om.merchant_id
+WHERE o.courier_id IS NULL
+    AND o.released_at IS NOT NULL
+    AND o.status IN ('placed', 'accepted', 'preparing')
+ORDER BY o.created_at, o.id
+LIMIT @limit_count;
+
+-- name: ListBusyCouriers :many
+SELECT DISTINCT courier_id::uuid AS courier_id
+FROM orders
+WHERE courier_id = ANY(@courier_ids::uuid[])
+    AND status NOT IN ('delivered', 'cancelled');
+
+-- name: AssignOrderCourier :execrows
+UPDATE orders
+SET courier_id = @courier_id, updated_at = NOW()
+WHERE id = @id
+    AND courier_id IS NULL
+    AND released_at IS NOT NULL
+    AND status IN ('placed', 'accepted', 'preparing');
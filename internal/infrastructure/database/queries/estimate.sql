@@ -1,53 +1,81 @@
 -- name: GetMerchantLatLong :one
 SELECT id, lat, lng
 FROM merchants
-WHERE id = @merchant_id::uuid;
+WHERE id = @merchant_id::uuid AND deleted_at IS NULL;
 
 -- name: GetItemPrice :one
 SELECT price
 FROM items
-WHERE id = @item_id::uuid AND merchant_id = @merchant_id::uuid;
+WHERE id = @item_id::uuid AND merchant_id = @merchant_id::uuid AND deleted_at IS NULL;
 
 -- name: GetMerchantsLatLong :many
-SELECT id, lat, lng
+SELECT id, lat, lng, delivery_radius_meters, merchant_category, preparation_time_minutes
 FROM merchants
-WHERE id = ANY(@merchant_id::uuid[]);
+WHERE id = ANY(@merchant_id::uuid[]) AND deleted_at IS NULL;
+
+-- name: GetMerchantServiceZones :many
+SELECT merchant_id, h3_cell::text AS h3_cell
+FROM merchant_service_zones
+WHERE merchant_id = ANY(@merchant_id::uuid[]);
 
 -- name: GetItemPricesByIDsAndMerchants :many
-SELECT i.id, i.merchant_id, i.price
+SELECT i.id, i.merchant_id, i.price, i.preparation_time_minutes, i.product_category, i.stock, i.is_available
 FROM items i
 JOIN (
     SELECT 
         UNNEST(@item_id::uuid[]) AS item_id,
         UNNEST(@merchant_id::uuid[]) AS merchant_id
-) AS pairs ON i.id = pairs.item_id AND i.merchant_id = pairs.merchant_id;
+) AS pairs ON i.id = pairs.item_id AND i.merchant_id = pairs.merchant_id
+WHERE i.deleted_at IS NULL;
 
 -- name: GetEstimateById :one
-SELECT id, user_id, user_lat, user_lng, total_price, estimated_delivery_time_in_minutes, created_at
+SELECT id, user_id, user_lat, user_lng, total_price, estimated_delivery_time_in_minutes, created_at,
+    final_leg_distance_meters, final_leg_time_in_minutes,
+    items_total, base_fee, distance_fee, extra_stop_fee, small_order_fee, service_fee,
+    expires_at, scheduled_delivery_at, discount_total
 FROM estimates
 WHERE id = $1::uuid;
 
 -- name: CreateEstimate :one
 INSERT INTO estimates (
-    user_id, user_lat, user_lng, total_price, estimated_delivery_time_in_minutes
+    user_id, user_lat, user_lng, total_price, estimated_delivery_time_in_minutes,
+    final_leg_distance_meters, final_leg_time_in_minutes,
+    items_total, base_fee, distance_fee, extra_stop_fee, small_order_fee, service_fee,
+    expires_at, scheduled_delivery_at, discount_total
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7,
+    $8, $9, $10, $11, $12, $13,
+    $14, $15, $16
 )
 RETURNING id, total_price, estimated_delivery_time_in_minutes;
 
 -- name: CreateEstimateOrder :exec
 INSERT INTO estimate_orders (
-    estimate_id, merchant_id, is_starting_point
+    estimate_id, merchant_id, is_starting_point,
+    stop_sequence, leg_distance_meters, leg_time_in_minutes, subtotal
 ) VALUES (
-    @estimate_id, @merchant_id, @is_starting_point
+    @estimate_id, @merchant_id, @is_starting_point,
+    @stop_sequence, @leg_distance_meters, @leg_time_in_minutes, @subtotal
 );
 
--- name: CreateEstimateOrderItem :exec
+-- name: CreateEstimateOrderItem :one
 INSERT INTO estimate_order_items (
-    estimate_order_id, item_id, quantity
+    estimate_order_id, item_id, quantity, unit_price, options_price
 ) VALUES (
-    @estimate_order_id, @item_id, @quantity
-);
+    @estimate_order_id, @item_id, @quantity, @unit_price, @options_price
+)
+RETURNING id;
+
+-- name: AddEstimateOrderItemOptions :exec
+INSERT INTO estimate_order_item_options (
+    estimate_order_item_id, option_id, group_name, option_name, price_delta
+)
+SELECT
+    @estimate_order_item_id,
+    UNNEST(@option_ids::uuid[]),
+    UNNEST(@group_names::text[]),
+    UNNEST(@option_names::text[]),
+    UNNEST(@price_deltas::bigint[]);
 
 -- name: GetEstimateOrderIds :many
 SELECT id, merchant_id
@@ -55,26 +83,79 @@ FROM estimate_orders
 WHERE estimate_id = @estimate_id
 ORDER BY id;
 
--- name: GetAllMerchantsWithItemsSortedByH3Distance :many
+-- name: GetEstimateCurrentSubtotals :many
+-- One row per merchant and product category, so promotions limited to either
+-- can be recomputed. Options the merchant removed since the estimate keep
+-- their quoted price.
+SELECT eo.merchant_id, i.product_category, SUM((i.price + COALESCE(opt.price_delta, 0)) * eoi.quantity)::bigint AS subtotal
+FROM estimate_orders eo
+JOIN estimate_order_items eoi ON eo.id = eoi.estimate_order_id
+JOIN items i ON i.id = eoi.item_id
+LEFT JOIN LATERAL (
+    SELECT SUM(COALESCE(io.price_delta, eoio.price_delta)) AS price_delta
+    FROM estimate_order_item_options eoio
+    LEFT JOIN item_options io ON io.id = eoio.option_id
+    WHERE eoio.estimate_order_item_id = eoi.id
+) AS opt ON TRUE
+WHERE eo.estimate_id = @estimate_id
+GROUP BY eo.merchant_id, i.product_category;
+
+-- name: ListNearbyMerchants :many
+-- Reads only merchants whose res-8 cell is within the given number of rings
+-- of the user's cell (idx_merchants_h3_search_cell); m.id keeps the order stable.
 SELECT
-    m.id AS merchant_id,
-    m.name AS merchant_name,
+    m.id,
+    m.name,
     m.merchant_category,
-    m.image_url AS merchant_image_url,
+    m.image_url,
     m.lat,
     m.lng,
-    m.created_at AS merchant_created_at,
-    i.id AS item_id,
-    i.name AS item_name,
-    i.product_category,
-    i.price,
-    i.image_url AS item_image_url,
-    i.created_at AS item_created_at,
-    h3_grid_distance(
-        h3_latlng_to_cell(Point($1, $2), 10),
-        m.h3_index
-    ) AS h3_distance
+    m.created_at,
+    haversine_meters(@lat::float8, @lng::float8, m.lat, m.lng) AS distance_meters
+FROM merchants m
+WHERE m.deleted_at IS NULL
+    AND h3_cell_to_parent(m.h3_index, 8) IN (
+        SELECT h3_grid_disk(h3_latlng_to_cell(Point(@lat::float8, @lng::float8), 8), @rings::int)
+    )
+    AND haversine_meters(@lat::float8, @lng::float8, m.lat, m.lng) <= LEAST(m.delivery_radius_meters, @max_distance::float8)
+    AND (@name::text = '' OR m.name ILIKE '%' || @name::text || '%')
+    AND (@merchant_id::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR m.id = @merchant_id::uuid)
+    AND (@merchant_category::text = '' OR m.merchant_category = @merchant_category::text)
+    AND (
+        NOT EXISTS (SELECT 1 FROM merchant_service_zones z WHERE z.merchant_id = m.id)
+        OR EXISTS (
+            SELECT 1 FROM merchant_service_zones z
+            WHERE z.merchant_id = m.id
+                AND h3_cell_to_parent(h3_latlng_to_cell(Point(@lat::float8, @lng::float8), 10), h3_get_resolution(z.h3_cell)) = z.h3_cell
+        )
+    )
+    AND EXISTS (SELECT 1 FROM items i WHERE i.merchant_id = m.id AND i.deleted_at IS NULL)
+ORDER BY distance_meters ASC, m.created_at DESC, m.id ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: CountNearbyMerchants :one
+SELECT COUNT(*)
 FROM merchants m
-JOIN items i ON m.id = i.merchant_id
-WHERE ($3 = '' OR m.name ILIKE '%' || $3 || '%')
-ORDER BY h3_distance ASC, m.created_at DESC, i.created_at ASC; 
+WHERE m.deleted_at IS NULL
+    AND h3_cell_to_parent(m.h3_index, 8) IN (
+        SELECT h3_grid_disk(h3_latlng_to_cell(Point(@lat::float8, @lng::float8), 8), @rings::int)
+    )
+    AND haversine_meters(@lat::float8, @lng::float8, m.lat, m.lng) <= LEAST(m.delivery_radius_meters, @max_distance::float8)
+    AND (@name::text = '' OR m.name ILIKE '%' || @name::text || '%')
+    AND (@merchant_id::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR m.id = @merchant_id::uuid)
+    AND (@merchant_category::text = '' OR m.merchant_category = @merchant_category::text)
+    AND (
+        NOT EXISTS (SELECT 1 FROM merchant_service_zones z WHERE z.merchant_id = m.id)
+        OR EXISTS (
+            SELECT 1 FROM merchant_service_zones z
+            WHERE z.merchant_id = m.id
+                AND h3_cell_to_parent(h3_latlng_to_cell(Point(@lat::float8, @lng::float8), 10), h3_get_resolution(z.h3_cell)) = z.h3_cell
+        )
+    )
+    AND EXISTS (SELECT 1 FROM items i WHERE i.merchant_id = m.id AND i.deleted_at IS NULL);
+
+-- name: ListItemsByMerchantIds :many
+SELECT id, merchant_id, name, product_category, price, image_url, created_at
+FROM items
+WHERE merchant_id = ANY(@merchant_ids::uuid[]) AND deleted_at IS NULL
+ORDER BY merchant_id, created_at ASC, id ASC;
@@ -0,0 +1,56 @@
+-- name: ListItemOptions :many
+SELECT
+    g.item_id,
+    g.id AS group_id,
+    g.name AS group_name,
+    g.min_selections,
+    g.max_selections,
+    o.id AS option_id,
+    o.name AS option_name,
+    o.price_delta
+FROM item_option_groups g
+JOIN item_options o ON o.group_id = g.id
+WHERE g.item_id = ANY(@item_ids::uuid[])
+ORDER BY g.item_id, g.sort_order, g.id, o.sort_order, o.id;
+
+-- name: UpsertItemOptionGroup :one
+-- A zero group_id creates a new group. An existing group keeps its id, and
+-- is only updated when it belongs to the item.
+INSERT INTO item_option_groups (id, item_id, name, min_selections, max_selections, sort_order)
+VALUES (
+    COALESCE(NULLIF(@group_id::uuid, '00000000-0000-0000-0000-000000000000'::uuid), uuidv7()),
+    @item_id, @name, @min_selections, @max_selections, @sort_order
+)
+ON CONFLICT (id) DO UPDATE SET
+    name = EXCLUDED.name,
+    min_selections = EXCLUDED.min_selections,
+    max_selections = EXCLUDED.max_selections,
+    sort_order = EXCLUDED.sort_order
+WHERE item_option_groups.item_id = EXCLUDED.item_id
+RETURNING id;
+
+-- name: UpsertItemOptions :exec
+-- Zero option_ids create new options. Existing options keep their id, which
+-- estimates and orders refer to, and may move to another group of the item.
+INSERT INTO item_options (id, group_id, name, price_delta, sort_order)
+SELECT
+    COALESCE(NULLIF(o.id, '00000000-0000-0000-0000-000000000000'::uuid), uuidv7()),
+    @group_id, o.name, o.price_delta, o.ord
+FROM UNNEST(@option_ids::uuid[], @names::text[], @price_deltas::bigint[]) WITH ORDINALITY AS o(id, name, price_delta, ord)
+ON CONFLICT (id) DO UPDATE SET
+    group_id = EXCLUDED.group_id,
+    name = EXCLUDED.name,
+    price_delta = EXCLUDED.price_delta,
+    sort_order = EXCLUDED.sort_order;
+
+-- name: DeleteItemOptionsExcept :exec
+DELETE FROM item_options o
+USING item_option_groups g
+WHERE g.id = o.group_id
+    AND g.item_id = @item_id
+    AND NOT (o.id = ANY(@keep_ids::uuid[]));
+
+-- name: DeleteItemOptionGroupsExcept :exec
+DELETE FROM item_option_groups
+WHERE item_id = @item_id
+    AND NOT (id = ANY(@keep_ids::uuid[]));
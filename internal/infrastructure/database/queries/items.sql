@@ -4,23 +4,58 @@ INSERT INTO items (
     name,
     product_category,
     price,
-    image_url
+    image_url,
+    preparation_time_minutes,
+    stock,
+    is_available
 ) VALUES (
     @merchantId::uuid,
     @name::text,
     @productCategory::text,
     @price::bigint,
-    @imageUrl::text
+    @imageUrl::text,
+    sqlc.narg(preparationTimeMinutes)::int,
+    sqlc.narg(stock)::int,
+    @isAvailable::bool
 )
 RETURNING id;
 
+-- name: CopyItems :copyfrom
+INSERT INTO items (
+    id,
+    merchant_id,
+    name,
+    product_category,
+    price,
+    image_url,
+    preparation_time_minutes,
+    stock,
+    is_available,
+    created_at
+) VALUES (
+    @id,
+    @merchant_id,
+    @name,
+    @product_category,
+    @price,
+    @image_url,
+    @preparation_time_minutes,
+    @stock,
+    @is_available,
+    @created_at
+);
+
 -- name: MerchantExists :one
-SELECT EXISTS(SELECT 1 FROM merchants WHERE id = $1);
+SELECT EXISTS(SELECT 1 FROM merchants WHERE id = $1 AND deleted_at IS NULL);
+
+-- name: ItemExists :one
+SELECT EXISTS(SELECT 1 FROM items WHERE id = @item_id AND merchant_id = @merchant_id AND deleted_at IS NULL);
 
 -- name: ListItemsByMerchant :many
-SELECT id, merchant_id, name, product_category, price, image_url, created_at
+SELECT id, merchant_id, name, product_category, price, image_url, created_at, preparation_time_minutes, stock, is_available
 FROM items
 WHERE merchant_id = @merchant_id
+    AND deleted_at IS NULL
     AND (@item_id::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR id = @item_id::uuid)
     AND (@name::text IS NULL OR @name::text = '' OR name ILIKE '%' || @name::text || '%')
     AND (@product_category::text IS NULL OR @product_category::text = '' OR product_category = @product_category)
@@ -34,6 +69,66 @@ LIMIT @limitPage OFFSET @offsetPage;
 SELECT COUNT(*)
 FROM items
 WHERE merchant_id = @merchant_id
+    AND deleted_at IS NULL
   AND (@item_id::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR id = @item_id::uuid)
   AND (@name::text = '' OR name ILIKE '%' || @name::text || '%')
-  AND (@product_category::text = '' OR product_category = @product_category::text);
\ No newline at end of file
+  AND (@product_category::text = '' OR product_category = @product_category::text);
+
+-- name: UpdateItemStock :execrows
+UPDATE items
+SET stock = sqlc.narg(stock)::int, is_available = @is_available
+WHERE id = @item_id AND merchant_id = @merchant_id AND deleted_at IS NULL;
+
+-- name: UpdateItem :execrows
+UPDATE items
+SET
+    name = COALESCE(sqlc.narg(name), name),
+    product_category = COALESCE(sqlc.narg(product_category), product_category),
+    price = COALESCE(sqlc.narg(price), price),
+    image_url = COALESCE(sqlc.narg(image_url), image_url),
+    preparation_time_minutes = COALESCE(sqlc.narg(preparation_time_minutes), preparation_time_minutes),
+    stock = COALESCE(sqlc.narg(stock), stock),
+    is_available = COALESCE(sqlc.narg(is_available), is_available)
+WHERE id = @item_id AND merchant_id = @merchant_id AND deleted_at IS NULL;
+
+-- name: HasActiveItemOrders :one
+SELECT EXISTS(
+    SELECT 1
+    FROM order_items oi
+    JOIN order_merchants om ON om.id = oi.order_merchant_id
+    JOIN orders o ON o.id = om.order_id
+    WHERE oi.item_id = @item_id
+      AND o.status NOT IN ('delivered', 'cancelled')
+);
+
+-- name: DeleteItem :execrows
+-- Soft delete: order history keeps pointing at the item.
+UPDATE items
+SET deleted_at = NOW()
+WHERE id = @item_id AND merchant_id = @merchant_id AND deleted_at IS NULL;
+
+-- name: LockItemsForUpdate :many
+-- Rows are locked in id order so concurrent orders on the same items wait
+-- for each other instead of deadlocking. Deleted items are left out.
+SELECT id, stock, is_available
+FROM items
+WHERE id = ANY(@item_ids::uuid[]) AND deleted_at IS NULL
+ORDER BY id
+FOR UPDATE;
+
+-- name: AdjustItemStock :exec
+-- Untracked stock (NULL) stays untracked.
+UPDATE items i
+SET stock = i.stock + d.delta
+FROM (
+    SELECT UNNEST(@item_ids::uuid[]) AS item_id, UNNEST(@deltas::int[]) AS delta
+) AS d
+WHERE i.id = d.item_id AND i.stock IS NOT NULL;
+
+-- name: ListOrderItemQuantities :many
+SELECT oi.item_id, om.merchant_id, SUM(oi.quantity)::int AS quantity
+FROM order_items oi
+JOIN order_merchants om ON om.id = oi.order_merchant_id
+WHERE om.order_id = @order_id
+GROUP BY oi.item_id, om.merchant_id
+ORDER BY oi.item_id;
@@ -0,0 +1,141 @@
+-- name: CreateMerchantImport :one
+INSERT INTO merchant_imports (admin_id, file_name, duplicate_radius_meters, import_duplicates)
+VALUES (@admin_id, @file_name, @duplicate_radius_meters, @import_duplicates)
+RETURNING id;
+
+-- name: AddMerchantImportRows :exec
+-- A preparation time of -1 is stored as NULL (use the category default).
+INSERT INTO merchant_import_rows (
+    import_id, row_number, name, merchant_category, image_url, lat, lng,
+    delivery_radius_meters, preparation_time_minutes, status, errors
+)
+SELECT
+    @import_id,
+    r.row_number,
+    r.name,
+    r.merchant_category,
+    r.image_url,
+    r.lat,
+    r.lng,
+    r.delivery_radius_meters,
+    NULLIF(r.preparation_time_minutes, -1),
+    r.status::merchant_import_row_status,
+    r.errors
+FROM UNNEST(
+    @row_numbers::int[],
+    @names::text[],
+    @merchant_categories::text[],
+    @image_urls::text[],
+    @lats::float8[],
+    @lngs::float8[],
+    @delivery_radii::int[],
+    @preparation_times::int[],
+    @statuses::text[],
+    @errors::text[]
+) AS r(row_number, name, merchant_category, image_url, lat, lng, delivery_radius_meters, preparation_time_minutes, status, errors);
+
+-- name: GetMerchantImport :one
+SELECT
+    i.id,
+    i.file_name,
+    i.status,
+    i.duplicate_radius_meters,
+    i.import_duplicates,
+    i.error,
+    i.created_at,
+    i.started_at,
+    i.finished_at,
+    COUNT(r.row_number) AS total_rows,
+    COUNT(r.row_number) FILTER (WHERE r.status = 'pending') AS pending_rows,
+    COUNT(r.row_number) FILTER (WHERE r.status = 'imported') AS imported_rows,
+    COUNT(r.row_number) FILTER (WHERE r.status = 'duplicate') AS duplicate_rows,
+    COUNT(r.row_number) FILTER (WHERE r.status = 'invalid') AS invalid_rows,
+    COUNT(r.row_number) FILTER (WHERE r.status = 'failed') AS failed_rows
+FROM merchant_imports i
+LEFT JOIN merchant_import_rows r ON r.import_id = i.id
+WHERE i.id = @import_id AND i.admin_id = @admin_id
+GROUP BY i.id;
+
+-- name: ListMerchantImportRows :many
+SELECT
+    row_number,
+    name,
+    merchant_category,
+    status,
+    errors,
+    merchant_id,
+    duplicate_of,
+    duplicate_distance_meters
+FROM merchant_import_rows
+WHERE import_id = @import_id
+ORDER BY row_number;
+
+-- name: ClaimMerchantImport :one
+-- Takes the oldest waiting import, or a running one whose worker stopped
+-- reporting progress. SKIP LOCKED lets several workers run side by side.
+UPDATE merchant_imports
+SET status = 'running', started_at = COALESCE(started_at, NOW()), updated_at = NOW()
+WHERE id = (
+    SELECT mi.id
+    FROM merchant_imports mi
+    WHERE mi.status = 'pending'
+        OR (mi.status = 'running' AND mi.updated_at < @stale_before::timestamptz)
+    ORDER BY mi.created_at, mi.id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, admin_id, duplicate_radius_meters, import_duplicates;
+
+-- name: ListPendingMerchantImportRows :many
+SELECT
+    row_number,
+    name,
+    merchant_category,
+    image_url,
+    lat,
+    lng,
+    delivery_radius_meters,
+    preparation_time_minutes
+FROM merchant_import_rows
+WHERE import_id = @import_id AND status = 'pending'
+ORDER BY row_number
+LIMIT @limit_count;
+
+-- name: FindDuplicateMerchant :one
+-- Same name (ignoring case) within radius_meters of the given point, whichever
+-- admin owns it. Only merchants in the res-8 cells around the point are read
+-- (idx_merchants_h3_search_cell), the exact distance decides.
+SELECT
+    m.id,
+    haversine_meters(@lat::float8, @lng::float8, m.lat, m.lng)::float8 AS distance_meters
+FROM merchants m
+WHERE m.deleted_at IS NULL
+    AND h3_cell_to_parent(m.h3_index, 8) IN (
+        SELECT h3_grid_disk(h3_latlng_to_cell(Point(@lat::float8, @lng::float8), 8), @rings::int)
+    )
+    AND LOWER(m.name) = LOWER(@name::text)
+    AND haversine_meters(@lat::float8, @lng::float8, m.lat, m.lng) <= @radius_meters::float8
+ORDER BY distance_meters ASC, m.id ASC
+LIMIT 1;
+
+-- name: UpdateMerchantImportRow :execrows
+-- Only pending rows are updated, so a row another worker already processed
+-- is left alone.
+UPDATE merchant_import_rows
+SET
+    status = @status,
+    errors = @errors,
+    merchant_id = NULLIF(@merchant_id::uuid, '00000000-0000-0000-0000-000000000000'::uuid),
+    duplicate_of = NULLIF(@duplicate_of::uuid, '00000000-0000-0000-0000-000000000000'::uuid),
+    duplicate_distance_meters = sqlc.narg(duplicate_distance_meters)
+WHERE import_id = @import_id AND row_number = @row_number AND status = 'pending';
+
+-- name: TouchMerchantImport :exec
+UPDATE merchant_imports
+SET updated_at = NOW()
+WHERE id = @import_id;
+
+-- name: FinishMerchantImport :exec
+UPDATE merchant_imports
+SET status = @status, error = @error, finished_at = NOW(), updated_at = NOW()
+WHERE id = @import_id;
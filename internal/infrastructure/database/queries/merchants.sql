@@ -1,6 +1,6 @@
 -- name: CreateMerchant :one
-INSERT INTO merchants (admin_id, name, merchant_category, image_url, lat, lng, created_at)
-VALUES ($1, $2, $3, $4, $5, $6, NOW())
+INSERT INTO merchants (admin_id, name, merchant_category, image_url, lat, lng, delivery_radius_meters, preparation_time_minutes, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
 RETURNING id, admin_id, name, merchant_category, image_url, lat, lng, created_at;
 
 -- name: SearchMerchantsDesc :many
@@ -17,6 +17,8 @@ WHERE
     ($1::uuid IS NULL OR $1 = '00000000-0000-0000-0000-000000000000'::uuid OR id = $1)
     AND ($2::text IS NULL OR $2 = '' OR name ILIKE '%' || $2 || '%')
     AND ($3::text IS NULL OR $3 = '' OR merchant_category = $3)
+    AND ($6::uuid IS NULL OR $6 = '00000000-0000-0000-0000-000000000000'::uuid OR admin_id = $6)
+    AND deleted_at IS NULL
 ORDER BY 
     created_at DESC
 LIMIT $4
@@ -36,6 +38,8 @@ WHERE
     ($1::uuid IS NULL OR $1 = '00000000-0000-0000-0000-000000000000'::uuid OR id = $1)
     AND ($2::text IS NULL OR $2 = '' OR name ILIKE '%' || $2 || '%')
     AND ($3::text IS NULL OR $3 = '' OR merchant_category = $3)
+    AND ($6::uuid IS NULL OR $6 = '00000000-0000-0000-0000-000000000000'::uuid OR admin_id = $6)
+    AND deleted_at IS NULL
 ORDER BY 
     created_at ASC
 LIMIT $4 OFFSET $5;
@@ -46,4 +50,95 @@ FROM merchants
 WHERE
     ($1::uuid IS NULL OR $1 = '00000000-0000-0000-0000-000000000000'::uuid OR id = $1)
     AND ($2::text IS NULL OR $2 = '' OR name ILIKE '%' || $2 || '%')
-    AND ($3::text IS NULL OR $3 = '' OR merchant_category = $3);
+    AND ($3::text IS NULL OR $3 = '' OR merchant_category = $3)
+    AND ($4::uuid IS NULL OR $4 = '00000000-0000-0000-0000-000000000000'::uuid OR admin_id = $4)
+    AND deleted_at IS NULL;
+
+-- name: GetMerchantAccess :one
+-- An admin may manage its own merchants; platform admins may manage all.
+SELECT
+    m.admin_id,
+    (m.admin_id = @admin_id OR EXISTS(
+        SELECT 1 FROM users u WHERE u.id = @admin_id AND u.is_platform_admin
+    ))::boolean AS allowed
+FROM merchants m
+WHERE m.id = @merchant_id AND m.deleted_at IS NULL;
+
+-- name: GetAdminMerchant :one
+SELECT
+    id,
+    name,
+    merchant_category,
+    image_url,
+    lat,
+    lng,
+    h3_index::text AS h3_index,
+    delivery_radius_meters,
+    preparation_time_minutes,
+    created_at
+FROM merchants
+WHERE id = @merchant_id AND admin_id = @admin_id AND deleted_at IS NULL;
+
+-- name: UpdateMerchant :one
+-- h3_index is a generated column, so it follows lat/lng automatically.
+UPDATE merchants
+SET
+    name = COALESCE(sqlc.narg(name), name),
+    merchant_category = COALESCE(sqlc.narg(merchant_category), merchant_category),
+    image_url = COALESCE(sqlc.narg(image_url), image_url),
+    lat = COALESCE(sqlc.narg(lat), lat),
+    lng = COALESCE(sqlc.narg(lng), lng),
+    delivery_radius_meters = COALESCE(sqlc.narg(delivery_radius_meters), delivery_radius_meters),
+    preparation_time_minutes = COALESCE(sqlc.narg(preparation_time_minutes), preparation_time_minutes)
+WHERE id = @merchant_id AND admin_id = @admin_id AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    merchant_category,
+    image_url,
+    lat,
+    lng,
+    h3_index::text AS h3_index,
+    delivery_radius_meters,
+    preparation_time_minutes,
+    created_at;
+
+-- name: HasActiveMerchantOrders :one
+SELECT EXISTS(
+    SELECT 1
+    FROM order_merchants om
+    JOIN orders o ON o.id = om.order_id
+    WHERE om.merchant_id = @merchant_id
+      AND o.status NOT IN ('delivered', 'cancelled')
+);
+
+-- name: DeleteMerchant :execrows
+-- Soft delete: order history keeps pointing at the merchant.
+UPDATE merchants
+SET deleted_at = NOW()
+WHERE id = @merchant_id AND admin_id = @admin_id AND deleted_at IS NULL;
+
+-- name: GetMerchantDeliveryRadius :one
+SELECT delivery_radius_meters
+FROM merchants
+WHERE id = @merchant_id AND admin_id = @admin_id AND deleted_at IS NULL;
+
+-- name: UpdateMerchantDeliveryRadius :execrows
+UPDATE merchants
+SET delivery_radius_meters = @delivery_radius_meters
+WHERE id = @merchant_id AND admin_id = @admin_id AND deleted_at IS NULL;
+
+-- name: ListMerchantServiceZone :many
+SELECT h3_cell::text AS h3_cell
+FROM merchant_service_zones
+WHERE merchant_id = @merchant_id
+ORDER BY h3_cell;
+
+-- name: DeleteMerchantServiceZone :exec
+DELETE FROM merchant_service_zones
+WHERE merchant_id = @merchant_id;
+
+-- name: AddMerchantServiceZoneCells :exec
+INSERT INTO merchant_service_zones (merchant_id, h3_cell)
+SELECT @merchant_id, UNNEST(@h3_cells::text[])::h3index
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,49 @@
+-- name: GetMerchantTimezone :one
+SELECT timezone
+FROM merchants
+WHERE id = @merchant_id AND admin_id = @admin_id AND deleted_at IS NULL;
+
+-- name: UpdateMerchantTimezone :execrows
+UPDATE merchants
+SET timezone = @timezone
+WHERE id = @merchant_id AND admin_id = @admin_id AND deleted_at IS NULL;
+
+-- name: ListMerchantTimezones :many
+SELECT id, timezone
+FROM merchants
+WHERE id = ANY(@merchant_ids::uuid[]) AND deleted_at IS NULL;
+
+-- name: ListMerchantOpeningHours :many
+SELECT merchant_id, day_of_week, open_minute, close_minute
+FROM merchant_opening_hours
+WHERE merchant_id = ANY(@merchant_ids::uuid[])
+ORDER BY merchant_id, day_of_week, open_minute;
+
+-- name: DeleteMerchantOpeningHours :exec
+DELETE FROM merchant_opening_hours
+WHERE merchant_id = @merchant_id;
+
+-- name: AddMerchantOpeningHours :exec
+INSERT INTO merchant_opening_hours (merchant_id, day_of_week, open_minute, close_minute)
+SELECT @merchant_id, UNNEST(@days::int[]), UNNEST(@open_minutes::int[]), UNNEST(@close_minutes::int[]);
+
+-- name: ListMerchantOpeningExceptions :many
+SELECT merchant_id, exception_date::text AS exception_date, is_closed, open_minute, close_minute, note
+FROM merchant_opening_exceptions
+WHERE merchant_id = ANY(@merchant_ids::uuid[])
+    AND exception_date >= (@from_date::text)::date
+ORDER BY merchant_id, exception_date;
+
+-- name: DeleteMerchantOpeningExceptions :exec
+DELETE FROM merchant_opening_exceptions
+WHERE merchant_id = @merchant_id;
+
+-- name: AddMerchantOpeningExceptions :exec
+INSERT INTO merchant_opening_exceptions (merchant_id, exception_date, is_closed, open_minute, close_minute, note)
+SELECT
+    @merchant_id,
+    UNNEST(@dates::text[])::date,
+    UNNEST(@is_closed::bool[]),
+    UNNEST(@open_minutes::int[]),
+    UNNEST(@close_minutes::int[]),
+    UNNEST(@notes::text[]);
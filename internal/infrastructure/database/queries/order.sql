@@ -1,38 +1,183 @@
 -- name: CreateOrderFromEstimate :one
+-- A scheduled order is released to its merchants once the estimate's
+-- delivery time (preparation plus route) before the scheduled time is reached.
 INSERT INTO orders (
-    user_id, estimate_id, total_price, estimated_delivery_time_in_minutes
+    user_id, estimate_id, total_price, estimated_delivery_time_in_minutes,
+    items_total, base_fee, distance_fee, extra_stop_fee, small_order_fee, service_fee,
+    discount_total, scheduled_delivery_at, release_at, released_at,
+    final_leg_distance_meters, final_leg_time_in_minutes
 ) 
 SELECT 
-    user_id, id, total_price, estimated_delivery_time_in_minutes
+    user_id, id, total_price, estimated_delivery_time_in_minutes,
+    items_total, base_fee, distance_fee, extra_stop_fee, small_order_fee, service_fee,
+    discount_total, scheduled_delivery_at,
+    scheduled_delivery_at - make_interval(mins => estimated_delivery_time_in_minutes),
+    CASE WHEN scheduled_delivery_at IS NULL THEN NOW() END,
+    final_leg_distance_meters, final_leg_time_in_minutes
 FROM estimates
 WHERE id = $1::uuid
-RETURNING id, total_price, estimated_delivery_time_in_minutes;
+RETURNING id, total_price, estimated_delivery_time_in_minutes, scheduled_delivery_at, release_at;
 
 -- name: GetEstimateOrderDetails :many
+-- current_unit_price and current_options_price are what the line costs now,
+-- used when the order is repriced. Options the merchant removed since the
+-- estimate keep their quoted price.
 SELECT 
     eo.merchant_id,
     eo.is_starting_point,
+    eo.stop_sequence,
+    eo.subtotal,
+    eo.leg_distance_meters,
+    eo.leg_time_in_minutes,
+    eoi.id AS estimate_order_item_id,
     eoi.item_id,
-    eoi.quantity
+    eoi.quantity,
+    eoi.unit_price,
+    eoi.options_price,
+    i.price AS current_unit_price,
+    COALESCE(opt.price_delta, 0)::bigint AS current_options_price
 FROM estimate_orders eo
 JOIN estimate_order_items eoi ON eo.id = eoi.estimate_order_id
+JOIN items i ON i.id = eoi.item_id
+LEFT JOIN LATERAL (
+    SELECT SUM(COALESCE(io.price_delta, eoio.price_delta)) AS price_delta
+    FROM estimate_order_item_options eoio
+    LEFT JOIN item_options io ON io.id = eoio.option_id
+    WHERE eoio.estimate_order_item_id = eoi.id
+) AS opt ON TRUE
 WHERE eo.estimate_id = $1::uuid
-ORDER BY eo.id;
+ORDER BY eo.stop_sequence, eo.id;
 
 -- name: CreateOrderMerchant :one
 INSERT INTO order_merchants (
-    order_id, merchant_id, is_starting_point
+    order_id, merchant_id, is_starting_point, stop_sequence, subtotal,
+    leg_distance_meters, leg_time_in_minutes
 )
-VALUES ($1, $2, $3)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING id;
 
--- name: CreateOrderItem :exec
+-- name: CreateOrderItem :one
 INSERT INTO order_items (
-    order_merchant_id, item_id, quantity
+    order_merchant_id, item_id, quantity, unit_price, options_price
 )
-VALUES ($1, $2, $3);
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id;
+
+-- name: CopyEstimateOrderItemOptions :exec
+-- With current_prices the options are copied at their current price, as in
+-- GetEstimateOrderDetails.
+INSERT INTO order_item_options (order_item_id, option_id, group_name, option_name, price_delta)
+SELECT
+    @order_item_id,
+    eoio.option_id,
+    eoio.group_name,
+    eoio.option_name,
+    CASE WHEN @current_prices::bool THEN COALESCE(io.price_delta, eoio.price_delta) ELSE eoio.price_delta END
+FROM estimate_order_item_options eoio
+LEFT JOIN item_options io ON io.id = eoio.option_id
+WHERE eoio.estimate_order_item_id = @estimate_order_item_id;
 
 -- name: GetOrderById :one
 SELECT id, estimate_id, total_price, estimated_delivery_time_in_minutes, created_at
 FROM orders
-WHERE id = $1::uuid;
\ No newline at end of file
+WHERE id = $1::uuid;
+
+-- name: UpdateOrderPrice :exec
+UPDATE orders
+SET items_total = @items_total,
+    small_order_fee = @small_order_fee,
+    discount_total = @discount_total,
+    total_price = @total_price
+WHERE id = @id;
+
+-- name: ListUserOrders :many
+SELECT o.id, o.status, o.total_price, o.estimated_delivery_time_in_minutes, o.created_at
+FROM orders o
+WHERE o.user_id = @user_id
+    AND o.created_at >= @created_from::timestamptz
+    AND (@created_to::timestamptz = '0001-01-01 00:00:00+00'::timestamptz OR o.created_at <= @created_to::timestamptz)
+    AND EXISTS (
+        SELECT 1
+        FROM order_merchants om
+        JOIN merchants m ON m.id = om.merchant_id
+        WHERE om.order_id = o.id
+            AND (@merchant_id::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR m.id = @merchant_id::uuid)
+            AND (@merchant_category::text = '' OR m.merchant_category = @merchant_category::text)
+            AND (
+                @name::text = ''
+                OR m.name ILIKE '%' || @name::text || '%'
+                OR EXISTS (
+                    SELECT 1
+                    FROM order_items oi
+                    JOIN items i ON i.id = oi.item_id
+                    WHERE oi.order_merchant_id = om.id AND i.name ILIKE '%' || @name::text || '%'
+                )
+            )
+    )
+ORDER BY o.created_at DESC, o.id DESC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: CountUserOrders :one
+SELECT COUNT(*)
+FROM orders o
+WHERE o.user_id = @user_id
+    AND o.created_at >= @created_from::timestamptz
+    AND (@created_to::timestamptz = '0001-01-01 00:00:00+00'::timestamptz OR o.created_at <= @created_to::timestamptz)
+    AND EXISTS (
+        SELECT 1
+        FROM order_merchants om
+        JOIN merchants m ON m.id = om.merchant_id
+        WHERE om.order_id = o.id
+            AND (@merchant_id::uuid = '00000000-0000-0000-0000-000000000000'::uuid OR m.id = @merchant_id::uuid)
+            AND (@merchant_category::text = '' OR m.merchant_category = @merchant_category::text)
+            AND (
+                @name::text = ''
+                OR m.name ILIKE '%' || @name::text || '%'
+                OR EXISTS (
+                    SELECT 1
+                    FROM order_items oi
+                    JOIN items i ON i.id = oi.item_id
+                    WHERE oi.order_merchant_id = om.id AND i.name ILIKE '%' || @name::text || '%'
+                )
+            )
+    );
+
+-- name: GetUserOrder :one
+SELECT id, status, total_price, estimated_delivery_time_in_minutes, created_at
+FROM orders
+WHERE id = @id AND user_id = @user_id;
+
+-- name: GetOrderDetailsByIds :many
+SELECT
+    om.order_id,
+    om.id AS order_merchant_id,
+    m.id AS merchant_id,
+    m.name AS merchant_name,
+    m.merchant_category,
+    m.image_url AS merchant_image_url,
+    m.lat,
+    m.lng,
+    m.created_at AS merchant_created_at,
+    i.id AS item_id,
+    i.name AS item_name,
+    i.product_category,
+    oi.unit_price,
+    i.image_url AS item_image_url,
+    i.created_at AS item_created_at,
+    oi.id AS order_item_id,
+    oi.quantity,
+    oi.options_price
+FROM order_merchants om
+JOIN merchants m ON m.id = om.merchant_id
+JOIN order_items oi ON oi.order_merchant_id = om.id
+JOIN items i ON i.id = oi.item_id
+WHERE om.order_id = ANY(@order_ids::uuid[])
+ORDER BY om.order_id, om.stop_sequence, om.id, oi.id;
+
+-- name: ListOrderItemOptions :many
+SELECT oio.order_item_id, oio.option_id, oio.group_name, oio.option_name, oio.price_delta
+FROM order_item_options oio
+JOIN order_items oi ON oi.id = oio.order_item_id
+JOIN order_merchants om ON om.id = oi.order_merchant_id
+WHERE om.order_id = ANY(@order_ids::uuid[])
+ORDER BY oio.order_item_id, oio.group_name, oio.option_name;
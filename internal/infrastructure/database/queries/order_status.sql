@@ -0,0 +1,58 @@
+-- name: GetOrderStatus :one
+SELECT id, user_id, status, released_at
+FROM orders
+WHERE id = @id;
+
+-- name: IsOrderMerchantAdmin :one
+-- The admin must own every merchant of the order, so the admin of one
+-- merchant cannot move the other merchants' part of a shared order.
+-- Platform admins may manage every order.
+SELECT ((EXISTS(
+    SELECT 1 FROM order_merchants om WHERE om.order_id = @order_id
+) AND NOT EXISTS(
+    SELECT 1
+    FROM order_merchants om
+    JOIN merchants m ON m.id = om.merchant_id
+    WHERE om.order_id = @order_id AND m.admin_id <> @admin_id
+)) OR EXISTS(
+    SELECT 1 FROM users u WHERE u.id = @admin_id AND u.is_platform_admin
+))::boolean AS allowed;
+
+-- name: UpdateOrderStatus :execrows
+UPDATE orders
+SET status = @to_status, updated_at = NOW()
+WHERE id = @id AND status = @from_status;
+
+-- name: CreateOrderStatusHistory :exec
+INSERT INTO order_status_history (
+    order_id, from_status, to_status, actor_id, actor_role
+) VALUES (
+    @order_id, @from_status, @to_status, @actor_id, @actor_role
+);
+
+-- name: ListOrderStatusHistory :many
+SELECT id, order_id, from_status, to_status, actor_id, actor_role, created_at
+FROM order_status_history
+WHERE order_id = @order_id
+ORDER BY created_at, id;
+
+-- name: GetOrderTracking :one
+SELECT id, user_id, status, estimated_delivery_time_in_minutes, courier_id, created_at, scheduled_delivery_at
+FROM orders
+WHERE id = @id;
+
+-- name: ReleaseDueOrders :many
+-- SKIP LOCKED lets several scheduler instances release orders side by side.
+UPDATE orders
+SET released_at = NOW(), updated_at = NOW()
+WHERE id IN (
+    SELECT o.id
+    FROM orders o
+    WHERE o.released_at IS NULL
+        AND o.release_at <= NOW()
+        AND o.status = 'placed'
+    ORDER BY o.release_at, o.id
+    LIMIT @limit_count
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, scheduled_delivery_at;
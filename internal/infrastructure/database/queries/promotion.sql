@@ -0,0 +1,71 @@
+-- name: ListApplicablePromotions :many
+-- Automatic promotions plus the voucher matching code, if any.
+SELECT id, COALESCE(code, '')::varchar AS code, name, promotion_type, value, max_discount, min_basket,
+    merchant_id, COALESCE(product_category, '')::varchar AS product_category,
+    usage_limit, per_user_limit, usage_count
+FROM promotions
+WHERE is_active
+    AND starts_at <= NOW()
+    AND (ends_at IS NULL OR ends_at > NOW())
+    AND (code IS NULL OR UPPER(code) = UPPER(@code::text))
+ORDER BY created_at, id;
+
+-- name: ListPromotionsByIds :many
+-- The estimate's promotions, whether or not they are still running; used to
+-- recompute their discounts when an order is repriced.
+SELECT id, COALESCE(code, '')::varchar AS code, name, promotion_type, value, max_discount, min_basket,
+    merchant_id, COALESCE(product_category, '')::varchar AS product_category,
+    usage_limit, per_user_limit, usage_count
+FROM promotions
+WHERE id = ANY(@ids::uuid[]);
+
+-- name: CountUserPromotionRedemptions :many
+SELECT promotion_id, COUNT(*) AS redemptions
+FROM promotion_redemptions
+WHERE user_id = @user_id AND promotion_id = ANY(@promotion_ids::uuid[])
+GROUP BY promotion_id;
+
+-- name: CreateEstimateDiscount :exec
+INSERT INTO estimate_discounts (estimate_id, promotion_id, amount)
+VALUES (@estimate_id, @promotion_id, @amount);
+
+-- name: ListEstimateDiscounts :many
+SELECT promotion_id, amount
+FROM estimate_discounts
+WHERE estimate_id = @estimate_id
+ORDER BY id;
+
+-- name: ClaimPromotion :one
+-- Counts one use against the global limit. The row stays locked until the
+-- order transaction ends, so concurrent redemptions of the same promotion
+-- are serialized and the per-user count read afterwards is up to date.
+UPDATE promotions
+SET usage_count = usage_count + 1
+WHERE id = @id
+    AND is_active
+    AND starts_at <= NOW()
+    AND (ends_at IS NULL OR ends_at > NOW())
+    AND (usage_limit IS NULL OR usage_count < usage_limit)
+RETURNING per_user_limit;
+
+-- name: CountUserRedemptions :one
+SELECT COUNT(*)
+FROM promotion_redemptions
+WHERE promotion_id = @promotion_id AND user_id = @user_id;
+
+-- name: CreatePromotionRedemption :exec
+INSERT INTO promotion_redemptions (promotion_id, user_id, order_id, amount)
+VALUES (@promotion_id, @user_id, @order_id, @amount);
+
+-- name: ReleaseOrderPromotions :exec
+-- Gives back the promotions redeemed by a cancelled order: the redemptions
+-- are removed and no longer count against the usage limits.
+WITH released AS (
+    DELETE FROM promotion_redemptions
+    WHERE order_id = @order_id
+    RETURNING promotion_id
+)
+UPDATE promotions p
+SET usage_count = GREATEST(p.usage_count - 1, 0)
+FROM released r
+WHERE p.id = r.promotion_id;
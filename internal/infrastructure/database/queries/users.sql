@@ -24,6 +24,9 @@ SELECT id, username, role
 FROM users 
 WHERE id = $1 AND role = 'admin';
 
+-- name: IsPlatformAdmin :one
+SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND is_platform_admin);
+
 -- name: VerifyUserByID :one
 SELECT id, username, role
 FROM users 
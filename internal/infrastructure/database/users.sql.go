@@ -42,7 +42,7 @@ func (q *Queries) CheckUsernameExists(ctx context.Context, username string) (boo
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, username, password_hash, email, role, created_at)
 VALUES ($1, $2, $3, $4, $5, NOW())
-RETURNING id, username, password_hash, email, role, created_at
+RETURNING id, username, password_hash, email, role, created_at, is_platform_admin
 `
 
 type CreateUserParams struct {
@@ -69,6 +69,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (Users,
 		&i.Email,
 		&i.Role,
 		&i.CreatedAt,
+		&i.IsPlatformAdmin,
 	)
 	return i, err
 }
@@ -173,6 +174,17 @@ func (q *Queries) GetUsersByRole(ctx context.Context, arg GetUsersByRoleParams)
 	return items, nil
 }
 
+const isPlatformAdmin = `-- name: IsPlatformAdmin :one
+SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND is_platform_admin)
+`
+
+func (q *Queries) IsPlatformAdmin(ctx context.Context, id uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isPlatformAdmin, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const verifyAdminByID = `-- name: VerifyAdminByID :one
 SELECT id, username, role
 FROM users 
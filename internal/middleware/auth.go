@@ -90,6 +90,10 @@ func RequireUser(jwtService *jwt.JWTService) gin.HandlerFunc {
 	return RequireUserRole(jwtService, "user")
 }
 
+func RequireCourier(jwtService *jwt.JWTService) gin.HandlerFunc {
+	return RequireUserRole(jwtService, "courier")
+}
+
 // AdminAuthMiddleware is a simplified admin authentication middleware
 // This is a placeholder implementation - in a real app, you'd inject the JWT service
 func AdminAuthMiddleware() gin.HandlerFunc {
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"belimang/internal/infrastructure/cache"
+	"belimang/internal/pkg/jwt"
+	logger "belimang/internal/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// body dibaca penuh untuk fingerprint; batasnya body terbesar yang
+	// diterima endpoint mana pun (upload import merchant)
+	maxIdempotentBodyBytes = 10 * 1024 * 1024
+	// request yang masih berjalan menahan lock ini; dibuat cukup panjang untuk
+	// transaksi order tapi tetap lepas sendiri jika instance mati
+	idempotencyLockTTL = 30 * time.Second
+)
+
+// idempotentResponse is the response stored for an Idempotency-Key
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	Body        []byte `json:"body"`
+}
+
+// responseRecorder copies everything written to the client so it can be replayed
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response when a mutating request is retried
+// with the same Idempotency-Key. Keys are scoped to the user ID of the
+// caller's token, so two users can never see each other's responses, and a
+// refreshed token keeps replaying the same response. Requests without a valid
+// token are processed normally; the route's auth middleware rejects them.
+// Reusing a key for a different request is rejected with 422, and a retry
+// that arrives while the first request is still running gets 409.
+// Server errors are not stored so the client can retry them.
+func Idempotency(redisCache *cache.RedisCache, jwtService *jwt.JWTService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		userID, ok := idempotencyScope(c, jwtService)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_idempotency_key", "message": "Idempotency-Key must be at most 255 characters"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "body_too_large", "message": "request body is too large"})
+				c.Abort()
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_body", "message": "failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		cacheKey := fmt.Sprintf(cache.IdempotencyKey, hashParts(userID, key))
+		lockKey := cacheKey + ":lock"
+		fingerprint := hashParts(c.Request.Method, c.Request.URL.Path, string(body))
+
+		if replayIdempotentResponse(c, redisCache, cacheKey, fingerprint) {
+			return
+		}
+
+		// token unik per request: retry dengan body yang sama punya fingerprint
+		// yang sama, jadi fingerprint tidak bisa membedakan pemilik lock
+		lockToken := uuid.NewString()
+		locked, err := redisCache.SetNX(ctx, lockKey, lockToken, idempotencyLockTTL)
+		if err != nil {
+			// Redis tidak tersedia: lanjutkan tanpa idempotency daripada menolak request
+			logger.WarnCtx(ctx, "Idempotency lock unavailable, processing without it", "error", err)
+			c.Next()
+			return
+		}
+		if !locked {
+			c.JSON(http.StatusConflict, gin.H{"error": "idempotency_key_in_use", "message": "A request with this Idempotency-Key is still being processed"})
+			c.Abort()
+			return
+		}
+		// lock yang sudah kedaluwarsa bisa diambil retry; jangan hapus lock miliknya
+		defer redisCache.DeleteIfEquals(ctx, lockKey, lockToken)
+
+		// request sebelumnya bisa selesai di antara pengecekan dan lock
+		if replayIdempotentResponse(c, redisCache, cacheKey, fingerprint) {
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		err = redisCache.Set(ctx, cacheKey, idempotentResponse{
+			Fingerprint: fingerprint,
+			Status:      recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}, cache.IdempotencyTTL)
+		if err != nil {
+			logger.WarnCtx(ctx, "Failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// idempotencyScope returns the user ID of the caller's bearer token
+func idempotencyScope(c *gin.Context, jwtService *jwt.JWTService) (string, bool) {
+	tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || tokenString == "" {
+		return "", false
+	}
+	claims, err := jwtService.ValidateToken(tokenString)
+	if err != nil || claims.UserID == "" {
+		return "", false
+	}
+	return claims.UserID, true
+}
+
+// replayIdempotentResponse writes the stored response for cacheKey, if any,
+// and reports whether the request has been answered.
+func replayIdempotentResponse(c *gin.Context, redisCache *cache.RedisCache, cacheKey, fingerprint string) bool {
+	var saved idempotentResponse
+	if err := redisCache.Get(c.Request.Context(), cacheKey, &saved); err != nil {
+		return false
+	}
+
+	if saved.Fingerprint != fingerprint {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "idempotency_key_reused", "message": "Idempotency-Key was already used for a different request"})
+		c.Abort()
+		return true
+	}
+
+	c.Header(IdempotencyReplayedHeader, "true")
+	c.Data(saved.Status, saved.ContentType, saved.Body)
+	c.Abort()
+	return true
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
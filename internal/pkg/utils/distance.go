@@ -28,8 +28,13 @@ func HaversineDistance(lat1, lng1, lat2, lng2 float64) float64 {
 }
 
 func EstimateTimeMinutes(totalDistanceMeters float64) int {
-	km := totalDistanceMeters / 1000.0
+	return int(math.Round(TravelTimeMinutes(totalDistanceMeters)))
+}
+
+// TravelTimeMinutes is the unrounded travel time at SpeedKmH, used when
+// several legs and waits are added up before rounding.
+func TravelTimeMinutes(distanceMeters float64) float64 {
+	km := distanceMeters / 1000.0
 	hours := km / SpeedKmH
-	minutes := hours * 60
-	return int(math.Round(minutes))
+	return hours * 60
 }
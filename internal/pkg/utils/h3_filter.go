@@ -1,14 +1,38 @@
 package utils
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/uber/h3-go/v4"
 )
 
 const (
-	H3_RES_FOR_FILTER  = 10     // resolusi H3
-	EDGE_LENGTH_METERS = 104.8  // panjang sisi heksagon di res 10 (meter)
-	SAFE_ACCEPT_M      = 2500.0 // batas bawah: pasti ≤3000m
-	SAFE_REJECT_M      = 3500.0 // batas atas: pasti >3000m
+	H3_RES_FOR_FILTER         = 10     // resolusi H3
+	EDGE_LENGTH_METERS        = 104.8  // panjang sisi heksagon di res 10 (meter)
+	DEFAULT_DELIVERY_RADIUS_M = 3000.0 // radius default merchant ke user
+
+	// batas geometri sel res 10 di seluruh bumi, dibulatkan ke arah aman:
+	// jarak pusat sel bertetangga antara 91.6m dan 144.8m, jarak pusat ke
+	// sudut sel paling jauh 83.6m
+	H3_MIN_STEP_M        = 91.0
+	H3_MAX_STEP_M        = 145.0
+	H3_MAX_CELL_RADIUS_M = 84.0
+
+	H3_SEARCH_RES = 8 // resolusi sel untuk lookup nearby (h3_grid_disk)
+	// jarak per ring di res 8 (rata-rata panjang sisi); jarak antar pusat sel
+	// bertetangga selalu lebih besar, jadi ring yang dihitung dari nilai ini
+	// pasti menutupi radius yang diminta
+	H3_SEARCH_RING_STEP_M = 461.4
+)
+
+// H3Verdict is the outcome of the H3 pre-filter for a single pair of cells
+type H3Verdict int
+
+const (
+	H3Accept    H3Verdict = iota // pasti dalam radius
+	H3Reject                     // pasti di luar radius
+	H3Ambiguous                  // perlu validasi jarak exact
 )
 
 // konvert lat long ke h3 cell di resolusi H3_RES_FOR_FILTER
@@ -28,3 +52,62 @@ func H3GridDistanceMeters(a, b h3.Cell) float64 {
 	}
 	return float64(gridDist) * EDGE_LENGTH_METERS
 }
+
+// ClassifyH3Distance decides from grid distance alone whether every point of
+// cell a is within radiusMeters of every point of cell b (H3Accept), or none
+// is (H3Reject). The bounds use the worst-case res-10 cell geometry: k steps
+// of at most H3_MAX_STEP_M apart, and at least k·H3_MIN_STEP_M·√3/2 in a
+// straight line, each end off-centre by up to H3_MAX_CELL_RADIUS_M. Anything
+// in between, or pairs whose grid distance cannot be computed, is ambiguous
+// and must be checked with an exact distance.
+func ClassifyH3Distance(a, b h3.Cell, radiusMeters float64) H3Verdict {
+	k, err := h3.GridDistance(a, b)
+	if err != nil {
+		return H3Ambiguous
+	}
+	maxDist := float64(k)*H3_MAX_STEP_M + 2*H3_MAX_CELL_RADIUS_M
+	minDist := float64(k)*H3_MIN_STEP_M*math.Sqrt(3)/2 - 2*H3_MAX_CELL_RADIUS_M
+	switch {
+	case maxDist <= radiusMeters:
+		return H3Accept
+	case minDist > radiusMeters:
+		return H3Reject
+	default:
+		return H3Ambiguous
+	}
+}
+
+// H3SearchRings returns how many h3_grid_disk rings at H3_SEARCH_RES are
+// needed to cover every point within radiusMeters of the centre cell.
+func H3SearchRings(radiusMeters float64) int {
+	if radiusMeters <= 0 {
+		return 1
+	}
+	return int(math.Ceil(radiusMeters/H3_SEARCH_RING_STEP_M)) + 1
+}
+
+// ParseZoneCell parses a service zone cell; zones may use any resolution up
+// to H3_RES_FOR_FILTER so they can be compared against res-10 user cells.
+func ParseZoneCell(s string) (h3.Cell, error) {
+	cell := h3.Cell(h3.IndexFromString(s))
+	if !cell.IsValid() {
+		return 0, fmt.Errorf("invalid h3 cell %q", s)
+	}
+	if cell.Resolution() > H3_RES_FOR_FILTER {
+		return 0, fmt.Errorf("h3 cell %q resolution must be at most %d", s, H3_RES_FOR_FILTER)
+	}
+	return cell, nil
+}
+
+// ZoneContains reports whether cell lies inside any of the zone cells
+func ZoneContains(zone []h3.Cell, cell h3.Cell) bool {
+	for _, z := range zone {
+		if z.Resolution() > cell.Resolution() {
+			continue
+		}
+		if parent, err := cell.Parent(z.Resolution()); err == nil && parent == z {
+			return true
+		}
+	}
+	return false
+}
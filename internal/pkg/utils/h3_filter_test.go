@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// destination returns the point distanceMeters from lat/lng along bearingDeg
+func destination(lat, lng, bearingDeg, distanceMeters float64) (float64, float64) {
+	const R = EarthRadiusKm * 1000
+	lat1Rad := lat * math.Pi / 180
+	lng1Rad := lng * math.Pi / 180
+	bearing := bearingDeg * math.Pi / 180
+	angular := distanceMeters / R
+
+	lat2Rad := math.Asin(math.Sin(lat1Rad)*math.Cos(angular) + math.Cos(lat1Rad)*math.Sin(angular)*math.Cos(bearing))
+	lng2Rad := lng1Rad + math.Atan2(math.Sin(bearing)*math.Sin(angular)*math.Cos(lat1Rad), math.Cos(angular)-math.Sin(lat1Rad)*math.Sin(lat2Rad))
+	return lat2Rad * 180 / math.Pi, lng2Rad * 180 / math.Pi
+}
+
+func TestClassifyH3DistanceAroundRadius(t *testing.T) {
+	origins := []struct {
+		name     string
+		lat, lng float64
+	}{
+		{"jakarta", -6.2, 106.8},
+		{"equator", 0.01, 0.01},
+		{"oslo", 59.91, 10.75},
+	}
+	radii := []float64{1000, DEFAULT_DELIVERY_RADIUS_M, 10000, 20000}
+	offsets := []float64{-100, -10, -1, 1, 10, 100}
+
+	for _, o := range origins {
+		for _, radius := range radii {
+			for _, offset := range offsets {
+				name := fmt.Sprintf("%s/%.0fm/%+.0fm", o.name, radius, offset)
+				t.Run(name, func(t *testing.T) {
+					a, err := LatLonToH3(o.lat, o.lng)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for bearing := 0.0; bearing < 360; bearing += 15 {
+						lat, lng := destination(o.lat, o.lng, bearing, radius+offset)
+						b, err := LatLonToH3(lat, lng)
+						if err != nil {
+							t.Fatal(err)
+						}
+						inside := HaversineDistance(o.lat, o.lng, lat, lng) <= radius
+
+						switch ClassifyH3Distance(a, b, radius) {
+						case H3Accept:
+							if !inside {
+								t.Errorf("bearing %.0f: accepted a point outside the radius", bearing)
+							}
+						case H3Reject:
+							if inside {
+								t.Errorf("bearing %.0f: rejected a point inside the radius", bearing)
+							}
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestClassifyH3DistanceClearCases(t *testing.T) {
+	const lat, lng = -6.2, 106.8
+	tests := []struct {
+		name     string
+		radius   float64
+		distance float64
+		want     H3Verdict
+	}{
+		{"same cell", DEFAULT_DELIVERY_RADIUS_M, 0, H3Accept},
+		{"well inside 3km", DEFAULT_DELIVERY_RADIUS_M, 1000, H3Accept},
+		{"well outside 3km", DEFAULT_DELIVERY_RADIUS_M, 6000, H3Reject},
+		{"well inside 10km", 10000, 4000, H3Accept},
+		{"well outside 10km", 10000, 20000, H3Reject},
+		{"well inside 20km", 20000, 8000, H3Accept},
+		{"well outside 20km", 20000, 40000, H3Reject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := LatLonToH3(lat, lng)
+			bLat, bLng := destination(lat, lng, 45, tt.distance)
+			b, _ := LatLonToH3(bLat, bLng)
+			if got := ClassifyH3Distance(a, b, tt.radius); got != tt.want {
+				t.Errorf("ClassifyH3Distance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
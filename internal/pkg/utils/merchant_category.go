@@ -0,0 +1,16 @@
+package utils
+
+// merchantCategories are the accepted values of merchant_category
+var merchantCategories = map[string]bool{
+	"SmallRestaurant":       true,
+	"MediumRestaurant":      true,
+	"LargeRestaurant":       true,
+	"MerchandiseRestaurant": true,
+	"BoothKiosk":            true,
+	"ConvenienceStore":      true,
+}
+
+// IsMerchantCategory reports whether category is a known merchant category
+func IsMerchantCategory(category string) bool {
+	return merchantCategories[category]
+}
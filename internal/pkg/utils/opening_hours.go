@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // image runtime tidak selalu punya zoneinfo
+)
+
+// DateLayout is the layout of the local dates used for opening exceptions
+const DateLayout = "2006-01-02"
+
+// OpeningWindow is one opening period, in minutes since local midnight. A
+// window whose close is not after its open runs past midnight into the next
+// day; open == close means open around the clock.
+type OpeningWindow struct {
+	OpenMinute  int
+	CloseMinute int
+}
+
+func (w OpeningWindow) overnight() bool {
+	return w.CloseMinute <= w.OpenMinute
+}
+
+// OpeningException replaces the weekly hours of one local date
+type OpeningException struct {
+	Closed bool
+	Window OpeningWindow // ignored when Closed
+}
+
+// OpeningSchedule is a merchant's weekly hours plus its date exceptions.
+// A merchant without weekly hours is open around the clock except on
+// exception dates, so merchants that never set hours keep taking orders.
+type OpeningSchedule struct {
+	Location   *time.Location
+	Weekly     map[time.Weekday][]OpeningWindow
+	Exceptions map[string]OpeningException // keyed by local date, DateLayout
+}
+
+// IsOpenAt reports whether the merchant is open at t
+func (s OpeningSchedule) IsOpenAt(t time.Time) bool {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	for _, w := range s.windowsOn(local) {
+		if w.overnight() {
+			if minute >= w.OpenMinute {
+				return true
+			}
+		} else if minute >= w.OpenMinute && minute < w.CloseMinute {
+			return true
+		}
+	}
+
+	// jendela semalam yang dibuka kemarin masih berlaku sampai jam tutupnya
+	for _, w := range s.windowsOn(local.AddDate(0, 0, -1)) {
+		if w.overnight() && minute < w.CloseMinute {
+			return true
+		}
+	}
+	return false
+}
+
+// windowsOn returns the windows that open on the local date of day
+func (s OpeningSchedule) windowsOn(day time.Time) []OpeningWindow {
+	if ex, ok := s.Exceptions[day.Format(DateLayout)]; ok {
+		if ex.Closed {
+			return nil
+		}
+		return []OpeningWindow{ex.Window}
+	}
+	if len(s.Weekly) == 0 {
+		return []OpeningWindow{{}}
+	}
+	return s.Weekly[day.Weekday()]
+}
+
+// ParseClock parses a "15:04" wall clock time into minutes since midnight
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatClock formats minutes since midnight as "15:04"
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
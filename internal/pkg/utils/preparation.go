@@ -0,0 +1,32 @@
+package utils
+
+// waktu persiapan default (menit) per merchant_category, dipakai jika
+// merchant tidak mengisi preparation_time_minutes
+var defaultPreparationMinutes = map[string]int{
+	"SmallRestaurant":       15,
+	"MediumRestaurant":      20,
+	"LargeRestaurant":       25,
+	"MerchandiseRestaurant": 10,
+	"BoothKiosk":            5,
+	"ConvenienceStore":      3,
+}
+
+const FALLBACK_PREPARATION_MINUTES = 15
+
+// DefaultPreparationMinutes returns the preparation time assumed for a
+// merchant category when the merchant has not set its own.
+func DefaultPreparationMinutes(merchantCategory string) int {
+	if m, ok := defaultPreparationMinutes[merchantCategory]; ok {
+		return m
+	}
+	return FALLBACK_PREPARATION_MINUTES
+}
+
+// MerchantPreparationMinutes resolves a merchant's preparation time, falling
+// back to the default of its category.
+func MerchantPreparationMinutes(merchantCategory string, preparationMinutes *int) int {
+	if preparationMinutes != nil {
+		return *preparationMinutes
+	}
+	return DefaultPreparationMinutes(merchantCategory)
+}
@@ -0,0 +1,23 @@
+-- Persist the optimised delivery route so orders keep the same stop sequence
+
+-- Per-merchant stop: position in the route, the leg arriving at it and its subtotal
+ALTER TABLE estimate_orders
+    ADD COLUMN IF NOT EXISTS stop_sequence INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS leg_distance_meters FLOAT8 NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS leg_time_in_minutes INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS subtotal BIGINT NOT NULL DEFAULT 0;
+
+-- Final leg from the last merchant to the user
+ALTER TABLE estimates
+    ADD COLUMN IF NOT EXISTS final_leg_distance_meters FLOAT8 NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS final_leg_time_in_minutes INT NOT NULL DEFAULT 0;
+
+ALTER TABLE order_merchants
+    ADD COLUMN IF NOT EXISTS stop_sequence INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS subtotal BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_estimate_orders_estimate_sequence
+    ON estimate_orders(estimate_id, stop_sequence);
+
+CREATE INDEX IF NOT EXISTS idx_order_merchants_order_sequence
+    ON order_merchants(order_id, stop_sequence);
@@ -0,0 +1,27 @@
+-- Per-merchant delivery radius and optional H3 service zone
+
+ALTER TABLE merchants
+    ADD COLUMN IF NOT EXISTS delivery_radius_meters INT NOT NULL DEFAULT 3000
+        CHECK (delivery_radius_meters > 0);
+
+-- Admin-defined service zone: a user can only be served if their res-10 cell
+-- lies inside one of these cells (cells may be coarser than res 10)
+CREATE TABLE IF NOT EXISTS merchant_service_zones (
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    h3_cell H3INDEX NOT NULL CHECK (h3_get_resolution(h3_cell) <= 10),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (merchant_id, h3_cell)
+);
+
+-- Great-circle distance in meters, same formula as utils.HaversineDistance
+CREATE OR REPLACE FUNCTION haversine_meters(lat1 FLOAT8, lng1 FLOAT8, lat2 FLOAT8, lng2 FLOAT8)
+RETURNS FLOAT8
+LANGUAGE SQL
+IMMUTABLE
+PARALLEL SAFE
+AS $$
+    SELECT 2 * 6371000 * asin(sqrt(
+        power(sin(radians(lat2 - lat1) / 2), 2) +
+        cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lng2 - lng1) / 2), 2)
+    ))
+$$;
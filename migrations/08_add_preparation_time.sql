@@ -0,0 +1,8 @@
+-- Waktu persiapan merchant dan item (menit).
+-- NULL pada merchant berarti pakai default per merchant_category,
+-- NULL pada item berarti pakai waktu persiapan merchant.
+ALTER TABLE merchants
+    ADD COLUMN IF NOT EXISTS preparation_time_minutes INT CHECK (preparation_time_minutes >= 0);
+
+ALTER TABLE items
+    ADD COLUMN IF NOT EXISTS preparation_time_minutes INT CHECK (preparation_time_minutes >= 0);
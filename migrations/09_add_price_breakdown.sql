@@ -0,0 +1,17 @@
+-- Rincian harga (item + biaya) disimpan agar finance tidak perlu menghitung ulang.
+-- total_price = items_total + semua fee.
+ALTER TABLE estimates
+    ADD COLUMN IF NOT EXISTS items_total BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS base_fee BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS distance_fee BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS extra_stop_fee BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS small_order_fee BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS service_fee BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS items_total BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS base_fee BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS distance_fee BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS extra_stop_fee BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS small_order_fee BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS service_fee BIGINT NOT NULL DEFAULT 0;
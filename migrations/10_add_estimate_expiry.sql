@@ -0,0 +1,9 @@
+-- Estimate hanya berlaku sampai expires_at; estimate lama dianggap
+-- berlaku 15 menit sejak dibuat.
+ALTER TABLE estimates ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+UPDATE estimates
+SET expires_at = created_at + INTERVAL '15 minutes'
+WHERE expires_at IS NULL;
+
+ALTER TABLE estimates ALTER COLUMN expires_at SET NOT NULL;
@@ -0,0 +1,2 @@
+-- Satu estimate hanya boleh menjadi satu order
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_estimate_id_unique ON orders(estimate_id);
@@ -0,0 +1,30 @@
+-- Status order beserta riwayat perubahannya
+CREATE TYPE order_status AS ENUM (
+    'placed', 'accepted', 'preparing', 'picked_up', 'delivered', 'cancelled'
+);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS status order_status NOT NULL DEFAULT 'placed',
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status);
+
+-- actor_id bukan foreign key agar perubahan oleh sistem (uuid nol) tetap tercatat
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status order_status,
+    to_status order_status NOT NULL,
+    actor_id UUID NOT NULL,
+    actor_role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order
+ON order_status_history(order_id, created_at);
+
+-- order yang sudah ada dianggap baru dibuat
+INSERT INTO order_status_history (order_id, from_status, to_status, actor_id, actor_role, created_at)
+SELECT id, NULL, 'placed', user_id, 'user', created_at
+FROM orders o
+WHERE NOT EXISTS (SELECT 1 FROM order_status_history h WHERE h.order_id = o.id);
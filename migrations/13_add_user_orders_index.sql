@@ -0,0 +1,2 @@
+-- Riwayat order user diurutkan dari yang terbaru
+CREATE INDEX IF NOT EXISTS idx_orders_user_created_at ON orders(user_id, created_at DESC, id DESC);
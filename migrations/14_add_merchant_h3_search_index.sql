@@ -0,0 +1,5 @@
+-- Nearby search looks merchants up by the res-8 parent of h3_index so that a
+-- handful of h3_grid_disk rings cover the whole search radius; this index
+-- turns each ring lookup into index probes instead of a full table scan.
+CREATE INDEX IF NOT EXISTS idx_merchants_h3_search_cell
+    ON merchants (h3_cell_to_parent(h3_index, 8));
@@ -0,0 +1,14 @@
+-- Courier accounts and the courier assigned to each order
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'courier';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unique_courier_email ON users (email) WHERE role = 'courier';
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS courier_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- dispatcher mencari order yang belum punya kurir
+CREATE INDEX IF NOT EXISTS idx_orders_undispatched
+ON orders(created_at) WHERE courier_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_courier_status
+ON orders(courier_id, status) WHERE courier_id IS NOT NULL;
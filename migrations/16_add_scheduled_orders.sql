@@ -0,0 +1,18 @@
+-- Deliver-later orders. scheduled_delivery_at NULL berarti dikirim secepatnya.
+ALTER TABLE estimates
+    ADD COLUMN IF NOT EXISTS scheduled_delivery_at TIMESTAMPTZ;
+
+-- release_at: kapan order diteruskan ke merchant (jadwal dikurangi ETA estimate)
+-- released_at: NULL selama order masih menunggu release_at
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS scheduled_delivery_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS release_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS released_at TIMESTAMPTZ;
+
+UPDATE orders
+SET released_at = created_at
+WHERE released_at IS NULL AND scheduled_delivery_at IS NULL;
+
+-- scheduler mencari order terjadwal yang sudah waktunya dirilis
+CREATE INDEX IF NOT EXISTS idx_orders_pending_release
+ON orders(release_at) WHERE released_at IS NULL;
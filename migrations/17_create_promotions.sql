@@ -0,0 +1,52 @@
+-- Promo: voucher (punya code) dan promo otomatis (code NULL)
+CREATE TYPE promotion_type AS ENUM ('percentage', 'flat', 'free_delivery');
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    code VARCHAR(50),
+    name VARCHAR(100) NOT NULL,
+    promotion_type promotion_type NOT NULL,
+    -- persen (1-100) untuk percentage, nominal untuk flat, diabaikan untuk free_delivery
+    value BIGINT NOT NULL DEFAULT 0,
+    max_discount BIGINT NOT NULL DEFAULT 0, -- 0 berarti tanpa batas
+    min_basket BIGINT NOT NULL DEFAULT 0,
+    -- merchant_id / product_category membatasi item yang dihitung diskonnya
+    merchant_id UUID REFERENCES merchants(id) ON DELETE CASCADE,
+    product_category VARCHAR(10),
+    usage_limit INT,    -- NULL berarti tanpa batas
+    per_user_limit INT, -- NULL berarti tanpa batas
+    usage_count INT NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_code ON promotions (UPPER(code)) WHERE code IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_promotions_automatic ON promotions (starts_at) WHERE code IS NULL AND is_active;
+
+-- diskon yang dikutip pada estimate, ditebus saat order dibuat
+CREATE TABLE IF NOT EXISTS estimate_discounts (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    estimate_id UUID NOT NULL REFERENCES estimates(id) ON DELETE CASCADE,
+    promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_estimate_discounts_estimate ON estimate_discounts(estimate_id);
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (promotion_id, order_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_user ON promotion_redemptions(promotion_id, user_id);
+
+ALTER TABLE estimates ADD COLUMN IF NOT EXISTS discount_total BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_total BIGINT NOT NULL DEFAULT 0;
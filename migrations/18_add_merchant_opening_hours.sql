@@ -0,0 +1,24 @@
+-- Jam buka merchant. Merchant tanpa jam mingguan dianggap buka 24 jam.
+ALTER TABLE merchants
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta';
+
+-- Menit dihitung dari tengah malam waktu lokal merchant.
+-- close_minute <= open_minute berarti tutup lewat tengah malam (hari berikutnya).
+CREATE TABLE IF NOT EXISTS merchant_opening_hours (
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    day_of_week INT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6), -- 0 = Minggu
+    open_minute INT NOT NULL CHECK (open_minute BETWEEN 0 AND 1439),
+    close_minute INT NOT NULL CHECK (close_minute BETWEEN 0 AND 1439),
+    PRIMARY KEY (merchant_id, day_of_week, open_minute)
+);
+
+-- Pengecualian per tanggal lokal (libur atau jam khusus), menggantikan jam mingguan hari itu
+CREATE TABLE IF NOT EXISTS merchant_opening_exceptions (
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    exception_date DATE NOT NULL,
+    is_closed BOOLEAN NOT NULL,
+    open_minute INT NOT NULL DEFAULT 0 CHECK (open_minute BETWEEN 0 AND 1439),
+    close_minute INT NOT NULL DEFAULT 0 CHECK (close_minute BETWEEN 0 AND 1439),
+    note VARCHAR(100) NOT NULL DEFAULT '',
+    PRIMARY KEY (merchant_id, exception_date)
+);
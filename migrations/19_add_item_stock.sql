@@ -0,0 +1,5 @@
+-- Stok dan ketersediaan item.
+-- NULL pada stock berarti stok tidak dilacak (tidak terbatas).
+ALTER TABLE items
+    ADD COLUMN IF NOT EXISTS stock INT CHECK (stock >= 0),
+    ADD COLUMN IF NOT EXISTS is_available BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,48 @@
+-- Grup opsi item (ukuran, level pedas, topping) beserta pilihannya
+CREATE TABLE IF NOT EXISTS item_option_groups (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    item_id UUID NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    min_selections INT NOT NULL DEFAULT 0 CHECK (min_selections >= 0),
+    max_selections INT NOT NULL DEFAULT 1 CHECK (max_selections >= 1 AND max_selections >= min_selections),
+    sort_order INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_option_groups_item ON item_option_groups (item_id, sort_order);
+
+CREATE TABLE IF NOT EXISTS item_options (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    group_id UUID NOT NULL REFERENCES item_option_groups(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    price_delta BIGINT NOT NULL DEFAULT 0 CHECK (price_delta >= 0), -- tambahan harga per unit
+    sort_order INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_options_group ON item_options (group_id, sort_order);
+
+-- Opsi yang dipilih disalin (bukan direferensikan) agar estimate dan order
+-- tetap utuh ketika merchant mengubah atau menghapus opsinya.
+CREATE TABLE IF NOT EXISTS estimate_order_item_options (
+    estimate_order_item_id UUID NOT NULL REFERENCES estimate_order_items(id) ON DELETE CASCADE,
+    option_id UUID NOT NULL,
+    group_name VARCHAR(50) NOT NULL,
+    option_name VARCHAR(50) NOT NULL,
+    price_delta BIGINT NOT NULL,
+    PRIMARY KEY (estimate_order_item_id, option_id)
+);
+
+CREATE TABLE IF NOT EXISTS order_item_options (
+    order_item_id UUID NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    option_id UUID NOT NULL,
+    group_name VARCHAR(50) NOT NULL,
+    option_name VARCHAR(50) NOT NULL,
+    price_delta BIGINT NOT NULL,
+    PRIMARY KEY (order_item_id, option_id)
+);
+
+-- total price_delta opsi terpilih per unit
+ALTER TABLE estimate_order_items
+    ADD COLUMN IF NOT EXISTS options_price BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS options_price BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+-- Platform admins may manage every merchant, other admins only their own.
+-- The flag is granted directly in the database, never through registration:
+--   UPDATE users SET is_platform_admin = TRUE WHERE username = '...' AND role = 'admin';
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS is_platform_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- admin hanya melihat merchant miliknya sendiri
+CREATE INDEX IF NOT EXISTS idx_merchants_admin_created_at
+ON merchants(admin_id, created_at);
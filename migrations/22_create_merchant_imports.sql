@@ -0,0 +1,51 @@
+-- Onboarding merchant massal dari CSV. Baris disimpan saat upload lalu
+-- diproses di background, jadi file besar tidak kena timeout request.
+CREATE TYPE merchant_import_status AS ENUM ('pending', 'running', 'completed', 'failed');
+
+-- pending: belum diproses, imported: merchant dibuat, duplicate: dilewati karena
+-- mirip merchant yang sudah ada, invalid: gagal validasi, failed: gagal disimpan
+CREATE TYPE merchant_import_row_status AS ENUM ('pending', 'imported', 'duplicate', 'invalid', 'failed');
+
+CREATE TABLE IF NOT EXISTS merchant_imports (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    admin_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL DEFAULT '',
+    status merchant_import_status NOT NULL DEFAULT 'pending',
+    -- merchant dengan nama sama dalam radius ini dianggap duplikat
+    duplicate_radius_meters INT NOT NULL CHECK (duplicate_radius_meters > 0),
+    import_duplicates BOOLEAN NOT NULL DEFAULT FALSE,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    -- diperbarui worker tiap batch; job running yang lama tidak berubah diambil ulang
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_imports_queue
+ON merchant_imports(created_at) WHERE status IN ('pending', 'running');
+
+CREATE TABLE IF NOT EXISTS merchant_import_rows (
+    import_id UUID NOT NULL REFERENCES merchant_imports(id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    name TEXT NOT NULL,
+    merchant_category TEXT NOT NULL,
+    image_url TEXT NOT NULL,
+    lat FLOAT8 NOT NULL,
+    lng FLOAT8 NOT NULL,
+    delivery_radius_meters INT NOT NULL DEFAULT 0, -- 0 berarti radius default
+    preparation_time_minutes INT,
+    status merchant_import_row_status NOT NULL,
+    errors TEXT NOT NULL DEFAULT '',
+    merchant_id UUID REFERENCES merchants(id) ON DELETE SET NULL,
+    duplicate_of UUID REFERENCES merchants(id) ON DELETE SET NULL,
+    duplicate_distance_meters FLOAT8,
+    PRIMARY KEY (import_id, row_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_import_rows_pending
+ON merchant_import_rows(import_id, row_number) WHERE status = 'pending';
+
+-- cek duplikat membandingkan nama tanpa beda huruf besar/kecil
+CREATE INDEX IF NOT EXISTS idx_merchants_admin_lower_name
+ON merchants(admin_id, LOWER(name));
@@ -0,0 +1,23 @@
+-- Order menyimpan rute yang sama dengan estimate: jarak dan waktu tiap leg
+-- menuju merchant, plus leg terakhir ke user
+ALTER TABLE order_merchants
+    ADD COLUMN IF NOT EXISTS leg_distance_meters FLOAT8 NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS leg_time_in_minutes INT NOT NULL DEFAULT 0;
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS final_leg_distance_meters FLOAT8 NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS final_leg_time_in_minutes INT NOT NULL DEFAULT 0;
+
+-- isi order lama dari estimate asalnya
+UPDATE order_merchants om
+SET leg_distance_meters = eo.leg_distance_meters,
+    leg_time_in_minutes = eo.leg_time_in_minutes
+FROM orders o
+JOIN estimate_orders eo ON eo.estimate_id = o.estimate_id
+WHERE om.order_id = o.id AND eo.merchant_id = om.merchant_id;
+
+UPDATE orders o
+SET final_leg_distance_meters = e.final_leg_distance_meters,
+    final_leg_time_in_minutes = e.final_leg_time_in_minutes
+FROM estimates e
+WHERE e.id = o.estimate_id;
@@ -0,0 +1,18 @@
+-- Harga satuan item disalin saat estimate dan order dibuat, jadi riwayat order
+-- tetap menampilkan harga yang dibayar walau merchant mengubah harganya.
+ALTER TABLE estimate_order_items
+    ADD COLUMN IF NOT EXISTS unit_price BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS unit_price BIGINT NOT NULL DEFAULT 0;
+
+-- Data lama tidak menyimpan harganya; harga saat ini adalah perkiraan terbaik
+UPDATE estimate_order_items eoi
+SET unit_price = i.price
+FROM items i
+WHERE i.id = eoi.item_id;
+
+UPDATE order_items oi
+SET unit_price = i.price
+FROM items i
+WHERE i.id = oi.item_id;
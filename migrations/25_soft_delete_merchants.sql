@@ -0,0 +1,16 @@
+-- Merchant yang dihapus hanya ditandai, karena riwayat order tetap merujuk
+-- ke merchant tersebut. Semua query katalog menyaring deleted_at IS NULL.
+ALTER TABLE merchants
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Pencarian nearby hanya membaca merchant yang masih aktif
+DROP INDEX IF EXISTS idx_merchants_h3_search_cell;
+CREATE INDEX IF NOT EXISTS idx_merchants_h3_search_cell
+    ON merchants (h3_cell_to_parent(h3_index, 8))
+    WHERE deleted_at IS NULL;
+
+-- Riwayat order tidak boleh ikut terhapus bersama merchant
+ALTER TABLE order_merchants
+    DROP CONSTRAINT IF EXISTS order_merchants_merchant_id_fkey,
+    ADD CONSTRAINT order_merchants_merchant_id_fkey
+        FOREIGN KEY (merchant_id) REFERENCES merchants(id) ON DELETE RESTRICT;
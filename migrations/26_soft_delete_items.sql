@@ -0,0 +1,9 @@
+-- Item yang dihapus hanya ditandai, sama seperti merchant, agar riwayat order
+-- tetap utuh. Query katalog dan pemesanan menyaring deleted_at IS NULL.
+ALTER TABLE items
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE order_items
+    DROP CONSTRAINT IF EXISTS order_items_item_id_fkey,
+    ADD CONSTRAINT order_items_item_id_fkey
+        FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE RESTRICT;
@@ -0,0 +1,10 @@
+-- Migration 11 gagal membuat index unik jika sudah ada beberapa order dari
+-- satu estimate (retry sebelum index ada). Semuanya salinan estimate yang
+-- sama, jadi order pertama dipertahankan dan duplikatnya dihapus beserta
+-- order_merchants/order_items/riwayat status (ON DELETE CASCADE).
+DELETE FROM orders o
+USING orders keep
+WHERE keep.estimate_id = o.estimate_id
+    AND (keep.created_at, keep.id) < (o.created_at, o.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_estimate_id_unique ON orders(estimate_id);
@@ -62,3 +62,12 @@ VALUES
    'Air Mineral 600ml', 'Minuman', 5000, 'https://picsum.photos/200/200?random=air.jpg'),
   ('bbbbbbb5-5555-5555-5555-bbbbbbbbbbb2', 'aaaaaaa5-aaaa-aaaa-aaaa-aaaaaaaaaaa5',
    'Chiki Balls', 'Snack', 3000, 'https://picsum.photos/200/200?random=chiki.jpg');
+
+-- Promotions: vouchers (with code) and automatic promotions (code NULL)
+INSERT INTO promotions (code, name, promotion_type, value, max_discount, min_basket, merchant_id, product_category, usage_limit, per_user_limit)
+VALUES
+  ('HEMAT20', 'Diskon 20% maks 15rb', 'percentage', 20, 15000, 50000, NULL, NULL, 1000, 1),
+  ('POTONG10', 'Potongan 10rb', 'flat', 10000, 0, 40000, NULL, NULL, NULL, 3),
+  ('GRATISONGKIR', 'Gratis ongkir', 'free_delivery', 0, 20000, 30000, NULL, NULL, 500, 2),
+  ('SATE15', 'Diskon 15% Sate Enak', 'percentage', 15, 0, 0, 'aaaaaaa1-aaaa-aaaa-aaaa-aaaaaaaaaaa1', NULL, NULL, NULL),
+  (NULL, 'Minuman hemat 5rb', 'flat', 5000, 0, 10000, NULL, 'Minuman', NULL, NULL);
@@ -0,0 +1,30 @@
+-- Benchmark dataset for the nearby lookup: 100k merchants spread over
+-- Jabodetabek (roughly 45 km x 45 km) with 3 items each.
+-- Load with `make seed-bench`, then run `make bench-nearby`.
+
+SELECT setseed(0.42);
+
+INSERT INTO users (id, username, password_hash, email, role)
+VALUES ('99999999-9999-9999-9999-999999999999', 'benchadmin', 'hashedpassword000', 'benchadmin@example.com', 'admin')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO merchants (admin_id, name, merchant_category, image_url, lat, lng, created_at)
+SELECT
+    '99999999-9999-9999-9999-999999999999',
+    'Bench Merchant ' || g,
+    (ARRAY['SmallRestaurant', 'MediumRestaurant', 'LargeRestaurant',
+           'MerchandiseRestaurant', 'BoothKiosk', 'ConvenienceStore'])[1 + (g % 6)],
+    'https://picsum.photos/200/300?random=bench' || g || '.jpg',
+    -6.40 + random() * 0.40,
+    106.65 + random() * 0.40,
+    NOW() - (g || ' seconds')::interval
+FROM generate_series(1, 100000) AS g;
+
+INSERT INTO items (merchant_id, name, product_category, price, image_url)
+SELECT m.id, 'Bench Item ' || n, 'Food', 10000 + n * 5000, 'https://picsum.photos/200/200?random=benchitem' || n || '.jpg'
+FROM merchants m
+CROSS JOIN generate_series(1, 3) AS n
+WHERE m.admin_id = '99999999-9999-9999-9999-999999999999';
+
+ANALYZE merchants;
+ANALYZE items;
@@ -0,0 +1,43 @@
+-- Compares the old full-scan nearby query with the H3 ring lookup used by
+-- PurchaseService.GetMerchantsNearby. Run against the data from
+-- 02_benchmark_merchants.sql:
+--   make bench-nearby
+-- Each query returns the first page (limit 5) for a user in central Jakarta.
+
+\set lat -6.2000
+\set lng 106.8167
+\timing on
+
+\echo '== full scan: h3_grid_distance over every merchant (before) =='
+EXPLAIN (ANALYZE, BUFFERS, COSTS OFF)
+SELECT m.id,
+    h3_grid_distance(h3_latlng_to_cell(Point(:lat, :lng), 10), m.h3_index) AS h3_distance
+FROM merchants m
+WHERE haversine_meters(:lat, :lng, m.lat, m.lng) <= m.delivery_radius_meters
+    AND EXISTS (SELECT 1 FROM items i WHERE i.merchant_id = m.id)
+ORDER BY h3_distance ASC, m.created_at DESC, m.id ASC
+LIMIT 5;
+
+-- rings = utils.H3SearchRings(radius): 8 for 3000 m, 45 for 20000 m
+\echo '== ring lookup: first pass, default radius 3000 m (after) =='
+EXPLAIN (ANALYZE, BUFFERS, COSTS OFF)
+SELECT m.id, haversine_meters(:lat, :lng, m.lat, m.lng) AS distance_meters
+FROM merchants m
+WHERE h3_cell_to_parent(m.h3_index, 8) IN (
+        SELECT h3_grid_disk(h3_latlng_to_cell(Point(:lat, :lng), 8), 8)
+    )
+    AND haversine_meters(:lat, :lng, m.lat, m.lng) <= LEAST(m.delivery_radius_meters, 3000)
+    AND EXISTS (SELECT 1 FROM items i WHERE i.merchant_id = m.id)
+ORDER BY distance_meters ASC, m.created_at DESC, m.id ASC
+LIMIT 5;
+
+-- the total is counted at the radius the page was found in
+\echo '== ring lookup: total count at the default radius 3000 m (after) =='
+EXPLAIN (ANALYZE, BUFFERS, COSTS OFF)
+SELECT COUNT(*)
+FROM merchants m
+WHERE h3_cell_to_parent(m.h3_index, 8) IN (
+        SELECT h3_grid_disk(h3_latlng_to_cell(Point(:lat, :lng), 8), 8)
+    )
+    AND haversine_meters(:lat, :lng, m.lat, m.lng) <= LEAST(m.delivery_radius_meters, 3000)
+    AND EXISTS (SELECT 1 FROM items i WHERE i.merchant_id = m.id);